@@ -11,12 +11,18 @@ import (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "start",
+	Use:   "fantombot",
 	Short: "Fantom bot",
 	Long:  "Fantom bot",
 	RunE:  rootMain,
 }
 
+var startCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the bot",
+	RunE:  rootMain,
+}
+
 func rootMain(cmd *cobra.Command, args []string) error {
 	coreIns, err := core.New()
 	if err != nil {
@@ -63,4 +69,6 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	rootCmd.AddCommand(startCmd)
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/quangkeu95/fantom-bot/pkg/core"
+	"github.com/quangkeu95/fantom-bot/pkg/fetcher"
+	"github.com/spf13/cobra"
+)
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Transaction tools",
+}
+
+var txDecodeCmd = &cobra.Command{
+	Use:   "decode <hash>",
+	Short: "Decode the call data, the status and the events of a transaction with the SFC and ERC20 ABIs",
+	Args:  cobra.ExactArgs(1),
+	RunE:  txDecodeMain,
+}
+
+func txDecodeMain(cmd *cobra.Command, args []string) error {
+	nodeClient, err := fetcher.NewNodeClient()
+	if err != nil {
+		return err
+	}
+	txDecoder, err := core.NewTxDecoder(nodeClient)
+	if err != nil {
+		return err
+	}
+
+	tx, err := txDecoder.Decode(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(tx)
+	return nil
+}
+
+func init() {
+	txCmd.AddCommand(txDecodeCmd)
+	rootCmd.AddCommand(txCmd)
+}
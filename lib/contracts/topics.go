@@ -7,3 +7,21 @@ import (
 var (
 	TransferTopics = ethereumCommon.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
 )
+
+// SFC event topics
+var (
+	ChangedValidatorStatusTopics         = ethereumCommon.HexToHash("0xcd35267e7654194727477d6c78b541a553483cff7f92a055d17868d3da6e953e")
+	ClaimedRewardsTopics                 = ethereumCommon.HexToHash("0xc1d8eb6e444b89fb8ff0991c19311c070df704ccb009e210d1462d5b2410bf45")
+	CreatedValidatorTopics               = ethereumCommon.HexToHash("0x49bca1ed2666922f9f1690c26a569e1299c2a715fe57647d77e81adfabbf25bf")
+	DeactivatedValidatorTopics           = ethereumCommon.HexToHash("0xac4801c32a6067ff757446524ee4e7a373797278ac3c883eac5c693b4ad72e47")
+	DelegatedTopics                      = ethereumCommon.HexToHash("0x9a8f44850296624dadfd9c246d17e47171d35727a181bd090aa14bbbe00238bb")
+	LockedUpStakeTopics                  = ethereumCommon.HexToHash("0x138940e95abffcd789b497bf6188bba3afa5fbd22fb5c42c2f6018d1bf0f4e78")
+	OwnershipTransferredTopics           = ethereumCommon.HexToHash("0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0")
+	RestakedRewardsTopics                = ethereumCommon.HexToHash("0x4119153d17a36f9597d40e3ab4148d03261a439dddbec4e91799ab7159608e26")
+	UndelegatedTopics                    = ethereumCommon.HexToHash("0xd3bb4e423fbea695d16b982f9f682dc5f35152e5411646a8a5a79a6b02ba8d57")
+	UnlockedStakeTopics                  = ethereumCommon.HexToHash("0xef6c0c14fe9aa51af36acd791464dec3badbde668b63189b47bfa4e25be9b2b9")
+	UpdatedBaseRewardPerSecTopics        = ethereumCommon.HexToHash("0x8cd9dae1bbea2bc8a5e80ffce2c224727a25925130a03ae100619a8861ae2396")
+	UpdatedOfflinePenaltyThresholdTopics = ethereumCommon.HexToHash("0x702756a07c05d0bbfd06fc17b67951a5f4deb7bb6b088407e68a58969daf2a34")
+	UpdatedSlashingRefundRatioTopics     = ethereumCommon.HexToHash("0x047575f43f09a7a093d94ec483064acfc61b7e25c0de28017da442abf99cb917")
+	WithdrawnTopics                      = ethereumCommon.HexToHash("0x75e161b3e824b114fc1a33274bd7091918dd4e639cede50b78b15a4eea956a21")
+)
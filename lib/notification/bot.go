@@ -1,6 +1,15 @@
 package notification
 
+// Priority is the importance of a message, high priority messages are highlighted and always ring.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+)
+
 type SocialBot interface {
 	SendMessage(msg string) error
+	SendMessageWithPriority(msg string, priority Priority) error
 	GetChatID() int64
 }
@@ -1,17 +1,28 @@
 package notification
 
 import (
+	"fmt"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"go.uber.org/zap"
 )
 
 const (
-	EmojiWhale     = "\U0001F40B"
-	EmojiCheckMark = "\U00002705"
-	EmojiCrossMark = "\U0000274C"
-	EmojiStar      = "\U00002B50"
-	EmojiLock      = "\U0001F512"
-	EmojiUnlock    = "\U0001F513"
+	EmojiWhale          = "\U0001F40B"
+	EmojiCheckMark      = "\U00002705"
+	EmojiCrossMark      = "\U0000274C"
+	EmojiStar           = "\U00002B50"
+	EmojiLock           = "\U0001F512"
+	EmojiUnlock         = "\U0001F513"
+	EmojiWarning        = "\U000026A0"
+	EmojiInformation    = "\U00002139"
+	EmojiMoneyWithWings = "\U0001F4B8"
+	EmojiRepeat         = "\U0001F501"
+	EmojiSiren          = "\U0001F6A8"
+	EmojiBarChart       = "\U0001F4CA"
+	EmojiAlarmClock     = "\U000023F0"
+	EmojiEyes           = "\U0001F440"
+	EmojiHourglass      = "\U000023F3"
 )
 
 type TelegramBot struct {
@@ -36,6 +47,13 @@ func NewTelegramBot(token string, chatId int64) (*TelegramBot, error) {
 }
 
 func (b *TelegramBot) SendMessage(msg string) error {
+	return b.SendMessageWithPriority(msg, PriorityNormal)
+}
+
+func (b *TelegramBot) SendMessageWithPriority(msg string, priority Priority) error {
+	if priority == PriorityHigh {
+		msg = fmt.Sprintf("%v <b>HIGH PRIORITY</b>\n%s", EmojiSiren, msg)
+	}
 	sendMsg := tgbotapi.NewMessage(b.chatId, msg)
 	sendMsg.ParseMode = tgbotapi.ModeHTML
 	sendMsg.DisableWebPagePreview = true
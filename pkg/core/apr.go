@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	APRWindowEpochsFlag = "apr.window_epochs"
+	APRDropRatioFlag    = "apr.drop_ratio"
+
+	DefaultAPRWindowEpochs = uint64(100)
+	DefaultAPRDropRatio    = 0.3
+
+	SecondsPerYear = uint64(365 * 24 * 60 * 60)
+)
+
+// AnnualizeReward converts a reward per token earned during a window into an APR, the SFC accumulates
+// the reward per token of the delegators once the validator commission is taken.
+func AnnualizeReward(rewardPerToken float64, windowSeconds uint64) float64 {
+	if windowSeconds == 0 {
+		return 0
+	}
+	return rewardPerToken * float64(SecondsPerYear) / float64(windowSeconds)
+}
+
+// LockupRewardMultiplier returns the part of the full reward received by a delegation locked for lockupDuration seconds.
+// Unlocked delegations receive the unlocked reward ratio, the rest of the reward grows linearly with the lockup duration.
+func LockupRewardMultiplier(params pkg.RewardParams, lockupDuration uint64) float64 {
+	if params.MaxLockupDuration == 0 {
+		return params.UnlockedRewardRatio
+	}
+	if lockupDuration > params.MaxLockupDuration {
+		lockupDuration = params.MaxLockupDuration
+	}
+	return params.UnlockedRewardRatio + (1-params.UnlockedRewardRatio)*float64(lockupDuration)/float64(params.MaxLockupDuration)
+}
+
+// MedianAPR returns the median of the full APRs.
+func MedianAPR(aprs map[uint64]pkg.ValidatorAPR) float64 {
+	if len(aprs) == 0 {
+		return 0
+	}
+	var values = make([]float64, 0, len(aprs))
+	for _, apr := range aprs {
+		values = append(values, apr.FullAPR)
+	}
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// APRDropAlert is sent when the APR of a validator falls below the median APR of the network.
+type APRDropAlert struct {
+	APR    pkg.ValidatorAPR
+	Median float64
+}
+
+// APREngine computes the trailing APR of every validator from the rewards accumulated during the last epochs.
+type APREngine struct {
+	l            *zap.SugaredLogger
+	sfcClient    *SFCClient
+	notify       func(ctx context.Context, alert APRDropAlert)
+	windowEpochs uint64
+	dropRatio    float64
+
+	aprs    map[uint64]pkg.ValidatorAPR
+	params  pkg.RewardParams
+	dropped map[uint64]bool
+	mu      sync.RWMutex
+}
+
+func NewAPREngine(sfcClient *SFCClient, notify func(ctx context.Context, alert APRDropAlert)) *APREngine {
+	windowEpochs := DefaultAPRWindowEpochs
+	if viper.IsSet(APRWindowEpochsFlag) {
+		windowEpochs = viper.GetUint64(APRWindowEpochsFlag)
+	}
+	dropRatio := DefaultAPRDropRatio
+	if viper.IsSet(APRDropRatioFlag) {
+		dropRatio = viper.GetFloat64(APRDropRatioFlag)
+	}
+	return &APREngine{
+		l:            zap.S(),
+		sfcClient:    sfcClient,
+		notify:       notify,
+		windowEpochs: windowEpochs,
+		dropRatio:    dropRatio,
+		aprs:         make(map[uint64]pkg.ValidatorAPR),
+		dropped:      make(map[uint64]bool),
+		mu:           sync.RWMutex{},
+	}
+}
+
+// Update computes the APRs until the sealed epoch and alerts on the validators far below the median.
+func (e *APREngine) Update(ctx context.Context, toEpoch uint64) error {
+	fromEpoch := uint64(1)
+	if toEpoch > e.windowEpochs {
+		fromEpoch = toEpoch - e.windowEpochs
+	}
+	if fromEpoch >= toEpoch {
+		return fmt.Errorf("not enough epochs to compute the APR")
+	}
+
+	params, err := e.sfcClient.GetRewardParams(ctx)
+	if err != nil {
+		return err
+	}
+	fromTime, err := e.sfcClient.GetEpochEndTime(ctx, fromEpoch)
+	if err != nil {
+		return err
+	}
+	toTime, err := e.sfcClient.GetEpochEndTime(ctx, toEpoch)
+	if err != nil {
+		return err
+	}
+	if toTime <= fromTime {
+		return fmt.Errorf("invalid epoch times %d and %d", fromTime, toTime)
+	}
+	fromValidatorIDs, err := e.sfcClient.GetEpochValidatorIDs(ctx, fromEpoch)
+	if err != nil {
+		return err
+	}
+	toValidatorIDs, err := e.sfcClient.GetEpochValidatorIDs(ctx, toEpoch)
+	if err != nil {
+		return err
+	}
+
+	// only the validators active during the whole window are compared
+	var inWindow = make(map[uint64]bool)
+	for _, id := range fromValidatorIDs {
+		inWindow[id] = true
+	}
+	var aprs = make(map[uint64]pkg.ValidatorAPR)
+	for _, id := range toValidatorIDs {
+		if !inWindow[id] {
+			continue
+		}
+		fromReward, err := e.sfcClient.GetEpochAccumulatedRewardPerToken(ctx, fromEpoch, id)
+		if err != nil {
+			return err
+		}
+		toReward, err := e.sfcClient.GetEpochAccumulatedRewardPerToken(ctx, toEpoch, id)
+		if err != nil {
+			return err
+		}
+		aprs[id] = pkg.ValidatorAPR{
+			ValidatorID: id,
+			FromEpoch:   fromEpoch,
+			ToEpoch:     toEpoch,
+			FullAPR:     AnnualizeReward(toReward-fromReward, toTime-fromTime),
+		}
+	}
+
+	median := MedianAPR(aprs)
+	var alerts = make([]APRDropAlert, 0)
+	e.mu.Lock()
+	e.aprs = aprs
+	e.params = params
+	for id, apr := range aprs {
+		dropped := apr.FullAPR < median*(1-e.dropRatio)
+		if dropped && !e.dropped[id] {
+			alerts = append(alerts, APRDropAlert{APR: apr, Median: median})
+		}
+		e.dropped[id] = dropped
+	}
+	e.mu.Unlock()
+
+	e.l.Debugw("update validators apr", "from_epoch", fromEpoch, "to_epoch", toEpoch, "validators", len(aprs), "median", median)
+	for _, alert := range alerts {
+		e.notify(ctx, alert)
+	}
+	return nil
+}
+
+// HandleEpoch updates the APRs when an epoch is sealed.
+func (e *APREngine) HandleEpoch(ctx context.Context, snapshot pkg.EpochSnapshot) {
+	if err := e.Update(ctx, snapshot.Epoch); err != nil {
+		e.l.Warnw("update validators apr error", "error", err, "epoch", snapshot.Epoch)
+	}
+}
+
+func (e *APREngine) GetValidatorAPR(validatorID uint64) (pkg.ValidatorAPR, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	apr, ok := e.aprs[validatorID]
+	return apr, ok
+}
+
+func (e *APREngine) GetListValidatorAPR() map[uint64]pkg.ValidatorAPR {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var result = make(map[uint64]pkg.ValidatorAPR)
+	for key, value := range e.aprs {
+		result[key] = value
+	}
+	return result
+}
+
+// GetLockupAPR returns the APR of a delegation to the validator locked for lockupDuration, 0 means unlocked.
+func (e *APREngine) GetLockupAPR(validatorID uint64, lockupDuration time.Duration) (float64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	apr, ok := e.aprs[validatorID]
+	if !ok {
+		return 0, fmt.Errorf("apr of validator %d is unknown", validatorID)
+	}
+	seconds := uint64(lockupDuration / time.Second)
+	if seconds != 0 && seconds < e.params.MinLockupDuration {
+		return 0, fmt.Errorf("lockup duration is lower than the minimum %s", pkg.FormatDuration(time.Duration(e.params.MinLockupDuration)*time.Second))
+	}
+	return apr.FullAPR * LockupRewardMultiplier(e.params, seconds), nil
+}
+
+func (e *APREngine) GetRewardParams() pkg.RewardParams {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.params
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type APRTestSuite struct {
+	suite.Suite
+}
+
+func TestAPRTestSuite(t *testing.T) {
+	suite.Run(t, new(APRTestSuite))
+}
+
+func (ts *APRTestSuite) TestAnnualizeReward() {
+	assert := ts.Assert()
+
+	assert.Equal(float64(0), AnnualizeReward(0.1, 0))
+	assert.InDelta(0.1, AnnualizeReward(0.05, SecondsPerYear/2), 1e-9)
+}
+
+func (ts *APRTestSuite) TestLockupRewardMultiplier() {
+	assert := ts.Assert()
+
+	params := pkg.RewardParams{UnlockedRewardRatio: 0.3, MaxLockupDuration: 365 * 24 * 60 * 60}
+	assert.InDelta(0.3, LockupRewardMultiplier(params, 0), 1e-9)
+	assert.InDelta(0.65, LockupRewardMultiplier(params, params.MaxLockupDuration/2), 1e-9)
+	assert.InDelta(1, LockupRewardMultiplier(params, params.MaxLockupDuration), 1e-9)
+	assert.InDelta(1, LockupRewardMultiplier(params, params.MaxLockupDuration*2), 1e-9)
+}
+
+func (ts *APRTestSuite) TestMedianAPR() {
+	assert := ts.Assert()
+
+	assert.Equal(float64(0), MedianAPR(nil))
+	assert.Equal(0.2, MedianAPR(map[uint64]pkg.ValidatorAPR{
+		1: {FullAPR: 0.1},
+		2: {FullAPR: 0.2},
+		3: {FullAPR: 0.3},
+	}))
+	assert.InDelta(0.15, MedianAPR(map[uint64]pkg.ValidatorAPR{
+		1: {FullAPR: 0.1},
+		2: {FullAPR: 0.2},
+	}), 1e-9)
+}
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	CapacityWarningRatioFlag    = "capacity.warning_ratio"
+	DefaultCapacityWarningRatio = 0.9
+)
+
+type CapacityLevel int
+
+const (
+	CapacityOpen CapacityLevel = iota
+	CapacityNearlyFull
+	CapacityFull
+)
+
+// ValidatorCapacity is the room left for delegations to a validator, amounts are in FTM.
+// The received stake of a validator, its self stake included, is limited to MaxDelegatedRatio times its self stake.
+type ValidatorCapacity struct {
+	ValidatorID   uint64
+	SelfStake     float64
+	ReceivedStake float64
+	Capacity      float64
+	// BlockNumber is the block whose state the capacity was read in.
+	BlockNumber uint64
+}
+
+func (c ValidatorCapacity) Room() float64 {
+	if c.ReceivedStake >= c.Capacity {
+		return 0
+	}
+	return c.Capacity - c.ReceivedStake
+}
+
+// Usage returns the used part of the capacity, 1 means the validator is full.
+func (c ValidatorCapacity) Usage() float64 {
+	if c.Capacity == 0 {
+		return 1
+	}
+	return c.ReceivedStake / c.Capacity
+}
+
+// CapacityAlert is sent when the capacity level of a validator changes.
+type CapacityAlert struct {
+	Capacity      ValidatorCapacity
+	Level         CapacityLevel
+	PreviousLevel CapacityLevel
+}
+
+// CapacityTracker follows the delegation capacity of the validators after each delegation change,
+// the capacities are read by Run outside of the event handlers.
+type CapacityTracker struct {
+	l            *zap.SugaredLogger
+	sfcClient    *SFCClient
+	notify       func(ctx context.Context, alert CapacityAlert)
+	warningRatio float64
+
+	// maxDelegatedRatio is a governance parameter of the SFC contract, it is read again after each sealed epoch
+	maxDelegatedRatio float64
+	capacities        map[uint64]ValidatorCapacity
+	// pending are the validators to refresh with the block of their last delegation change
+	pending   map[uint64]uint64
+	pendingCh chan struct{}
+	mu        sync.RWMutex
+}
+
+func NewCapacityTracker(sfcClient *SFCClient, notify func(ctx context.Context, alert CapacityAlert)) *CapacityTracker {
+	warningRatio := DefaultCapacityWarningRatio
+	if viper.IsSet(CapacityWarningRatioFlag) {
+		warningRatio = viper.GetFloat64(CapacityWarningRatioFlag)
+	}
+	return &CapacityTracker{
+		l:            zap.S(),
+		sfcClient:    sfcClient,
+		notify:       notify,
+		warningRatio: warningRatio,
+		capacities:   make(map[uint64]ValidatorCapacity),
+		pending:      make(map[uint64]uint64),
+		pendingCh:    make(chan struct{}, 1),
+		mu:           sync.RWMutex{},
+	}
+}
+
+func (t *CapacityTracker) level(capacity ValidatorCapacity) CapacityLevel {
+	switch usage := capacity.Usage(); {
+	case usage >= 1:
+		return CapacityFull
+	case usage >= t.warningRatio:
+		return CapacityNearlyFull
+	default:
+		return CapacityOpen
+	}
+}
+
+// Update schedules a refresh of the capacity of the validator in the state of blockNumber,
+// the changes of a validator made before its refresh are read at once.
+func (t *CapacityTracker) Update(validatorID uint64, blockNumber uint64) {
+	t.mu.Lock()
+	if blockNumber > t.pending[validatorID] {
+		t.pending[validatorID] = blockNumber
+	}
+	t.mu.Unlock()
+
+	select {
+	case t.pendingCh <- struct{}{}:
+	default:
+	}
+}
+
+// Run refreshes the scheduled capacities until ctx is done.
+func (t *CapacityTracker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.pendingCh:
+			t.refreshPending(ctx)
+		}
+	}
+}
+
+func (t *CapacityTracker) refreshPending(ctx context.Context) {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[uint64]uint64)
+	t.mu.Unlock()
+
+	for validatorID, blockNumber := range pending {
+		t.refresh(ctx, validatorID, blockNumber)
+	}
+}
+
+// GetCapacityAt reads the capacity of the validator in the state of blockNumber.
+func (t *CapacityTracker) GetCapacityAt(ctx context.Context, validatorID uint64, blockNumber uint64) (ValidatorCapacity, error) {
+	maxDelegatedRatio, err := t.getMaxDelegatedRatio(ctx)
+	if err != nil {
+		return ValidatorCapacity{}, err
+	}
+	validator, err := t.sfcClient.GetValidatorAt(ctx, validatorID, &blockNumber)
+	if err != nil {
+		return ValidatorCapacity{}, err
+	}
+	return ValidatorCapacity{
+		ValidatorID:   validatorID,
+		SelfStake:     validator.SelfStake,
+		ReceivedStake: validator.TotalStake,
+		Capacity:      validator.SelfStake * maxDelegatedRatio,
+		BlockNumber:   blockNumber,
+	}, nil
+}
+
+// refresh reads the capacity of the validator and alerts when its level changed,
+// the first capacity known of a validator is never alerted.
+func (t *CapacityTracker) refresh(ctx context.Context, validatorID uint64, blockNumber uint64) {
+	capacity, err := t.GetCapacityAt(ctx, validatorID, blockNumber)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	previous, known := t.capacities[validatorID]
+	if known && previous.BlockNumber > blockNumber {
+		t.mu.Unlock()
+		return
+	}
+	t.capacities[validatorID] = capacity
+	t.mu.Unlock()
+
+	level := t.level(capacity)
+	if !known {
+		return
+	}
+	if previousLevel := t.level(previous); level != previousLevel {
+		t.l.Infow("validator capacity level changed", "validator_id", validatorID, "level", level, "usage", capacity.Usage())
+		t.notify(ctx, CapacityAlert{
+			Capacity:      capacity,
+			Level:         level,
+			PreviousLevel: previousLevel,
+		})
+	}
+}
+
+// HandleEpoch forgets the max delegated ratio so a change voted by the governance is read after the sealed epoch.
+func (t *CapacityTracker) HandleEpoch(ctx context.Context, snapshot pkg.EpochSnapshot) {
+	t.mu.Lock()
+	t.maxDelegatedRatio = 0
+	t.mu.Unlock()
+}
+
+// getMaxDelegatedRatio returns the max delegated ratio of the SFC contract, it is cached until the next sealed epoch.
+func (t *CapacityTracker) getMaxDelegatedRatio(ctx context.Context) (float64, error) {
+	t.mu.RLock()
+	maxDelegatedRatio := t.maxDelegatedRatio
+	t.mu.RUnlock()
+	if maxDelegatedRatio > 0 {
+		return maxDelegatedRatio, nil
+	}
+
+	maxDelegatedRatio, err := t.sfcClient.GetMaxDelegatedRatio(ctx)
+	if err != nil {
+		return 0, err
+	}
+	t.mu.Lock()
+	t.maxDelegatedRatio = maxDelegatedRatio
+	t.mu.Unlock()
+	return maxDelegatedRatio, nil
+}
+
+func (t *CapacityTracker) GetCapacity(validatorID uint64) (ValidatorCapacity, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	capacity, ok := t.capacities[validatorID]
+	return capacity, ok
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type CapacityTrackerTestSuite struct {
+	suite.Suite
+	tracker *CapacityTracker
+}
+
+func TestCapacityTrackerTestSuite(t *testing.T) {
+	suite.Run(t, new(CapacityTrackerTestSuite))
+}
+
+func (ts *CapacityTrackerTestSuite) SetupTest() {
+	ts.tracker = &CapacityTracker{
+		warningRatio: DefaultCapacityWarningRatio,
+	}
+}
+
+func (ts *CapacityTrackerTestSuite) TestRoomAndUsage() {
+	assert := ts.Assert()
+
+	capacity := ValidatorCapacity{SelfStake: 100, ReceivedStake: 1200, Capacity: 1600}
+	assert.Equal(float64(400), capacity.Room())
+	assert.Equal(0.75, capacity.Usage())
+
+	capacity.ReceivedStake = 1700
+	assert.Equal(float64(0), capacity.Room())
+
+	assert.Equal(float64(1), ValidatorCapacity{}.Usage())
+}
+
+func (ts *CapacityTrackerTestSuite) TestLevel() {
+	assert := ts.Assert()
+
+	assert.Equal(CapacityOpen, ts.tracker.level(ValidatorCapacity{ReceivedStake: 800, Capacity: 1000}))
+	assert.Equal(CapacityNearlyFull, ts.tracker.level(ValidatorCapacity{ReceivedStake: 900, Capacity: 1000}))
+	assert.Equal(CapacityFull, ts.tracker.level(ValidatorCapacity{ReceivedStake: 1000, Capacity: 1000}))
+}
+
+func (ts *CapacityTrackerTestSuite) TestRefreshAtEventBlock() {
+	assert := ts.Assert()
+
+	backend := &fakeContractBackend{outputs: make(map[string][]byte)}
+	sfcClient, err := newFakeSFCClient(backend)
+	assert.NoError(err)
+	var alerts []CapacityAlert
+	ts.tracker = NewCapacityTracker(sfcClient, func(ctx context.Context, alert CapacityAlert) {
+		alerts = append(alerts, alert)
+	})
+
+	// a self stake of 100 FTM with a max delegated ratio of 16 and 1200 FTM received
+	backend.setBigOutput("maxDelegatedRatio()", ftm(16))
+	backend.setBigOutput("getSelfStake(uint256)", ftm(100))
+	ts.setReceivedStake(backend, 1200)
+
+	// the changes of a validator made before its refresh are read once, at the last block
+	ts.tracker.Update(12, 100)
+	ts.tracker.Update(12, 102)
+	ts.tracker.Update(12, 101)
+	ts.tracker.refreshPending(context.Background())
+	assert.Equal([]*big.Int{nil, big.NewInt(102), big.NewInt(102)}, backend.blocks)
+
+	capacity, ok := ts.tracker.GetCapacity(12)
+	assert.True(ok)
+	assert.Equal(uint64(102), capacity.BlockNumber)
+	assert.Equal(float64(400), capacity.Room())
+	assert.Equal(0, len(alerts))
+
+	// the max delegated ratio is read once per epoch
+	ts.setReceivedStake(backend, 1500)
+	backend.blocks = nil
+	ts.tracker.Update(12, 103)
+	ts.tracker.refreshPending(context.Background())
+	assert.Equal([]*big.Int{big.NewInt(103), big.NewInt(103)}, backend.blocks)
+	assert.Equal(1, len(alerts))
+	assert.Equal(CapacityNearlyFull, alerts[0].Level)
+	assert.Equal(CapacityOpen, alerts[0].PreviousLevel)
+
+	// a max delegated ratio changed by the governance is read after the sealed epoch
+	backend.setBigOutput("maxDelegatedRatio()", ftm(20))
+	ts.tracker.HandleEpoch(context.Background(), pkg.EpochSnapshot{Epoch: 10})
+	backend.blocks = nil
+	capacity, err = ts.tracker.GetCapacityAt(context.Background(), 12, 104)
+	assert.NoError(err)
+	assert.Equal([]*big.Int{nil, big.NewInt(104), big.NewInt(104)}, backend.blocks)
+	assert.Equal(float64(500), capacity.Room())
+}
+
+func (ts *CapacityTrackerTestSuite) setReceivedStake(backend *fakeContractBackend, receivedStake int64) {
+	// status, deactivated time, deactivated epoch, received stake, created epoch, created time and auth
+	backend.setBigOutput("getValidator(uint256)", big.NewInt(0), big.NewInt(0), big.NewInt(0), ftm(receivedStake),
+		big.NewInt(1), big.NewInt(1650000000), big.NewInt(0xbb))
+}
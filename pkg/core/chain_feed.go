@@ -0,0 +1,417 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	ChainModeFlag       = "fantom_chain.mode"
+	PollIntervalFlag    = "fantom_chain.poll_interval"
+	WsMaxFailuresFlag   = "fantom_chain.ws_max_failures"
+	WsRetryIntervalFlag = "fantom_chain.ws_retry_interval"
+
+	// ChainModeWs subscribes over the ws endpoint only
+	ChainModeWs = "ws"
+	// ChainModePoll polls the rpc endpoint, no ws endpoint is needed
+	ChainModePoll = "poll"
+	// ChainModeAuto subscribes over the ws endpoint and polls the rpc endpoint while the ws endpoint keeps failing
+	ChainModeAuto = "auto"
+
+	DefaultChainMode       = ChainModeAuto
+	DefaultPollInterval    = 3 * time.Second
+	DefaultWsMaxFailures   = 5
+	DefaultWsRetryInterval = 10 * time.Minute
+
+	// WsStableLifetime is the time without ws failure after which the ws subscriptions are considered working again
+	WsStableLifetime = 1 * time.Minute
+	// WsFailureEpisode is the window in which the ws failures count as one, a disconnect fails
+	// every subscription at once
+	WsFailureEpisode = 10 * time.Second
+	// PollMaxHeads is the number of most recent heads delivered at once after a polling gap
+	PollMaxHeads = 100
+)
+
+var ErrPollRetryWs = errors.New("polling stopped to retry the ws subscriptions")
+
+// GetChainMode returns the configured chain mode, auto when it is not set or invalid.
+func GetChainMode() string {
+	mode := viper.GetString(ChainModeFlag)
+	switch mode {
+	case ChainModeWs, ChainModePoll, ChainModeAuto:
+		return mode
+	case "":
+		return DefaultChainMode
+	default:
+		zap.S().Warnw("invalid chain mode, fallback default", "mode", mode, "default", DefaultChainMode)
+		return DefaultChainMode
+	}
+}
+
+// ChainFeed feeds the new heads and the contract logs to the watchers, either from the ws subscriptions
+// or by polling the rpc endpoint with the same channels, so that the event pipeline is unchanged.
+type ChainFeed struct {
+	l             *zap.SugaredLogger
+	sfcClient     *SFCClient
+	onSwitch      func(polling bool)
+	mode          string
+	hasWs         bool
+	pollInterval  time.Duration
+	maxFailures   int
+	retryInterval time.Duration
+
+	failures     int
+	episodeStart time.Time
+	lastFailure  time.Time
+	degraded     bool
+	pollUntil    time.Time
+
+	// the logs of every subscribed topic are polled by a single poller
+	logSubscribers []*logSubscriber
+	pollingLogs    bool
+	mu             sync.Mutex
+}
+
+// logSubscriber is a subscription to the logs of an event topic served by the log poller.
+type logSubscriber struct {
+	ctx     context.Context
+	address etherCommon.Address
+	topic   etherCommon.Hash
+	logCh   chan<- types.Log
+	errCh   chan<- error
+}
+
+func (s *logSubscriber) matches(log types.Log) bool {
+	return log.Address == s.address && len(log.Topics) > 0 && log.Topics[0] == s.topic
+}
+
+// NewChainFeed creates the feed, onSwitch is called when the auto mode falls back to polling
+// and when the ws subscriptions work again.
+func NewChainFeed(sfcClient *SFCClient, mode string, onSwitch func(polling bool)) *ChainFeed {
+	pollInterval := DefaultPollInterval
+	if viper.IsSet(PollIntervalFlag) {
+		pollInterval = viper.GetDuration(PollIntervalFlag)
+	}
+	maxFailures := DefaultWsMaxFailures
+	if viper.IsSet(WsMaxFailuresFlag) {
+		maxFailures = viper.GetInt(WsMaxFailuresFlag)
+	}
+	retryInterval := DefaultWsRetryInterval
+	if viper.IsSet(WsRetryIntervalFlag) {
+		retryInterval = viper.GetDuration(WsRetryIntervalFlag)
+	}
+
+	return &ChainFeed{
+		l:             zap.S(),
+		sfcClient:     sfcClient,
+		onSwitch:      onSwitch,
+		mode:          mode,
+		hasWs:         sfcClient.HasWs(),
+		pollInterval:  pollInterval,
+		maxFailures:   maxFailures,
+		retryInterval: retryInterval,
+		mu:            sync.Mutex{},
+	}
+}
+
+// Polling returns whether the feed polls the rpc endpoint at the time now.
+func (f *ChainFeed) Polling(now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pollingLocked(now)
+}
+
+func (f *ChainFeed) pollingLocked(now time.Time) bool {
+	switch {
+	case f.mode == ChainModePoll || !f.hasWs:
+		return true
+	case f.mode == ChainModeAuto:
+		return now.Before(f.pollUntil)
+	default:
+		return false
+	}
+}
+
+// recordWsFailure counts the failure episodes of the ws subscriptions, in auto mode the feed polls
+// for the retry interval once the failures reach the maximum.
+// The count is kept after the switch so that a ws endpoint still failing at the retry falls back at once.
+func (f *ChainFeed) recordWsFailure(now time.Time) {
+	f.mu.Lock()
+	f.lastFailure = now
+	if now.Sub(f.episodeStart) >= WsFailureEpisode {
+		f.episodeStart = now
+		f.failures++
+	}
+	var notify bool
+	if f.mode == ChainModeAuto && f.failures >= f.maxFailures && !f.pollingLocked(now) {
+		f.pollUntil = now.Add(f.retryInterval)
+		notify, f.degraded = !f.degraded, true
+		f.l.Warnw("ws endpoint keeps failing, poll the rpc endpoint", "failures", f.failures, "until", f.pollUntil)
+	}
+	f.mu.Unlock()
+
+	if notify {
+		f.onSwitch(true)
+	}
+}
+
+// recordWsStable resets the failures once no ws subscription has failed for the stable lifetime.
+func (f *ChainFeed) recordWsStable(now time.Time) {
+	f.mu.Lock()
+	if now.Sub(f.lastFailure) < WsStableLifetime {
+		f.mu.Unlock()
+		return
+	}
+	f.failures = 0
+	notify := f.degraded
+	f.degraded = false
+	f.mu.Unlock()
+
+	if notify {
+		f.l.Info("ws endpoint works again, stop polling")
+		f.onSwitch(false)
+	}
+}
+
+// SubscribeLogs delivers the logs of the event topic emitted by the contract, errors are sent to errCh
+// and end the subscription.
+func (f *ChainFeed) SubscribeLogs(ctx context.Context, address etherCommon.Address, topic etherCommon.Hash, logCh chan<- types.Log, errCh chan<- error) {
+	if f.Polling(time.Now()) {
+		f.pollLogs(ctx, address, topic, logCh, errCh)
+		return
+	}
+	f.runWs(ctx, func(wsErrCh chan<- error) {
+		f.sfcClient.SubscribeLogs(ctx, address, topic, logCh, wsErrCh)
+	}, errCh)
+}
+
+// SubscribeNewHead delivers the new heads, errors are sent to errCh and end the subscription.
+func (f *ChainFeed) SubscribeNewHead(ctx context.Context, headCh chan<- pkg.BlockHead, errCh chan<- error) {
+	if f.Polling(time.Now()) {
+		f.pollNewHead(ctx, headCh, errCh)
+		return
+	}
+	f.runWs(ctx, func(wsErrCh chan<- error) {
+		f.sfcClient.SubscribeNewHead(ctx, headCh, wsErrCh)
+	}, errCh)
+}
+
+func (f *ChainFeed) runWs(ctx context.Context, subscribe func(wsErrCh chan<- error), errCh chan<- error) {
+	wsErrCh := make(chan error, 1)
+	go subscribe(wsErrCh)
+
+	stable := time.NewTimer(WsStableLifetime)
+	defer stable.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stable.C:
+			f.recordWsStable(time.Now())
+		case err := <-wsErrCh:
+			f.recordWsFailure(time.Now())
+			f.sendError(ctx, errCh, err)
+			return
+		}
+	}
+}
+
+// pollLogs adds the subscription to the log poller, the first subscription runs the poller.
+func (f *ChainFeed) pollLogs(ctx context.Context, address etherCommon.Address, topic etherCommon.Hash, logCh chan<- types.Log, errCh chan<- error) {
+	f.mu.Lock()
+	f.logSubscribers = append(f.logSubscribers, &logSubscriber{
+		ctx:     ctx,
+		address: address,
+		topic:   topic,
+		logCh:   logCh,
+		errCh:   errCh,
+	})
+	start := !f.pollingLogs
+	f.pollingLogs = true
+	f.mu.Unlock()
+
+	if start {
+		f.runLogPoller(ctx)
+	}
+}
+
+// runLogPoller polls the logs of every subscribed topic with a single query per interval and delivers
+// them to the matching subscriptions, like a subscription it does not deliver the logs of the past blocks.
+// It stops when no subscription is left, an error ends every subscription.
+func (f *ChainFeed) runLogPoller(ctx context.Context) {
+	latestBlock, err := f.sfcClient.GetLatestBlock(ctx)
+	if err != nil {
+		f.stopLogPoller(err)
+		return
+	}
+	fromBlock := latestBlock + 1
+
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			f.stopLogPoller(ctx.Err())
+			return
+		case <-ticker.C:
+		}
+		if !f.Polling(time.Now()) {
+			f.stopLogPoller(ErrPollRetryWs)
+			return
+		}
+		subscribers := f.getLogSubscribers()
+		if len(subscribers) == 0 {
+			return
+		}
+
+		latestBlock, err := f.sfcClient.GetLatestBlock(ctx)
+		if err != nil {
+			f.stopLogPoller(err)
+			return
+		}
+		if latestBlock < fromBlock {
+			continue
+		}
+		toBlock := latestBlock
+		if toBlock-fromBlock >= BlockRange {
+			toBlock = fromBlock + BlockRange - 1
+		}
+		addresses, topics := logQuery(subscribers)
+		logs, err := f.sfcClient.GetLogsByTopics(ctx, addresses, topics, fromBlock, toBlock)
+		if err != nil {
+			f.stopLogPoller(err)
+			return
+		}
+		dispatchLogs(subscribers, logs)
+		fromBlock = toBlock + 1
+	}
+}
+
+// getLogSubscribers drops the ended subscriptions and returns the others, the poller is marked
+// as stopped when none is left so that the next subscription runs a new one.
+func (f *ChainFeed) getLogSubscribers() []*logSubscriber {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var subscribers = make([]*logSubscriber, 0, len(f.logSubscribers))
+	for _, subscriber := range f.logSubscribers {
+		if subscriber.ctx.Err() == nil {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	f.logSubscribers = subscribers
+	if len(subscribers) == 0 {
+		f.pollingLogs = false
+	}
+	return subscribers
+}
+
+// stopLogPoller ends every subscription of the poller with the error.
+func (f *ChainFeed) stopLogPoller(err error) {
+	f.mu.Lock()
+	subscribers := f.logSubscribers
+	f.logSubscribers, f.pollingLogs = nil, false
+	f.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		f.sendError(subscriber.ctx, subscriber.errCh, err)
+	}
+}
+
+// logQuery returns the distinct contracts and event topics of the subscriptions.
+func logQuery(subscribers []*logSubscriber) ([]etherCommon.Address, []etherCommon.Hash) {
+	var (
+		addresses   = make([]etherCommon.Address, 0)
+		topics      = make([]etherCommon.Hash, 0)
+		seenAddress = make(map[etherCommon.Address]bool)
+		seenTopic   = make(map[etherCommon.Hash]bool)
+	)
+	for _, subscriber := range subscribers {
+		if !seenAddress[subscriber.address] {
+			seenAddress[subscriber.address] = true
+			addresses = append(addresses, subscriber.address)
+		}
+		if !seenTopic[subscriber.topic] {
+			seenTopic[subscriber.topic] = true
+			topics = append(topics, subscriber.topic)
+		}
+	}
+	return addresses, topics
+}
+
+// dispatchLogs delivers every log to the subscriptions of its contract and event topic.
+func dispatchLogs(subscribers []*logSubscriber, logs []types.Log) {
+	for _, log := range logs {
+		for _, subscriber := range subscribers {
+			if !subscriber.matches(log) {
+				continue
+			}
+			select {
+			case subscriber.logCh <- log:
+			case <-subscriber.ctx.Done():
+			}
+		}
+	}
+}
+
+// pollNewHead polls the latest head and delivers every head since the previous poll,
+// at most the PollMaxHeads most recent ones.
+func (f *ChainFeed) pollNewHead(ctx context.Context, headCh chan<- pkg.BlockHead, errCh chan<- error) {
+	var lastBlock uint64
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+	for {
+		if !f.Polling(time.Now()) {
+			f.sendError(ctx, errCh, ErrPollRetryWs)
+			return
+		}
+
+		latest, err := f.sfcClient.GetBlockHead(ctx, nil)
+		if err != nil {
+			f.sendError(ctx, errCh, err)
+			return
+		}
+		fromBlock := lastBlock + 1
+		switch {
+		case lastBlock == 0:
+			fromBlock = latest.Number
+		case latest.Number >= lastBlock+PollMaxHeads:
+			fromBlock = latest.Number - PollMaxHeads + 1
+		}
+		for number := fromBlock; number <= latest.Number; number++ {
+			head := latest
+			if number < latest.Number {
+				if head, err = f.sfcClient.GetBlockHead(ctx, &number); err != nil {
+					f.sendError(ctx, errCh, err)
+					return
+				}
+			}
+			select {
+			case headCh <- head:
+			case <-ctx.Done():
+				return
+			}
+			lastBlock = number
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *ChainFeed) sendError(ctx context.Context, errCh chan<- error, err error) {
+	select {
+	case errCh <- err:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type ChainFeedTestSuite struct {
+	suite.Suite
+	switches []bool
+	start    time.Time
+}
+
+func TestChainFeedTestSuite(t *testing.T) {
+	suite.Run(t, new(ChainFeedTestSuite))
+}
+
+func (ts *ChainFeedTestSuite) SetupTest() {
+	ts.switches = make([]bool, 0)
+	ts.start = time.Unix(1600000000, 0)
+}
+
+func (ts *ChainFeedTestSuite) newFeed(mode string, hasWs bool) *ChainFeed {
+	return &ChainFeed{
+		l:             zap.S(),
+		onSwitch:      func(polling bool) { ts.switches = append(ts.switches, polling) },
+		mode:          mode,
+		hasWs:         hasWs,
+		maxFailures:   3,
+		retryInterval: 10 * time.Minute,
+		mu:            sync.Mutex{},
+	}
+}
+
+func (ts *ChainFeedTestSuite) TestAutoMode() {
+	assert := ts.Assert()
+	feed := ts.newFeed(ChainModeAuto, true)
+
+	assert.False(feed.Polling(ts.start))
+	feed.recordWsFailure(ts.start)
+	feed.recordWsFailure(ts.start.Add(WsFailureEpisode))
+	assert.False(feed.Polling(ts.start))
+	now := ts.start.Add(2 * WsFailureEpisode)
+	feed.recordWsFailure(now)
+	assert.True(feed.Polling(now))
+	assert.Equal([]bool{true}, ts.switches)
+
+	// the ws subscriptions are retried after the interval and a new failure falls back at once
+	retry := now.Add(10 * time.Minute)
+	assert.False(feed.Polling(retry))
+	feed.recordWsFailure(retry)
+	assert.True(feed.Polling(retry))
+	assert.Equal([]bool{true}, ts.switches)
+
+	retry = retry.Add(10 * time.Minute)
+	feed.recordWsStable(retry)
+	assert.False(feed.Polling(retry))
+	assert.Equal([]bool{true, false}, ts.switches)
+
+	feed.recordWsFailure(retry)
+	assert.False(feed.Polling(retry))
+	feed.recordWsStable(retry.Add(WsStableLifetime))
+	assert.Equal([]bool{true, false}, ts.switches)
+}
+
+func (ts *ChainFeedTestSuite) TestFailureEpisode() {
+	assert := ts.Assert()
+	feed := ts.newFeed(ChainModeAuto, true)
+
+	// a disconnect fails every subscription at once, it is a single failure
+	for i := 0; i < 17; i++ {
+		feed.recordWsFailure(ts.start.Add(time.Duration(i) * time.Millisecond))
+	}
+	assert.False(feed.Polling(ts.start))
+	assert.Equal(1, feed.failures)
+
+	// a subscription which survived the disconnect does not reset the failures
+	now := ts.start.Add(WsFailureEpisode)
+	feed.recordWsFailure(now)
+	feed.recordWsStable(now.Add(WsStableLifetime / 2))
+	assert.Equal(2, feed.failures)
+
+	feed.recordWsStable(now.Add(WsStableLifetime))
+	assert.Equal(0, feed.failures)
+	assert.Equal(0, len(ts.switches))
+}
+
+func (ts *ChainFeedTestSuite) TestFixedMode() {
+	assert := ts.Assert()
+
+	feed := ts.newFeed(ChainModeWs, true)
+	for i := 0; i < 5; i++ {
+		feed.recordWsFailure(ts.start.Add(time.Duration(i) * WsFailureEpisode))
+	}
+	assert.False(feed.Polling(ts.start))
+
+	assert.True(ts.newFeed(ChainModePoll, true).Polling(ts.start))
+	assert.True(ts.newFeed(ChainModeAuto, false).Polling(ts.start))
+	assert.Equal(0, len(ts.switches))
+}
+
+func (ts *ChainFeedTestSuite) TestLogQuery() {
+	assert := ts.Assert()
+
+	sfc := etherCommon.HexToAddress("0xfc00face00000000000000000000000000000000")
+	token := etherCommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	transfer := etherCommon.HexToHash("0x01")
+	subscribers := []*logSubscriber{
+		{address: sfc, topic: contracts.DelegatedTopics},
+		{address: sfc, topic: contracts.UndelegatedTopics},
+		{address: token, topic: transfer},
+		{address: sfc, topic: contracts.DelegatedTopics},
+	}
+
+	addresses, topics := logQuery(subscribers)
+	assert.Equal([]etherCommon.Address{sfc, token}, addresses)
+	assert.Equal([]etherCommon.Hash{contracts.DelegatedTopics, contracts.UndelegatedTopics, transfer}, topics)
+}
+
+func (ts *ChainFeedTestSuite) TestDispatchLogs() {
+	assert := ts.Assert()
+
+	sfc := etherCommon.HexToAddress("0xfc00face00000000000000000000000000000000")
+	token := etherCommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	newSubscriber := func(address etherCommon.Address, topic etherCommon.Hash) (*logSubscriber, chan types.Log) {
+		logCh := make(chan types.Log, 10)
+		return &logSubscriber{ctx: context.Background(), address: address, topic: topic, logCh: logCh}, logCh
+	}
+	delegated, delegatedCh := newSubscriber(sfc, contracts.DelegatedTopics)
+	undelegated, undelegatedCh := newSubscriber(sfc, contracts.UndelegatedTopics)
+
+	logs := []types.Log{
+		{Address: sfc, Topics: []etherCommon.Hash{contracts.DelegatedTopics}, BlockNumber: 10},
+		{Address: sfc, Topics: []etherCommon.Hash{contracts.UndelegatedTopics}, BlockNumber: 10},
+		// the query matches any topic of any address, a log of another contract is not delivered
+		{Address: token, Topics: []etherCommon.Hash{contracts.DelegatedTopics}, BlockNumber: 11},
+		{Address: sfc, Topics: []etherCommon.Hash{contracts.DelegatedTopics}, BlockNumber: 12},
+	}
+	dispatchLogs([]*logSubscriber{delegated, undelegated}, logs)
+
+	assert.Equal(2, len(delegatedCh))
+	assert.Equal(uint64(10), (<-delegatedCh).BlockNumber)
+	assert.Equal(uint64(12), (<-delegatedCh).BlockNumber)
+	assert.Equal(1, len(undelegatedCh))
+	assert.Equal(logs[1], <-undelegatedCh)
+}
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg/fetcher"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	ChainMonitorIntervalFlag     = "chain_monitor.check_interval"
+	ChainMonitorStallTimeoutFlag = "chain_monitor.stall_timeout"
+	ChainMonitorMaxLagFlag       = "chain_monitor.max_lag_blocks"
+
+	DefaultChainMonitorInterval     = 30 * time.Second
+	DefaultChainMonitorStallTimeout = 2 * time.Minute
+	DefaultChainMonitorMaxLag       = 10
+)
+
+type EndpointState int
+
+const (
+	EndpointHealthy EndpointState = iota
+	EndpointLagging
+	EndpointUnreachable
+)
+
+func (s EndpointState) String() string {
+	switch s {
+	case EndpointLagging:
+		return "lagging"
+	case EndpointUnreachable:
+		return "unreachable"
+	default:
+		return "healthy"
+	}
+}
+
+type LivenessAlertKind int
+
+const (
+	ChainStalled LivenessAlertKind = iota
+	ChainResumed
+	HeadSubscriptionSilent
+	HeadSubscriptionResumed
+	EndpointStateChanged
+)
+
+// LivenessAlert is sent when the chain stalls or resumes, when the head subscription goes silent
+// or resumes, and when the state of an endpoint changes.
+type LivenessAlert struct {
+	Kind      LivenessAlertKind
+	Endpoint  string
+	State     EndpointState
+	Height    uint64
+	MaxHeight uint64
+	// Since is the time since the last progress of the chain or the last head
+	Since time.Duration
+}
+
+// HeightSource reads the latest block number from an endpoint.
+type HeightSource struct {
+	Name      string
+	GetHeight func(ctx context.Context) (uint64, error)
+}
+
+type heightResult struct {
+	height uint64
+	err    error
+}
+
+// ChainMonitor checks the liveness of the chain: the time since the last head received by the
+// subscription, the progress of the block height and the lag between the endpoints.
+type ChainMonitor struct {
+	l            *zap.SugaredLogger
+	sources      []HeightSource
+	notify       func(ctx context.Context, alert LivenessAlert)
+	interval     time.Duration
+	stallTimeout time.Duration
+	maxLag       uint64
+	// checkTimeout bounds the height query of each endpoint
+	checkTimeout time.Duration
+
+	maxHeight    uint64
+	lastProgress time.Time
+	lastHead     time.Time
+	stalled      bool
+	silent       bool
+	states       map[string]EndpointState
+	mu           sync.Mutex
+}
+
+func NewChainMonitor(sources []HeightSource, notify func(ctx context.Context, alert LivenessAlert)) *ChainMonitor {
+	interval := DefaultChainMonitorInterval
+	if viper.IsSet(ChainMonitorIntervalFlag) {
+		interval = viper.GetDuration(ChainMonitorIntervalFlag)
+	}
+	stallTimeout := DefaultChainMonitorStallTimeout
+	if viper.IsSet(ChainMonitorStallTimeoutFlag) {
+		stallTimeout = viper.GetDuration(ChainMonitorStallTimeoutFlag)
+	}
+	maxLag := uint64(DefaultChainMonitorMaxLag)
+	if viper.IsSet(ChainMonitorMaxLagFlag) {
+		maxLag = viper.GetUint64(ChainMonitorMaxLagFlag)
+	}
+
+	return &ChainMonitor{
+		l:            zap.S(),
+		sources:      sources,
+		notify:       notify,
+		interval:     interval,
+		stallTimeout: stallTimeout,
+		maxLag:       maxLag,
+		checkTimeout: fetcher.EndpointCheckTimeout,
+		states:       make(map[string]EndpointState),
+		mu:           sync.Mutex{},
+	}
+}
+
+// OnHead records a head received by the subscription.
+func (m *ChainMonitor) OnHead(number uint64, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastHead = now
+	if number > m.maxHeight {
+		m.maxHeight = number
+		m.lastProgress = now
+	}
+}
+
+// Run polls the height of every endpoint until ctx is done.
+func (m *ChainMonitor) Run(ctx context.Context) {
+	m.mu.Lock()
+	now := time.Now()
+	m.lastProgress, m.lastHead = now, now
+	m.mu.Unlock()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, alert := range m.evaluate(m.getHeights(ctx), time.Now()) {
+			m.notify(ctx, alert)
+		}
+	}
+}
+
+// getHeights queries the endpoints concurrently, an endpoint which does not answer in time is unreachable.
+func (m *ChainMonitor) getHeights(ctx context.Context) map[string]heightResult {
+	var (
+		results = make(map[string]heightResult)
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+	)
+	for _, source := range m.sources {
+		wg.Add(1)
+		go func(source HeightSource) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, m.checkTimeout)
+			defer cancel()
+			height, err := source.GetHeight(checkCtx)
+			if err != nil {
+				m.l.Warnw("get endpoint height error", "error", err, "endpoint", source.Name)
+			}
+			mu.Lock()
+			results[source.Name] = heightResult{height: height, err: err}
+			mu.Unlock()
+		}(source)
+	}
+	wg.Wait()
+	return results
+}
+
+func (m *ChainMonitor) evaluate(results map[string]heightResult, now time.Time) []LivenessAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var alerts = make([]LivenessAlert, 0)
+	for _, result := range results {
+		if result.err == nil && result.height > m.maxHeight {
+			m.maxHeight = result.height
+			m.lastProgress = now
+		}
+	}
+
+	sinceProgress := now.Sub(m.lastProgress)
+	switch {
+	case m.stalled && sinceProgress < m.stallTimeout:
+		m.stalled = false
+		alerts = append(alerts, LivenessAlert{Kind: ChainResumed, MaxHeight: m.maxHeight})
+	case !m.stalled && sinceProgress >= m.stallTimeout:
+		m.stalled = true
+		alerts = append(alerts, LivenessAlert{Kind: ChainStalled, MaxHeight: m.maxHeight, Since: sinceProgress})
+	}
+
+	// the subscription is only silent when the chain itself moves on
+	sinceHead := now.Sub(m.lastHead)
+	switch {
+	case m.silent && sinceHead < m.stallTimeout:
+		m.silent = false
+		alerts = append(alerts, LivenessAlert{Kind: HeadSubscriptionResumed, MaxHeight: m.maxHeight})
+	case !m.silent && !m.stalled && sinceHead >= m.stallTimeout:
+		m.silent = true
+		alerts = append(alerts, LivenessAlert{Kind: HeadSubscriptionSilent, MaxHeight: m.maxHeight, Since: sinceHead})
+	}
+
+	for _, source := range m.sources {
+		result, ok := results[source.Name]
+		if !ok {
+			continue
+		}
+		state := EndpointHealthy
+		switch {
+		case result.err != nil:
+			state = EndpointUnreachable
+		case result.height+m.maxLag < m.maxHeight:
+			state = EndpointLagging
+		}
+		if state == m.states[source.Name] {
+			continue
+		}
+		m.states[source.Name] = state
+		alerts = append(alerts, LivenessAlert{
+			Kind:      EndpointStateChanged,
+			Endpoint:  source.Name,
+			State:     state,
+			Height:    result.height,
+			MaxHeight: m.maxHeight,
+		})
+	}
+	return alerts
+}
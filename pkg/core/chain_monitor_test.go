@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type ChainMonitorTestSuite struct {
+	suite.Suite
+	monitor *ChainMonitor
+	start   time.Time
+}
+
+func TestChainMonitorTestSuite(t *testing.T) {
+	suite.Run(t, new(ChainMonitorTestSuite))
+}
+
+func (ts *ChainMonitorTestSuite) SetupTest() {
+	ts.start = time.Unix(1600000000, 0)
+	ts.monitor = &ChainMonitor{
+		l:            zap.S(),
+		sources:      []HeightSource{{Name: "rpc"}, {Name: "ws"}},
+		stallTimeout: 2 * time.Minute,
+		maxLag:       10,
+		lastProgress: ts.start,
+		lastHead:     ts.start,
+		states:       make(map[string]EndpointState),
+	}
+}
+
+func (ts *ChainMonitorTestSuite) TestStall() {
+	assert := ts.Assert()
+
+	results := map[string]heightResult{"rpc": {height: 100}, "ws": {height: 100}}
+	alerts := ts.monitor.evaluate(results, ts.start.Add(30*time.Second))
+	assert.Equal(0, len(alerts))
+
+	alerts = ts.monitor.evaluate(results, ts.start.Add(3*time.Minute))
+	assert.Equal(1, len(alerts))
+	assert.Equal(ChainStalled, alerts[0].Kind)
+	assert.Equal(uint64(100), alerts[0].MaxHeight)
+	assert.Equal(150*time.Second, alerts[0].Since)
+
+	// no repeated alert and no silent subscription while stalled
+	alerts = ts.monitor.evaluate(results, ts.start.Add(5*time.Minute))
+	assert.Equal(0, len(alerts))
+
+	ts.monitor.OnHead(101, ts.start.Add(6*time.Minute))
+	alerts = ts.monitor.evaluate(map[string]heightResult{"rpc": {height: 101}, "ws": {height: 101}}, ts.start.Add(6*time.Minute))
+	assert.Equal(1, len(alerts))
+	assert.Equal(ChainResumed, alerts[0].Kind)
+	assert.Equal(uint64(101), alerts[0].MaxHeight)
+}
+
+func (ts *ChainMonitorTestSuite) TestSilentSubscription() {
+	assert := ts.Assert()
+
+	ts.monitor.OnHead(100, ts.start)
+	alerts := ts.monitor.evaluate(map[string]heightResult{"rpc": {height: 105}, "ws": {height: 105}}, ts.start.Add(3*time.Minute))
+	assert.Equal(1, len(alerts))
+	assert.Equal(HeadSubscriptionSilent, alerts[0].Kind)
+	assert.Equal(uint64(105), alerts[0].MaxHeight)
+
+	ts.monitor.OnHead(106, ts.start.Add(4*time.Minute))
+	alerts = ts.monitor.evaluate(map[string]heightResult{"rpc": {height: 106}, "ws": {height: 106}}, ts.start.Add(4*time.Minute))
+	assert.Equal(1, len(alerts))
+	assert.Equal(HeadSubscriptionResumed, alerts[0].Kind)
+}
+
+func (ts *ChainMonitorTestSuite) TestEndpointState() {
+	assert := ts.Assert()
+
+	alerts := ts.monitor.evaluate(map[string]heightResult{"rpc": {height: 100}, "ws": {height: 85}}, ts.start)
+	assert.Equal(1, len(alerts))
+	assert.Equal(EndpointStateChanged, alerts[0].Kind)
+	assert.Equal("ws", alerts[0].Endpoint)
+	assert.Equal(EndpointLagging, alerts[0].State)
+	assert.Equal(uint64(85), alerts[0].Height)
+	assert.Equal(uint64(100), alerts[0].MaxHeight)
+
+	// a lag within the limit is healthy
+	alerts = ts.monitor.evaluate(map[string]heightResult{"rpc": {height: 100}, "ws": {height: 95}}, ts.start)
+	assert.Equal(1, len(alerts))
+	assert.Equal(EndpointHealthy, alerts[0].State)
+
+	alerts = ts.monitor.evaluate(map[string]heightResult{"rpc": {err: errors.New("timeout")}, "ws": {height: 100}}, ts.start)
+	assert.Equal(1, len(alerts))
+	assert.Equal("rpc", alerts[0].Endpoint)
+	assert.Equal(EndpointUnreachable, alerts[0].State)
+
+	alerts = ts.monitor.evaluate(map[string]heightResult{"rpc": {err: errors.New("timeout")}, "ws": {height: 100}}, ts.start)
+	assert.Equal(0, len(alerts))
+}
+
+func (ts *ChainMonitorTestSuite) TestGetHeightsTimeout() {
+	assert := ts.Assert()
+
+	ts.monitor.checkTimeout = 50 * time.Millisecond
+	ts.monitor.sources = []HeightSource{
+		{Name: "rpc", GetHeight: func(ctx context.Context) (uint64, error) {
+			return 100, nil
+		}},
+		{Name: "ws", GetHeight: func(ctx context.Context) (uint64, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}},
+	}
+
+	results := ts.monitor.getHeights(context.Background())
+	assert.Equal(heightResult{height: 100}, results["rpc"])
+	assert.Equal(context.DeadlineExceeded, results["ws"].err)
+}
@@ -0,0 +1,336 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"go.uber.org/zap"
+)
+
+const (
+	ConfirmationBlocksFlag    = "confirmation_blocks"
+	DefaultConfirmationBlocks = uint64(5)
+	// ConfirmationHistoryBlocks is the number of blocks kept to detect reorgs and retract confirmed events.
+	ConfirmationHistoryBlocks = uint64(256)
+)
+
+type BlockHeadReader interface {
+	GetBlockHead(ctx context.Context, number *uint64) (pkg.BlockHead, error)
+	GetBlockHeadByHash(ctx context.Context, hash string) (pkg.BlockHead, error)
+}
+
+// ConfirmationItem is an event waiting for enough confirmations.
+// OnConfirm is called once the event is confirmed, corrected is true when the same log of
+// the transaction was retracted before, it returns whether the event must be retracted.
+// OnRetract is called when a confirmed event to retract disappears from the canonical chain.
+type ConfirmationItem struct {
+	Event     pkg.Event
+	OnConfirm func(event pkg.Event, corrected bool) bool
+	OnRetract func(event pkg.Event)
+}
+
+func (item ConfirmationItem) key() string {
+	log := item.Event.GetEventLog()
+	return fmt.Sprintf("%T:%s:%d", item.Event, log.TxHash, log.LogIndex)
+}
+
+// ConfirmationManager buffers events by block hash and emits them after a number of confirmations.
+// Reorgs are detected by comparing the parent hash of every new head with the known canonical chain.
+type ConfirmationManager struct {
+	l      *zap.SugaredLogger
+	reader BlockHeadReader
+	depth  uint64
+
+	head        uint64
+	canonical   map[uint64]string
+	pending     map[string][]ConfirmationItem
+	retractable map[string][]ConfirmationItem
+	retracted   map[string]uint64
+
+	mu sync.Mutex
+}
+
+func NewConfirmationManager(reader BlockHeadReader, depth uint64) *ConfirmationManager {
+	return &ConfirmationManager{
+		l:           zap.S(),
+		reader:      reader,
+		depth:       depth,
+		canonical:   make(map[uint64]string),
+		pending:     make(map[string][]ConfirmationItem),
+		retractable: make(map[string][]ConfirmationItem),
+		retracted:   make(map[string]uint64),
+		mu:          sync.Mutex{},
+	}
+}
+
+// Add buffers the event until it is confirmed, a removed log retracts the matching event.
+func (m *ConfirmationManager) Add(ctx context.Context, item ConfirmationItem) {
+	log := item.Event.GetEventLog()
+
+	m.mu.Lock()
+	if log.Removed {
+		retracted := m.removeLocked(item)
+		m.mu.Unlock()
+		m.retract(retracted)
+		return
+	}
+
+	m.pending[log.BlockHash] = append(m.pending[log.BlockHash], item)
+	m.mu.Unlock()
+
+	m.confirm(m.confirmReady(ctx))
+}
+
+// ConfirmedBlock returns the last block whose events have all been confirmed or dropped,
+// the events of the blocks above it may still be waiting for confirmations.
+func (m *ConfirmationManager) ConfirmedBlock() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.head < m.depth {
+		return 0
+	}
+	confirmedBlock := m.head - m.depth
+	for _, items := range m.pending {
+		blockNumber := items[0].Event.GetEventLog().BlockNumber
+		if blockNumber == 0 {
+			return 0
+		}
+		if blockNumber <= confirmedBlock {
+			confirmedBlock = blockNumber - 1
+		}
+	}
+	return confirmedBlock
+}
+
+// AddHead moves the chain head forward, retracts the events of orphaned blocks
+// and emits the events which have enough confirmations.
+func (m *ConfirmationManager) AddHead(ctx context.Context, head pkg.BlockHead) {
+	m.mu.Lock()
+	orphaned := m.updateCanonicalLocked(ctx, head)
+
+	var retracted = make([]ConfirmationItem, 0)
+	for _, hash := range orphaned {
+		if items, ok := m.pending[hash]; ok {
+			m.l.Infow("drop pending events of orphaned block", "block_hash", hash, "events", len(items))
+			delete(m.pending, hash)
+		}
+		if items, ok := m.retractable[hash]; ok {
+			retracted = append(retracted, items...)
+			delete(m.retractable, hash)
+		}
+	}
+	for _, item := range retracted {
+		m.retracted[item.key()] = item.Event.GetEventLog().BlockNumber
+	}
+	m.pruneLocked()
+	m.mu.Unlock()
+
+	m.retract(retracted)
+	m.confirm(m.confirmReady(ctx))
+}
+
+// updateCanonicalLocked records the new head and returns the hashes of the blocks removed from the canonical chain.
+func (m *ConfirmationManager) updateCanonicalLocked(ctx context.Context, head pkg.BlockHead) []string {
+	var orphaned = make([]string, 0)
+
+	// a head lower than or equal to the current one replaces the blocks above it
+	for number := head.Number; number <= m.head; number++ {
+		if hash, ok := m.canonical[number]; ok && hash != head.Hash {
+			orphaned = append(orphaned, hash)
+			delete(m.canonical, number)
+		}
+	}
+
+	// walk back until the parent hash matches the known canonical chain
+	parentHash := head.ParentHash
+	for number := head.Number - 1; number > 0; number-- {
+		hash, ok := m.canonical[number]
+		if !ok || hash == parentHash {
+			break
+		}
+		m.l.Warnw("chain reorg detected", "block_number", number, "old_hash", hash, "new_hash", parentHash)
+		orphaned = append(orphaned, hash)
+		m.canonical[number] = parentHash
+
+		parent, err := m.reader.GetBlockHeadByHash(ctx, parentHash)
+		if err != nil {
+			m.l.Warnw("get block head by hash error", "error", err, "block_hash", parentHash)
+			break
+		}
+		parentHash = parent.ParentHash
+	}
+
+	m.canonical[head.Number] = head.Hash
+	m.head = head.Number
+	return orphaned
+}
+
+// confirmReady returns the pending events which have enough confirmations, the heads of their blocks
+// missed by the manager are asked to the node without holding the lock.
+func (m *ConfirmationManager) confirmReady(ctx context.Context) []ConfirmationItem {
+	m.mu.Lock()
+	missing := m.missingHeadsLocked()
+	m.mu.Unlock()
+
+	var hashes = make(map[uint64]string)
+	for _, blockNumber := range missing {
+		number := blockNumber
+		head, err := m.reader.GetBlockHead(ctx, &number)
+		if err != nil {
+			m.l.Warnw("get block head error", "error", err, "block_number", number)
+			continue
+		}
+		hashes[number] = head.Hash
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for number, hash := range hashes {
+		if _, ok := m.canonical[number]; !ok {
+			m.canonical[number] = hash
+		}
+	}
+	return m.confirmLocked()
+}
+
+// missingHeadsLocked returns the blocks of the confirmed pending events whose head was missed.
+func (m *ConfirmationManager) missingHeadsLocked() []uint64 {
+	var missing = make([]uint64, 0)
+	for _, items := range m.pending {
+		blockNumber := items[0].Event.GetEventLog().BlockNumber
+		if !m.isConfirmedLocked(blockNumber) {
+			continue
+		}
+		if _, ok := m.canonical[blockNumber]; !ok && blockNumber+ConfirmationHistoryBlocks > m.head {
+			missing = append(missing, blockNumber)
+		}
+	}
+	return missing
+}
+
+func (m *ConfirmationManager) isConfirmedLocked(blockNumber uint64) bool {
+	return m.head != 0 && blockNumber+m.depth <= m.head
+}
+
+// confirmLocked returns the pending events which have enough confirmations, sorted by position in the chain.
+// The events of a block whose head is still unknown are kept pending.
+func (m *ConfirmationManager) confirmLocked() []ConfirmationItem {
+	var confirmed = make([]ConfirmationItem, 0)
+	for hash, items := range m.pending {
+		blockNumber := items[0].Event.GetEventLog().BlockNumber
+		if !m.isConfirmedLocked(blockNumber) {
+			continue
+		}
+
+		canonicalHash, ok := m.canonical[blockNumber]
+		if !ok && blockNumber+ConfirmationHistoryBlocks > m.head {
+			continue
+		}
+
+		delete(m.pending, hash)
+		if ok && canonicalHash != hash {
+			m.l.Infow("drop events of non canonical block", "block_number", blockNumber, "block_hash", hash, "events", len(items))
+			continue
+		}
+		confirmed = append(confirmed, items...)
+	}
+
+	sort.SliceStable(confirmed, func(i, j int) bool {
+		left, right := confirmed[i].Event.GetEventLog(), confirmed[j].Event.GetEventLog()
+		if left.BlockNumber != right.BlockNumber {
+			return left.BlockNumber < right.BlockNumber
+		}
+		return left.LogIndex < right.LogIndex
+	})
+	return confirmed
+}
+
+// removeLocked drops a removed log from the pending events, it returns the confirmed events to retract.
+func (m *ConfirmationManager) removeLocked(item ConfirmationItem) []ConfirmationItem {
+	var (
+		log       = item.Event.GetEventLog()
+		retracted = make([]ConfirmationItem, 0)
+	)
+	match := func(other ConfirmationItem) bool {
+		return other.key() == item.key()
+	}
+
+	var remaining = make([]ConfirmationItem, 0)
+	for _, pendingItem := range m.pending[log.BlockHash] {
+		if !match(pendingItem) {
+			remaining = append(remaining, pendingItem)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(m.pending, log.BlockHash)
+	} else {
+		m.pending[log.BlockHash] = remaining
+	}
+
+	remaining = make([]ConfirmationItem, 0)
+	for _, confirmedItem := range m.retractable[log.BlockHash] {
+		if match(confirmedItem) {
+			retracted = append(retracted, confirmedItem)
+			m.retracted[confirmedItem.key()] = log.BlockNumber
+			continue
+		}
+		remaining = append(remaining, confirmedItem)
+	}
+	if len(remaining) == 0 {
+		delete(m.retractable, log.BlockHash)
+	} else {
+		m.retractable[log.BlockHash] = remaining
+	}
+	return retracted
+}
+
+// pruneLocked forgets the blocks which are too old to be reorganized.
+func (m *ConfirmationManager) pruneLocked() {
+	if m.head < ConfirmationHistoryBlocks {
+		return
+	}
+	lowestBlock := m.head - ConfirmationHistoryBlocks
+	for number := range m.canonical {
+		if number < lowestBlock {
+			delete(m.canonical, number)
+		}
+	}
+	for hash, items := range m.retractable {
+		if items[0].Event.GetEventLog().BlockNumber < lowestBlock {
+			delete(m.retractable, hash)
+		}
+	}
+	for key, blockNumber := range m.retracted {
+		if blockNumber < lowestBlock {
+			delete(m.retracted, key)
+		}
+	}
+}
+
+func (m *ConfirmationManager) confirm(items []ConfirmationItem) {
+	for _, item := range items {
+		m.mu.Lock()
+		key := item.key()
+		_, corrected := m.retracted[key]
+		delete(m.retracted, key)
+		m.mu.Unlock()
+
+		if !item.OnConfirm(item.Event, corrected) || item.OnRetract == nil {
+			continue
+		}
+
+		log := item.Event.GetEventLog()
+		m.mu.Lock()
+		m.retractable[log.BlockHash] = append(m.retractable[log.BlockHash], item)
+		m.mu.Unlock()
+	}
+}
+
+func (m *ConfirmationManager) retract(items []ConfirmationItem) {
+	for _, item := range items {
+		item.OnRetract(item.Event)
+	}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeBlockHeadReader struct {
+	heads map[string]pkg.BlockHead
+	// onGetBlockHead is called on every head asked by number. Optional.
+	onGetBlockHead func(number uint64)
+}
+
+func (r *fakeBlockHeadReader) GetBlockHead(ctx context.Context, number *uint64) (pkg.BlockHead, error) {
+	if r.onGetBlockHead != nil {
+		r.onGetBlockHead(*number)
+	}
+	for _, head := range r.heads {
+		if head.Number == *number {
+			return head, nil
+		}
+	}
+	return pkg.BlockHead{}, fmt.Errorf("block %d not found", *number)
+}
+
+func (r *fakeBlockHeadReader) GetBlockHeadByHash(ctx context.Context, hash string) (pkg.BlockHead, error) {
+	if head, ok := r.heads[hash]; ok {
+		return head, nil
+	}
+	return pkg.BlockHead{}, fmt.Errorf("block %s not found", hash)
+}
+
+type ConfirmationManagerTestSuite struct {
+	suite.Suite
+	reader  *fakeBlockHeadReader
+	manager *ConfirmationManager
+
+	confirmed []pkg.Event
+	corrected []pkg.Event
+	retracted []pkg.Event
+}
+
+func TestConfirmationManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfirmationManagerTestSuite))
+}
+
+func (ts *ConfirmationManagerTestSuite) SetupTest() {
+	ts.reader = &fakeBlockHeadReader{heads: make(map[string]pkg.BlockHead)}
+	ts.manager = NewConfirmationManager(ts.reader, 2)
+	ts.confirmed = nil
+	ts.corrected = nil
+	ts.retracted = nil
+}
+
+func (ts *ConfirmationManagerTestSuite) addHead(number uint64, hash, parentHash string) {
+	head := pkg.BlockHead{Number: number, Hash: hash, ParentHash: parentHash}
+	ts.reader.heads[hash] = head
+	ts.manager.AddHead(context.Background(), head)
+}
+
+func (ts *ConfirmationManagerTestSuite) addEvent(event pkg.TransferLog) {
+	ts.manager.Add(context.Background(), ConfirmationItem{
+		Event: event,
+		OnConfirm: func(event pkg.Event, corrected bool) bool {
+			ts.confirmed = append(ts.confirmed, event)
+			if corrected {
+				ts.corrected = append(ts.corrected, event)
+			}
+			return true
+		},
+		OnRetract: func(event pkg.Event) {
+			ts.retracted = append(ts.retracted, event)
+		},
+	})
+}
+
+func (ts *ConfirmationManagerTestSuite) TestConfirmAfterDepth() {
+	assert := ts.Assert()
+
+	ts.addHead(10, "0xa10", "0xa9")
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1"}})
+	assert.Equal(0, len(ts.confirmed))
+
+	ts.addHead(11, "0xa11", "0xa10")
+	assert.Equal(0, len(ts.confirmed))
+
+	ts.addHead(12, "0xa12", "0xa11")
+	assert.Equal(1, len(ts.confirmed))
+	assert.Equal("0x1", ts.confirmed[0].GetEventLog().TxHash)
+}
+
+func (ts *ConfirmationManagerTestSuite) TestReorgRetractsAnnouncedEvent() {
+	assert := ts.Assert()
+
+	ts.addHead(10, "0xa10", "0xa9")
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1"}})
+	ts.addHead(11, "0xa11", "0xa10")
+	ts.addHead(12, "0xa12", "0xa11")
+	assert.Equal(1, len(ts.confirmed))
+
+	// block 10 is replaced by 0xb10
+	ts.reader.heads["0xb10"] = pkg.BlockHead{Number: 10, Hash: "0xb10", ParentHash: "0xa9"}
+	ts.reader.heads["0xb11"] = pkg.BlockHead{Number: 11, Hash: "0xb11", ParentHash: "0xb10"}
+	ts.reader.heads["0xb12"] = pkg.BlockHead{Number: 12, Hash: "0xb12", ParentHash: "0xb11"}
+	ts.addHead(13, "0xb13", "0xb12")
+	assert.Equal(1, len(ts.retracted))
+
+	// the transaction is included again in the new chain
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 11, BlockHash: "0xb11", TxHash: "0x1"}})
+	assert.Equal(2, len(ts.confirmed))
+	assert.Equal(1, len(ts.corrected))
+}
+
+func (ts *ConfirmationManagerTestSuite) TestDropPendingEventOfOrphanedBlock() {
+	assert := ts.Assert()
+
+	ts.addHead(10, "0xa10", "0xa9")
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1"}})
+	ts.addHead(10, "0xb10", "0xa9")
+	ts.addHead(11, "0xb11", "0xb10")
+	ts.addHead(12, "0xb12", "0xb11")
+	assert.Equal(0, len(ts.confirmed))
+	assert.Equal(0, len(ts.retracted))
+}
+
+func (ts *ConfirmationManagerTestSuite) TestRemovedLog() {
+	assert := ts.Assert()
+
+	ts.addHead(10, "0xa10", "0xa9")
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1"}})
+	ts.addHead(12, "0xa12", "0xa11")
+	assert.Equal(1, len(ts.confirmed))
+
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1", Removed: true}})
+	assert.Equal(1, len(ts.retracted))
+}
+
+func (ts *ConfirmationManagerTestSuite) TestConfirmedBlock() {
+	assert := ts.Assert()
+
+	assert.Equal(uint64(0), ts.manager.ConfirmedBlock())
+	ts.addHead(10, "0xa10", "0xa9")
+	assert.Equal(uint64(8), ts.manager.ConfirmedBlock())
+
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1"}})
+	ts.addHead(11, "0xa11", "0xa10")
+	assert.Equal(uint64(9), ts.manager.ConfirmedBlock())
+
+	ts.addHead(12, "0xa12", "0xa11")
+	assert.Equal(1, len(ts.confirmed))
+	assert.Equal(uint64(10), ts.manager.ConfirmedBlock())
+}
+
+func (ts *ConfirmationManagerTestSuite) TestRetractionKeyedByLogIndex() {
+	assert := ts.Assert()
+
+	ts.addHead(10, "0xa10", "0xa9")
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1", LogIndex: 0}})
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1", LogIndex: 1}})
+	ts.addHead(11, "0xa11", "0xa10")
+	ts.addHead(13, "0xa13", "0xa12")
+	assert.Equal(2, len(ts.confirmed))
+
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1", LogIndex: 0, Removed: true}})
+	assert.Equal(1, len(ts.retracted))
+	assert.Equal(uint(0), ts.retracted[0].GetEventLog().LogIndex)
+
+	// another event of the same transaction is not a correction of the retracted one
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 11, BlockHash: "0xa11", TxHash: "0x1", LogIndex: 5}})
+	assert.Equal(3, len(ts.confirmed))
+	assert.Equal(0, len(ts.corrected))
+
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 11, BlockHash: "0xa11", TxHash: "0x1", LogIndex: 0}})
+	assert.Equal(4, len(ts.confirmed))
+	assert.Equal(1, len(ts.corrected))
+}
+
+func (ts *ConfirmationManagerTestSuite) TestMissedHeadAskedWithoutLock() {
+	assert := ts.Assert()
+
+	var asked []uint64
+	ts.reader.onGetBlockHead = func(number uint64) {
+		asked = append(asked, number)
+		// the manager stays usable while the head is asked
+		ts.manager.ConfirmedBlock()
+	}
+	ts.reader.heads["0xa10"] = pkg.BlockHead{Number: 10, Hash: "0xa10", ParentHash: "0xa9"}
+	ts.addHead(9, "0xa9", "0xa8")
+	ts.addHead(12, "0xa12", "0xa11")
+
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 10, BlockHash: "0xa10", TxHash: "0x1"}})
+	assert.Equal([]uint64{10}, asked)
+	assert.Equal(1, len(ts.confirmed))
+
+	// an event of a block replaced by the node is dropped
+	ts.reader.heads["0xb11"] = pkg.BlockHead{Number: 11, Hash: "0xb11", ParentHash: "0xa10"}
+	delete(ts.reader.heads, "0xa11")
+	ts.addEvent(pkg.TransferLog{EventLog: pkg.EventLog{BlockNumber: 11, BlockHash: "0xa11", TxHash: "0x2"}})
+	assert.Equal(1, len(ts.confirmed))
+}
@@ -11,9 +11,11 @@ import (
 	"syscall"
 	"time"
 
+	etherCommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/quangkeu95/fantom-bot/config"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
 	"github.com/quangkeu95/fantom-bot/lib/notification"
 	"github.com/quangkeu95/fantom-bot/pkg"
 	"github.com/quangkeu95/fantom-bot/pkg/fetcher"
@@ -27,13 +29,40 @@ const (
 	MinStakingAmountFlag  = "min_staking_amount"
 	MinClaimAmountFlag    = "min_claim_amount"
 	MinTransferAmountFlag = "min_transfer_amount"
-	ShiftBlock            = uint64(5)
+	MinRestakeAmountFlag  = "min_restake_amount"
 )
 
 const (
 	SocialBotStorageKey = "social_bots"
 )
 
+const (
+	// UndelegationLookupBlocks bounds the blocks searched back from a withdrawal for an undelegation
+	// missing from the keeper, about three weeks of blocks.
+	UndelegationLookupBlocks  = 2000000
+	UndelegationLookupTimeout = 10 * time.Second
+)
+
+const (
+	CreatedValidatorStream       = "created_validator"
+	DelegateStream               = "delegate"
+	UndelegateStream             = "undelegate"
+	WithdrawnStream              = "withdrawn"
+	DeactivatedValidatorStream   = "deactivated_validator"
+	ChangedValidatorStatusStream = "changed_validator_status"
+	LockedUpStakeStream          = "locked_up_stake"
+	UnlockedStakeStream          = "unlocked_stake"
+	ClaimRewardStream            = "claim_reward"
+	RestakeRewardStream          = "restake_reward"
+	FTMTransferStream            = "ftm_transfer"
+	SFCCallStream                = "sfc_call"
+
+	UpdatedBaseRewardPerSecStream        = "updated_base_reward_per_sec"
+	UpdatedOfflinePenaltyThresholdStream = "updated_offline_penalty_threshold"
+	UpdatedSlashingRefundRatioStream     = "updated_slashing_refund_ratio"
+	OwnershipTransferredStream           = "ownership_transferred"
+)
+
 type Core struct {
 	l         *zap.SugaredLogger
 	sfcClient *SFCClient
@@ -44,12 +73,33 @@ type Core struct {
 	undelegateInfoKeeper *keeper.UndelegateInfoKeeper
 	rewardInfoKeeper     *keeper.RewardInfoKeeper
 
-	socialBots      []notification.SocialBot
-	keyValueStorage storage.KeyValueStorage
+	socialBots          []notification.SocialBot
+	keyValueStorage     storage.KeyValueStorage
+	checkpoint          *storage.Checkpoint
+	confirmations       *ConfirmationManager
+	eventSource         *EventSource
+	validatorSyncer     *ValidatorSyncer
+	epochStore          *storage.EpochStore
+	epochWatcher        *EpochWatcher
+	downtimeMonitor     *DowntimeMonitor
+	aprEngine           *APREngine
+	slashingMonitor     *SlashingMonitor
+	capacityTracker     *CapacityTracker
+	lockupScheduler     *LockupScheduler
+	withdrawalScheduler *WithdrawalScheduler
+	watchlist           *Watchlist
+	rewardsTracker      *PendingRewardsTracker
+	sfcCallMonitor      *SFCCallMonitor
+	statsCollector      *StatsCollector
+	mempoolWatcher      *MempoolWatcher
+	chainMonitor        *ChainMonitor
+	chainFeed           *ChainFeed
+	watchlistBot        notification.SocialBot
 
 	minStakingAmount  float64
 	minClaimAmount    float64
 	minTransferAmount float64
+	minRestakeAmount  float64
 
 	contactBook   map[string]string
 	validatorBook map[uint64]string
@@ -64,10 +114,20 @@ func New() (*Core, error) {
 		l.Errorw("error dial node client", "error", err)
 		return nil, err
 	}
-	wsClient, err := fetcher.NewWsClient()
-	if err != nil {
-		l.Errorw("error dial ws client", "error", err)
-		return nil, err
+	var (
+		chainMode = GetChainMode()
+		wsClient  *fetcher.WsClient
+	)
+	if chainMode != ChainModePoll {
+		wsClient, err = fetcher.NewWsClient()
+		if err != nil && chainMode == ChainModeWs {
+			l.Errorw("error dial ws client", "error", err)
+			return nil, err
+		}
+		if err != nil {
+			l.Warnw("error dial ws client, fallback polling", "error", err)
+			wsClient = nil
+		}
 	}
 
 	sfcClient, err := NewSFCClient(nodeClient, wsClient)
@@ -87,10 +147,14 @@ func New() (*Core, error) {
 		return nil, err
 	}
 	minTransferAmount := viper.GetFloat64(MinTransferAmountFlag)
-	if err := validation.Validate(minClaimAmount, validation.Required); err != nil {
+	if err := validation.Validate(minTransferAmount, validation.Required); err != nil {
 		l.Errorw("min transfer amount config error", "error", err)
 		return nil, err
 	}
+	minRestakeAmount := minClaimAmount
+	if viper.IsSet(MinRestakeAmountFlag) {
+		minRestakeAmount = viper.GetFloat64(MinRestakeAmountFlag)
+	}
 
 	fetchers := make([]fetcher.Fetcher, 0)
 	graphqlClient, err := fetcher.NewGraphqlClient()
@@ -100,6 +164,11 @@ func New() (*Core, error) {
 	}
 	fetchers = append(fetchers, graphqlClient, nodeClient)
 
+	confirmationBlocks := DefaultConfirmationBlocks
+	if viper.IsSet(ConfirmationBlocksFlag) {
+		confirmationBlocks = viper.GetUint64(ConfirmationBlocksFlag)
+	}
+
 	badgerDB, err := storage.NewBadgerDB()
 	if err != nil {
 		l.Errorw("error initialize badger db", "error", err)
@@ -115,18 +184,69 @@ func New() (*Core, error) {
 		undelegateInfoKeeper: keeper.NewUndelegateInfoKeeper(),
 		rewardInfoKeeper:     keeper.NewRewardInfoKeeper(),
 		keyValueStorage:      badgerDB,
+		checkpoint:           storage.NewCheckpoint(badgerDB),
+		confirmations:        NewConfirmationManager(nodeClient, confirmationBlocks),
 		minStakingAmount:     minStakingAmount,
 		minClaimAmount:       minClaimAmount,
 		minTransferAmount:    minTransferAmount,
+		minRestakeAmount:     minRestakeAmount,
 		contactBook:          config.GetContact(),
 		validatorBook:        config.GetValidatorContact(),
 		mu:                   sync.RWMutex{},
 	}
 
+	c.watchlist = NewWatchlist(badgerDB)
+	c.withdrawalScheduler = NewWithdrawalScheduler(sfcClient, badgerDB, c.watchlist, c.sendWithdrawalReminderMessage)
+	c.lockupScheduler = NewLockupScheduler(sfcClient, badgerDB, c.watchlist, c.validatorKeeper, c.sendLockupReminderMessage)
+	c.capacityTracker = NewCapacityTracker(sfcClient, c.sendCapacityMessage)
+	c.slashingMonitor = NewSlashingMonitor(sfcClient, badgerDB, c.validatorKeeper, c.delegateInfoKeeper, c.sendSlashingMessage)
+	c.chainFeed = NewChainFeed(sfcClient, chainMode, c.sendChainModeMessage)
+	c.eventSource = NewEventSource(sfcClient, c.chainFeed, c.checkpoint, c.confirmations, c.SendMessageWithPriority)
+	c.eventSource.SetWatchlist(c.watchlist, c.sendWatchlistMessage)
+	if err := c.registerEventTypes(); err != nil {
+		l.Errorw("error register event types", "error", err)
+		return nil, err
+	}
+	c.sfcCallMonitor = NewSFCCallMonitor(sfcClient, c.confirmSFCCall)
+	c.mempoolWatcher = NewMempoolWatcher(sfcClient, minTransferAmount, c.sendPendingTxMessage)
+	var heightSources = []HeightSource{{Name: "graphql", GetHeight: graphqlClient.GetLatestBlock}}
+	var endpoints = nodeClient.GetPool().Endpoints()
+	if wsClient != nil {
+		endpoints = append(endpoints, wsClient.GetPool().Endpoints()...)
+	}
+	for _, endpoint := range endpoints {
+		heightSources = append(heightSources, HeightSource{Name: endpoint.Name, GetHeight: endpoint.GetLatestBlock})
+	}
+	c.chainMonitor = NewChainMonitor(heightSources, c.sendLivenessMessage)
+	c.validatorSyncer = NewValidatorSyncer(c.validatorKeeper, c.fetchValidators, c.sendValidatorChangeMessage)
+
+	c.epochStore = storage.NewEpochStore(badgerDB)
+	c.epochWatcher = NewEpochWatcher(sfcClient, c.epochStore)
+	c.epochWatcher.OnSealed(func(ctx context.Context, snapshot pkg.EpochSnapshot) {
+		if err := c.validatorSyncer.Sync(ctx); err != nil {
+			c.l.Warnw("sync validators error", "error", err, "epoch", snapshot.Epoch)
+		}
+	})
+	if viper.GetBool(EpochSummaryFlag) {
+		c.epochWatcher.OnSealed(c.sendEpochSummaryMessage)
+	}
+	c.downtimeMonitor = NewDowntimeMonitor(sfcClient, badgerDB, c.sendDowntimeMessage)
+	c.epochWatcher.OnSealed(c.downtimeMonitor.HandleEpoch)
+	c.epochWatcher.OnSealed(c.capacityTracker.HandleEpoch)
+	c.aprEngine = NewAPREngine(sfcClient, c.sendAPRDropMessage)
+	c.epochWatcher.OnSealed(c.aprEngine.HandleEpoch)
+	c.rewardsTracker = NewPendingRewardsTracker(sfcClient, badgerDB, c.watchlist, c.validatorKeeper, c.sendPendingRewardsMessage)
+	c.epochWatcher.OnSealed(c.rewardsTracker.HandleEpoch)
+	c.statsCollector = NewStatsCollector(sfcClient, storage.NewNetworkStatsStore(badgerDB, NetworkStatsRetention), c.sendNetworkReportMessage)
+
 	if err := c.initSocialBots(); err != nil {
 		l.Errorw("error initialize social bot", "error", err)
 		return nil, err
 	}
+	if err := c.initWatchlistBot(); err != nil {
+		l.Errorw("error initialize watchlist bot", "error", err)
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -136,20 +256,24 @@ func (c *Core) Run() error {
 
 	c.SendMessage("fantom bot start")
 
-	c.l.Infow("fantom bot start", "min_staking_amount", c.minStakingAmount, "min_claim_amount", c.minClaimAmount, "min_transfer_amount", c.minTransferAmount)
+	c.l.Infow("fantom bot start", "min_staking_amount", c.minStakingAmount, "min_claim_amount", c.minClaimAmount, "min_transfer_amount", c.minTransferAmount, "min_restake_amount", c.minRestakeAmount, "max_catch_up_blocks", c.eventSource.maxCatchUpBlocks, "confirmation_blocks", c.confirmations.depth)
 
+	go c.sfcClient.Run(ctx)
 	if err := c.initFetchValidators(ctx); err != nil {
 		c.l.Warnw("fetch validators error", "error", err)
 		return err
 	}
 
-	go c.watchCreatedValidators(ctx)
-	go c.watchDelegateEvent(ctx)
-	go c.watchUndelegateEvent(ctx)
-	go c.watchLockedUpStakeEvent(ctx)
-	go c.watchUnlockedStakeEvent(ctx)
-	go c.watchClaimRewardEvent(ctx)
-	go c.watchFTMTransferEvent(ctx)
+	c.eventSource.Run(ctx)
+	go c.watchNewHead(ctx)
+	go c.validatorSyncer.Run(ctx)
+	go c.epochWatcher.Run(ctx)
+	go c.capacityTracker.Run(ctx)
+	go c.lockupScheduler.Run(ctx)
+	go c.withdrawalScheduler.Run(ctx)
+	go c.statsCollector.Run(ctx)
+	go c.mempoolWatcher.Run(ctx)
+	go c.chainMonitor.Run(ctx)
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh,
@@ -158,6 +282,8 @@ func (c *Core) Run() error {
 		syscall.SIGQUIT)
 
 	<-sigCh
+	cancel()
+	c.eventSource.Wait()
 	c.l.Info("fantombot exit")
 	return nil
 }
@@ -183,18 +309,42 @@ func (c *Core) initSocialBots() error {
 	return nil
 }
 
+// initWatchlistBot creates the bot of the watchlist chat when it is configured,
+// the watched events are sent to the main chats otherwise.
+func (c *Core) initWatchlistBot() error {
+	if !viper.IsSet(WatchlistChatIDFlag) {
+		return nil
+	}
+	token := viper.GetString("telegram.token")
+	chatId := viper.GetInt64(WatchlistChatIDFlag)
+	telegramBot, err := notification.NewTelegramBot(token, chatId)
+	if err != nil {
+		return err
+	}
+	c.watchlistBot = telegramBot
+	return nil
+}
+
 func (c *Core) initFetchValidators(ctx context.Context) error {
+	validators, err := c.fetchValidators(ctx)
+	if err != nil {
+		return err
+	}
+	c.validatorKeeper.AddBatch(validators)
+	return nil
+}
+
+// fetchValidators returns the validators of the first fetcher which succeeds.
+func (c *Core) fetchValidators(ctx context.Context) ([]pkg.SFCValidator, error) {
 	for _, f := range c.fetchers {
 		validators, err := f.GetListValidators(ctx)
-		if err != nil {
+		if err != nil || len(validators) == 0 {
 			continue
 		}
 		c.l.Debugw("fetch validators", "length", len(validators), "last_validator_id", validators[len(validators)-1].ID)
-
-		c.validatorKeeper.AddBatch(validators)
-		return nil
+		return validators, nil
 	}
-	return fmt.Errorf("cannot fetch validators from any fetcher")
+	return nil, fmt.Errorf("cannot fetch validators from any fetcher")
 }
 
 // func (c *Core) updateSocialBotStorageInterval() {
@@ -205,45 +355,427 @@ func (c *Core) initFetchValidators(ctx context.Context) error {
 // 	}
 // }
 
-func (c *Core) watchCreatedValidators(ctx context.Context) {
-	var (
-		validatorCh = make(chan pkg.SFCValidator)
-		errCh       = make(chan error)
-	)
+// registerEventTypes declares the SFC events notified by the bot.
+func (c *Core) registerEventTypes() error {
+	sfc := c.sfcClient.sfcContract
+
+	c.eventSource.Register(EventType{
+		Name:  CreatedValidatorStream,
+		Topic: contracts.CreatedValidatorTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseCreatedValidator(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCValidator(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			c.validatorKeeper.Add(event.(pkg.SFCValidator))
+		},
+		Addresses: func(event pkg.Event) []string {
+			return []string{event.(pkg.SFCValidator).Address}
+		},
+		Render: c.renderCreatedValidatorMessage,
+		Retract: func(ctx context.Context, event pkg.Event) {
+			c.reloadValidator(ctx, event.(pkg.SFCValidator).ID)
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  DeactivatedValidatorStream,
+		Topic: contracts.DeactivatedValidatorTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseDeactivatedValidator(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCDeactivatedValidator(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCDeactivatedValidator)
+			c.updateValidator(ctx, item.ValidatorID, item.Apply)
+		},
+		Addresses: func(event pkg.Event) []string {
+			return c.getEventAddresses("", event.(pkg.SFCDeactivatedValidator).ValidatorID)
+		},
+		Render: c.renderDeactivatedValidatorMessage,
+		Retract: func(ctx context.Context, event pkg.Event) {
+			c.reloadValidator(ctx, event.(pkg.SFCDeactivatedValidator).ValidatorID)
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  ChangedValidatorStatusStream,
+		Topic: contracts.ChangedValidatorStatusTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseChangedValidatorStatus(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCChangedValidatorStatus(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCChangedValidatorStatus)
+			c.updateValidator(ctx, item.ValidatorID, item.Apply)
+			c.slashingMonitor.HandleStatusChange(ctx, item)
+		},
+		Addresses: func(event pkg.Event) []string {
+			return c.getEventAddresses("", event.(pkg.SFCChangedValidatorStatus).ValidatorID)
+		},
+		Render: c.renderChangedValidatorStatusMessage,
+		Retract: func(ctx context.Context, event pkg.Event) {
+			c.reloadValidator(ctx, event.(pkg.SFCChangedValidatorStatus).ValidatorID)
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  DelegateStream,
+		Topic: contracts.DelegatedTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseDelegated(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCDelegateInfo(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCDelegateInfo)
+			c.delegateInfoKeeper.AddDelegation(item)
+			c.capacityTracker.Update(item.ToValidatorID, item.BlockNumber)
+			if c.watchlist.Contains(item.Delegator) {
+				c.rewardsTracker.AddDelegation(item.Delegator, item.ToValidatorID)
+			}
+		},
+		Amount: func(event pkg.Event) float64 {
+			return event.(pkg.SFCDelegateInfo).Amount
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCDelegateInfo)
+			return c.getEventAddresses(item.Delegator, item.ToValidatorID)
+		},
+		Threshold: c.getMinStakingAmount,
+		Render:    c.renderDelegateMessage,
+		Retract: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCDelegateInfo)
+			c.delegateInfoKeeper.RemoveDelegation(item)
+			c.capacityTracker.Update(item.ToValidatorID, item.BlockNumber)
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  UndelegateStream,
+		Topic: contracts.UndelegatedTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseUndelegated(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCUndelegateInfo(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCUndelegateInfo)
+			c.undelegateInfoKeeper.AddWithdrawalRequest(item)
+			c.delegateInfoKeeper.AddUndelegation(item)
+			c.capacityTracker.Update(item.ToValidatorID, item.BlockNumber)
+			if c.withdrawalScheduler.IsTracked(item.Delegator) {
+				c.withdrawalScheduler.AddUndelegation(ctx, item)
+			}
+		},
+		Amount: func(event pkg.Event) float64 {
+			return event.(pkg.SFCUndelegateInfo).Amount
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCUndelegateInfo)
+			return c.getEventAddresses(item.Delegator, item.ToValidatorID)
+		},
+		Threshold: c.getMinStakingAmount,
+		Render:    c.renderUndelegateMessage,
+		Retract: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCUndelegateInfo)
+			c.undelegateInfoKeeper.RemoveByWithdrawalRequest(item.Delegator, item.ToValidatorID, item.WrID)
+			c.delegateInfoKeeper.RemoveUndelegation(item)
+			c.capacityTracker.Update(item.ToValidatorID, item.BlockNumber)
+			c.withdrawalScheduler.Remove(item.Delegator, item.ToValidatorID, item.WrID)
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  WithdrawnStream,
+		Topic: contracts.WithdrawnTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseWithdrawn(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCWithdrawn(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCWithdrawn)
+			c.withdrawalScheduler.Remove(item.Delegator, item.ToValidatorID, item.WrID)
+		},
+		Amount: func(event pkg.Event) float64 {
+			return event.(pkg.SFCWithdrawn).Amount
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCWithdrawn)
+			return c.getEventAddresses(item.Delegator, item.ToValidatorID)
+		},
+		Threshold: c.getMinStakingAmount,
+		Render:    c.renderWithdrawnMessage,
+		Done: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCWithdrawn)
+			c.undelegateInfoKeeper.RemoveByWithdrawalRequest(item.Delegator, item.ToValidatorID, item.WrID)
+		},
+		Retract: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCWithdrawn)
+			if c.withdrawalScheduler.IsTracked(item.Delegator) {
+				c.withdrawalScheduler.Track(ctx, item.Delegator, item.ToValidatorID, item.WrID)
+			}
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  LockedUpStakeStream,
+		Topic: contracts.LockedUpStakeTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseLockedUpStake(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCLockedUpStake(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCLockedUpStake)
+			if c.lockupScheduler.IsTracked(item.Delegator) {
+				c.lockupScheduler.Refresh(ctx, item.Delegator, item.ValidatorID)
+			}
+		},
+		Amount: func(event pkg.Event) float64 {
+			return event.(pkg.SFCLockedUpStake).Amount
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCLockedUpStake)
+			return c.getEventAddresses(item.Delegator, item.ValidatorID)
+		},
+		Threshold: c.getMinStakingAmount,
+		Render:    c.renderLockedUpStakeMessage,
+		Retract: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCLockedUpStake)
+			if c.lockupScheduler.IsTracked(item.Delegator) {
+				c.lockupScheduler.Refresh(ctx, item.Delegator, item.ValidatorID)
+			}
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  UnlockedStakeStream,
+		Topic: contracts.UnlockedStakeTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseUnlockedStake(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCUnlockedStake(event), nil
+		},
+		Handle: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCUnlockedStake)
+			if c.lockupScheduler.IsTracked(item.Delegator) {
+				c.lockupScheduler.Refresh(ctx, item.Delegator, item.ValidatorID)
+			}
+		},
+		Amount: func(event pkg.Event) float64 {
+			return event.(pkg.SFCUnlockedStake).Amount
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCUnlockedStake)
+			return c.getEventAddresses(item.Delegator, item.ValidatorID)
+		},
+		Threshold: c.getMinStakingAmount,
+		Render:    c.renderUnlockedStakeMessage,
+		Retract: func(ctx context.Context, event pkg.Event) {
+			item := event.(pkg.SFCUnlockedStake)
+			if c.lockupScheduler.IsTracked(item.Delegator) {
+				c.lockupScheduler.Refresh(ctx, item.Delegator, item.ValidatorID)
+			}
+		},
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  ClaimRewardStream,
+		Topic: contracts.ClaimedRewardsTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseClaimedRewards(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCRewardInfo(event), nil
+		},
+		Amount: func(event pkg.Event) float64 {
+			return event.(pkg.SFCRewardInfo).UnlockedReward
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCRewardInfo)
+			return c.getEventAddresses(item.Delegator, item.ToValidatorID)
+		},
+		Threshold: c.getMinClaimAmount,
+		Render:    c.renderClaimRewardMessage,
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  RestakeRewardStream,
+		Topic: contracts.RestakedRewardsTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseRestakedRewards(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCRestakedRewards(event), nil
+		},
+		Amount: func(event pkg.Event) float64 {
+			return event.(pkg.SFCRestakedRewards).TotalReward()
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCRestakedRewards)
+			return c.getEventAddresses(item.Delegator, item.ToValidatorID)
+		},
+		Threshold: c.getMinRestakeAmount,
+		Render:    c.renderRestakeRewardMessage,
+	})
+
+	c.registerGovernanceEventTypes()
+	return c.registerTokenEventTypes()
+}
 
-	c.l.Info("watch created validators")
+// registerGovernanceEventTypes declares the SFC parameter changes, they are always notified with a high priority.
+func (c *Core) registerGovernanceEventTypes() {
+	sfc := c.sfcClient.sfcContract
+
+	c.eventSource.Register(EventType{
+		Name:  UpdatedBaseRewardPerSecStream,
+		Topic: contracts.UpdatedBaseRewardPerSecTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseUpdatedBaseRewardPerSec(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCUpdatedBaseRewardPerSec(event), nil
+		},
+		Render:   c.renderUpdatedBaseRewardPerSecMessage,
+		Priority: notification.PriorityHigh,
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  UpdatedOfflinePenaltyThresholdStream,
+		Topic: contracts.UpdatedOfflinePenaltyThresholdTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseUpdatedOfflinePenaltyThreshold(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCUpdatedOfflinePenaltyThreshold(event), nil
+		},
+		Render:   c.renderUpdatedOfflinePenaltyThresholdMessage,
+		Priority: notification.PriorityHigh,
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  UpdatedSlashingRefundRatioStream,
+		Topic: contracts.UpdatedSlashingRefundRatioTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseUpdatedSlashingRefundRatio(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCUpdatedSlashingRefundRatio(event), nil
+		},
+		Addresses: func(event pkg.Event) []string {
+			return c.getEventAddresses("", event.(pkg.SFCUpdatedSlashingRefundRatio).ValidatorID)
+		},
+		Render:   c.renderUpdatedSlashingRefundRatioMessage,
+		Priority: notification.PriorityHigh,
+	})
+
+	c.eventSource.Register(EventType{
+		Name:  OwnershipTransferredStream,
+		Topic: contracts.OwnershipTransferredTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseOwnershipTransferred(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCOwnershipTransferred(event), nil
+		},
+		Addresses: func(event pkg.Event) []string {
+			item := event.(pkg.SFCOwnershipTransferred)
+			return []string{item.PreviousOwner, item.NewOwner}
+		},
+		Render:   c.renderOwnershipTransferredMessage,
+		Priority: notification.PriorityHigh,
+	})
+}
 
-	go c.sfcClient.WatchCreatedValidator(ctx, validatorCh, errCh)
+// registerTokenEventTypes watches the transfers of every configured ERC20 token, each token is
+// its own stream with its own threshold. The tokens are resolved on chain, it blocks for at most TokenResolveTimeout.
+func (c *Core) registerTokenEventTypes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), TokenResolveTimeout)
+	defer cancel()
 
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-errCh:
-			c.l.Warnw("reset WatchCreatedValidator subscription", "error", err)
-			<-ticker.C
-			go c.sfcClient.WatchCreatedValidator(ctx, validatorCh, errCh)
-		case validator := <-validatorCh:
-			c.l.Debugw("new created validator", "validator_id", validator.ID)
-			c.validatorKeeper.Add(validator)
-			c.sendCreatedValidatorMessage(validator)
-		}
+	tokens, err := LoadWatchedTokens(ctx, c.sfcClient)
+	if err != nil {
+		return err
 	}
-
+	for _, token := range tokens {
+		token := token
+		c.eventSource.Register(EventType{
+			Name:    token.StreamName(),
+			Topic:   contracts.TransferTopics,
+			Address: etherCommon.HexToAddress(token.Address),
+			Decode:  token.DecodeTransfer,
+			Amount: func(event pkg.Event) float64 {
+				return event.(pkg.TokenTransferLog).Amount
+			},
+			Threshold: func() float64 {
+				return token.Threshold
+			},
+			Addresses: func(event pkg.Event) []string {
+				item := event.(pkg.TokenTransferLog)
+				return []string{item.From, item.To}
+			},
+			Render: func(ctx context.Context, event pkg.Event) string {
+				item := event.(pkg.TokenTransferLog)
+				return c.renderTokenTransferMessage(item, item.Amount > token.Threshold)
+			},
+		})
+	}
+	return nil
 }
 
-func (c *Core) watchDelegateEvent(ctx context.Context) {
+func (c *Core) watchNewHead(ctx context.Context) {
 	var (
-		delegateInfoCh = make(chan pkg.SFCDelegateInfo)
-		errCh          = make(chan error)
+		headCh = make(chan pkg.BlockHead)
+		errCh  = make(chan error)
+		// blockCh holds the latest head for the block catch up, which runs off the head loop
+		blockCh = make(chan uint64, 1)
 	)
 
-	c.l.Info("watch delegate event")
-
-	go c.sfcClient.WatchDelegateEvent(ctx, delegateInfoCh, errCh)
+	go c.chainFeed.SubscribeNewHead(ctx, headCh, errCh)
+	go c.catchUpBlocks(ctx, blockCh)
 
+	c.l.Infow("watch new head", "polling", c.chainFeed.Polling(time.Now()))
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 	for {
@@ -251,312 +783,731 @@ func (c *Core) watchDelegateEvent(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case err := <-errCh:
-			c.l.Warnw("reset WatchDelegateEvent subscription", "error", err)
+			c.l.Warnw("reset SubscribeNewHead subscription", "error", err)
 			<-ticker.C
-			go c.sfcClient.WatchDelegateEvent(ctx, delegateInfoCh, errCh)
-		case info := <-delegateInfoCh:
-			if info.Amount > c.minStakingAmount {
-				c.l.Debugw("new delegate event", "tx_hash", info.TxHash)
-				// c.delegateInfoKeeper.Add(info)
-				c.sendDelegateMessage(info)
+			go c.chainFeed.SubscribeNewHead(ctx, headCh, errCh)
+		case head := <-headCh:
+			c.chainMonitor.OnHead(head.Number, time.Now())
+			c.confirmations.AddHead(ctx, head)
+			select {
+			case <-blockCh:
+			default:
 			}
+			blockCh <- head.Number
+			c.eventSource.saveCheckpoints()
 		}
 	}
 }
 
-func (c *Core) watchUndelegateEvent(ctx context.Context) {
-	var (
-		undelegateInfoCh = make(chan pkg.SFCUndelegateInfo)
-		errCh            = make(chan error)
-	)
-
-	c.l.Info("watch unstake event")
-
-	go c.sfcClient.WatchUndelegateEvent(ctx, undelegateInfoCh, errCh)
-
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+// catchUpBlocks scans the blocks up to the latest head received on blockCh, a backfill does not
+// hold back the heads, it resumes from the newest head once done.
+func (c *Core) catchUpBlocks(ctx context.Context, blockCh <-chan uint64) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case err := <-errCh:
-			c.l.Warnw("reset WatchUndelegateEvent subscription", "error", err)
-			<-ticker.C
-			go c.sfcClient.WatchUndelegateEvent(ctx, undelegateInfoCh, errCh)
-		case info := <-undelegateInfoCh:
-			if info.Amount > c.minStakingAmount {
-				c.l.Debugw("new undelegate event", "tx_hash", info.TxHash)
-				// c.undelegateInfoKeeper.Add(info)
-				c.sendUndelegateMessage(info)
+		case toBlock := <-blockCh:
+			c.catchUpFTMTransferEvent(ctx, toBlock)
+			if c.sfcCallMonitor.Enabled() {
+				c.catchUpSFCCalls(ctx, toBlock)
 			}
-
 		}
 	}
 }
 
-func (c *Core) watchLockedUpStakeEvent(ctx context.Context) {
-	var (
-		lockedUpStakeCh = make(chan pkg.SFCLockedUpStake)
-		errCh           = make(chan error)
-	)
-
-	c.l.Info("watch locked up stake event")
+// catchUpFTMTransferEvent scans every block between the last handled block and toBlock,
+// so that blocks skipped during a subscription reset are never lost.
+func (c *Core) catchUpFTMTransferEvent(ctx context.Context, toBlock uint64) {
+	c.eventSource.catchUp(ctx, FTMTransferStream, &toBlock, func(fromBlock, toBlock uint64) error {
+		for blockNumber := fromBlock; blockNumber <= toBlock; blockNumber++ {
+			if err := c.handleFTMTransferByBlock(ctx, blockNumber); err != nil {
+				return err
+			}
+			c.eventSource.saveCheckpoint(FTMTransferStream, blockNumber)
+		}
+		return nil
+	})
+}
 
-	go c.sfcClient.WatchLockedUpStakeEvent(ctx, lockedUpStakeCh, errCh)
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-errCh:
-			c.l.Warnw("reset WatchLockedUpStakeEvent subscription", "error", err)
-			<-ticker.C
-			go c.sfcClient.WatchLockedUpStakeEvent(ctx, lockedUpStakeCh, errCh)
-		case info := <-lockedUpStakeCh:
-			if info.Amount > c.minStakingAmount {
-				c.l.Debugw("new locked up stake event", "tx_hash", info.TxHash)
-				c.sendLockedUpStakeMessage(info)
+// catchUpSFCCalls decodes the SFC calls of every block between the last handled block and toBlock.
+func (c *Core) catchUpSFCCalls(ctx context.Context, toBlock uint64) {
+	c.eventSource.catchUp(ctx, SFCCallStream, &toBlock, func(fromBlock, toBlock uint64) error {
+		for blockNumber := fromBlock; blockNumber <= toBlock; blockNumber++ {
+			if err := c.sfcCallMonitor.HandleBlock(ctx, blockNumber); err != nil {
+				return err
 			}
+			c.eventSource.saveCheckpoint(SFCCallStream, blockNumber)
 		}
-	}
+		return nil
+	})
 }
 
-func (c *Core) watchUnlockedStakeEvent(ctx context.Context) {
-	var (
-		unlockedStakeCh = make(chan pkg.SFCUnlockedStake)
-		errCh           = make(chan error)
-	)
+func (c *Core) confirmSFCCall(ctx context.Context, tx pkg.DecodedTx) {
+	c.eventSource.confirm(ctx, tx, func(event pkg.Event) Announcement {
+		return c.sendSFCCallMessage(event.(pkg.DecodedTx))
+	}, nil)
+}
 
-	c.l.Info("watch unlocked stake event")
+func (c *Core) sendSFCCallMessage(tx pkg.DecodedTx) Announcement {
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	var msg string
+	if tx.Success {
+		msg = fmt.Sprintf("%v An unusual <a href=\"%s/tx/%s\">SFC call</a> <code>%s</code> from <code>%s</code>",
+			notification.EmojiInformation, explorerEndpoint, tx.TxHash, tx.Call(), c.getContactName(tx.From))
+	} else {
+		msg = fmt.Sprintf("%v A failed <a href=\"%s/tx/%s\">SFC call</a> <code>%s</code> from <code>%s</code>",
+			notification.EmojiCrossMark, explorerEndpoint, tx.TxHash, tx.Call(), c.getContactName(tx.From))
+		if tx.Error != "" {
+			msg += fmt.Sprintf(": %s", tx.Error)
+		}
+	}
+	if len(tx.Events) > 0 {
+		var events = make([]string, 0, len(tx.Events))
+		for _, event := range tx.Events {
+			events = append(events, event.Name)
+		}
+		msg += fmt.Sprintf(", events %s", strings.Join(events, ", "))
+	}
 
-	go c.sfcClient.WatchUnlockedStakeEvent(ctx, unlockedStakeCh, errCh)
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-errCh:
-			c.l.Warnw("reset WatchUnlockedStakeEvent subscription", "error", err)
-			<-ticker.C
-			go c.sfcClient.WatchUnlockedStakeEvent(ctx, unlockedStakeCh, errCh)
-		case info := <-unlockedStakeCh:
-			if info.Amount > c.minStakingAmount {
-				c.l.Debugw("new unlocked stake event", "tx_hash", info.TxHash)
-				c.sendUnlockedStakeMessage(info)
-			}
+	if err := c.SendMessage(msg); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+	watched := c.watchlist.Contains(tx.From)
+	if watched {
+		if err := c.sendWatchlistMessage(msg, notification.PriorityNormal, true); err != nil {
+			c.l.Debugw("bot send message error", "error", err)
 		}
 	}
+	return Announcement{Main: true, Watched: watched}
 }
 
-func (c *Core) watchClaimRewardEvent(ctx context.Context) {
+func (c *Core) sendChainModeMessage(polling bool) {
+	msg := fmt.Sprintf("%v The ws endpoint works again, the bot uses the ws subscriptions", notification.EmojiCheckMark)
+	if polling {
+		msg = fmt.Sprintf("%v The ws endpoint keeps failing, the bot polls the rpc endpoint", notification.EmojiWarning)
+	}
+	if err := c.SendMessageWithPriority(msg, notification.PriorityHigh); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
+
+func (c *Core) sendLivenessMessage(ctx context.Context, alert LivenessAlert) {
 	var (
-		rewardInfoCh = make(chan pkg.SFCRewardInfo)
-		errCh        = make(chan error)
+		msg      string
+		priority = notification.PriorityNormal
 	)
+	switch alert.Kind {
+	case ChainStalled:
+		msg = fmt.Sprintf("%v The chain is stalled: no new block for <b>%s</b> on any endpoint, last block <b>%d</b>",
+			notification.EmojiSiren, pkg.FormatDuration(alert.Since), alert.MaxHeight)
+		priority = notification.PriorityHigh
+	case ChainResumed:
+		msg = fmt.Sprintf("%v The chain produces blocks again, latest block <b>%d</b>", notification.EmojiCheckMark, alert.MaxHeight)
+	case HeadSubscriptionSilent:
+		msg = fmt.Sprintf("%v No head received from the ws subscription for <b>%s</b> while the chain is at block <b>%d</b>",
+			notification.EmojiWarning, pkg.FormatDuration(alert.Since), alert.MaxHeight)
+		priority = notification.PriorityHigh
+	case HeadSubscriptionResumed:
+		msg = fmt.Sprintf("%v The ws head subscription receives heads again", notification.EmojiCheckMark)
+	case EndpointStateChanged:
+		switch alert.State {
+		case EndpointUnreachable:
+			msg = fmt.Sprintf("%v The <b>%s</b> endpoint is unreachable", notification.EmojiWarning, alert.Endpoint)
+		case EndpointLagging:
+			msg = fmt.Sprintf("%v The <b>%s</b> endpoint lags <b>%d</b> blocks behind (block %d, latest %d)",
+				notification.EmojiWarning, alert.Endpoint, alert.MaxHeight-alert.Height, alert.Height, alert.MaxHeight)
+		default:
+			msg = fmt.Sprintf("%v The <b>%s</b> endpoint is healthy again at block %d", notification.EmojiCheckMark, alert.Endpoint, alert.Height)
+		}
+	}
 
-	c.l.Info("watch claim reward event")
-
-	go c.sfcClient.WatchClaimRewardEvent(ctx, rewardInfoCh, errCh)
+	if err := c.SendMessageWithPriority(msg, priority); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
 
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-errCh:
-			c.l.Warnw("reset WatchClaimRewardEvent subscription", "error", err)
-			<-ticker.C
-			go c.sfcClient.WatchClaimRewardEvent(ctx, rewardInfoCh, errCh)
-		case info := <-rewardInfoCh:
-			if info.UnlockedReward > c.minClaimAmount {
-				c.l.Debugw("new claim reward event", "tx_hash", info.TxHash)
-				// c.rewardInfoKeeper.Add(info)
-				c.sendClaimRewardMessage(info)
-			}
+func (c *Core) sendPendingTxMessage(ctx context.Context, update PendingTxUpdate) {
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	tx := update.Tx
+	var msg string
+	switch update.Status {
+	case PendingTxSeen:
+		if tx.Kind == PendingSFCCall {
+			msg = fmt.Sprintf("%v Pending <a href=\"%s/tx/%s\">SFC call</a> <code>%s</code> from <code>%s</code>",
+				notification.EmojiHourglass, explorerEndpoint, tx.Hash, tx.Call(), c.getContactName(tx.From))
+		} else {
+			msg = fmt.Sprintf("%v Pending <a href=\"%s/tx/%s\">transfer</a> of <b>%f FTM</b> from <code>%s</code> to <code>%s</code>",
+				notification.EmojiHourglass, explorerEndpoint, tx.Hash, tx.Value, c.getContactName(tx.From), c.getContactName(tx.To))
+		}
+	case PendingTxMined:
+		if update.Success {
+			msg = fmt.Sprintf("%v The pending <a href=\"%s/tx/%s\">transaction</a> of <code>%s</code> was mined in block <b>%d</b>",
+				notification.EmojiCheckMark, explorerEndpoint, tx.Hash, c.getContactName(tx.From), update.BlockNumber)
+		} else {
+			msg = fmt.Sprintf("%v The pending <a href=\"%s/tx/%s\">transaction</a> of <code>%s</code> was mined in block <b>%d</b> but failed",
+				notification.EmojiCrossMark, explorerEndpoint, tx.Hash, c.getContactName(tx.From), update.BlockNumber)
 		}
+	case PendingTxReplaced:
+		msg = fmt.Sprintf("%v The pending <a href=\"%s/tx/%s\">transaction</a> of <code>%s</code> was replaced by another one with the same nonce",
+			notification.EmojiRepeat, explorerEndpoint, tx.Hash, c.getContactName(tx.From))
+	case PendingTxDropped:
+		msg = fmt.Sprintf("%v The pending <a href=\"%s/tx/%s\">transaction</a> of <code>%s</code> was dropped from the mempool",
+			notification.EmojiWarning, explorerEndpoint, tx.Hash, c.getContactName(tx.From))
+	}
+
+	if err := c.SendMessage(msg); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
 	}
 }
 
-func (c *Core) watchFTMTransferEvent(ctx context.Context) {
-	var (
-		headCh = make(chan *types.Header)
-		errCh  = make(chan error)
-	)
+func (c *Core) handleFTMTransferByBlock(ctx context.Context, blockNumber uint64) error {
+	for _, f := range c.fetchers {
+		logs, err := f.GetListFTMTransferByBlock(ctx, blockNumber)
+		if err != nil {
+			continue
+		}
 
-	go c.sfcClient.SubscribeNewHead(ctx, headCh, errCh)
+		for _, item := range logs {
+			c.eventSource.confirm(ctx, item, c.handleFTMTransfer, nil)
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot fetch FTM transfers of block %d from any fetcher", blockNumber)
+}
 
-	c.l.Info("watch FTM transfer event")
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-errCh:
-			c.l.Warnw("reset WatchFTMTransferEvent subscription", "error", err)
-			<-ticker.C
-			go c.sfcClient.SubscribeNewHead(ctx, headCh, errCh)
-		case head := <-headCh:
-			blockNumber := head.Number.Uint64()
-			blockNumber = blockNumber - ShiftBlock
-		L1:
-			for _, f := range c.fetchers {
-				logs, err := f.GetListFTMTransferByBlock(ctx, blockNumber)
-				if err != nil {
-					continue
-				}
-
-				for _, item := range logs {
-					if item.Amount > c.minTransferAmount {
-						c.l.Debugw("new big transfer event", "tx_hash", item.TxHash, "block_number", item.BlockNumber)
-						c.sendBigTransferMessage(item)
-					}
-				}
-				break L1
-			}
+func (c *Core) handleFTMTransfer(event pkg.Event) Announcement {
+	item := event.(pkg.TransferLog)
+	big := item.Amount > c.minTransferAmount
+	watched := c.watchlist.ContainsAny(item.From, item.To)
+	if !big && !watched {
+		return Announcement{}
+	}
 
+	c.l.Debugw("new transfer event", "tx_hash", item.TxHash, "block_number", item.BlockNumber, "big", big, "watched", watched)
+	msg := c.renderTransferMessage(item, big)
+	if big {
+		if err := c.SendMessage(msg); err != nil {
+			c.l.Debugw("bot send message error", "error", err)
+		}
+	}
+	if watched {
+		if err := c.sendWatchlistMessage(msg, notification.PriorityNormal, big); err != nil {
+			c.l.Debugw("bot send message error", "error", err)
 		}
 	}
+	return Announcement{Main: big, Watched: watched}
 }
 
 func (c *Core) SendMessage(msg string) error {
+	return c.SendMessageWithPriority(msg, notification.PriorityNormal)
+}
+
+func (c *Core) SendMessageWithPriority(msg string, priority notification.Priority) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	for _, socialBot := range c.socialBots {
-		if err := socialBot.SendMessage(msg); err != nil {
+		if err := socialBot.SendMessageWithPriority(msg, priority); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (c *Core) sendCreatedValidatorMessage(item pkg.SFCValidator) {
+// sendWatchlistMessage sends a message about a watched address to the watchlist chat,
+// or to the main chats when there is no watchlist chat and the message has not been sent there yet.
+func (c *Core) sendWatchlistMessage(msg string, priority notification.Priority, notified bool) error {
+	if c.watchlistBot != nil {
+		return c.watchlistBot.SendMessageWithPriority(fmt.Sprintf("%v Watchlist: %s", notification.EmojiEyes, msg), priority)
+	}
+	if notified {
+		return nil
+	}
+	return c.SendMessageWithPriority(msg, priority)
+}
+
+func (c *Core) renderCreatedValidatorMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCValidator)
 	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
-	msg := fmt.Sprintf("A new <a href=\"%s/address/%s\">created validator</a> with ID <b>%v</b> ",
+	return fmt.Sprintf("A new <a href=\"%s/address/%s\">created validator</a> with ID <b>%v</b> ",
 		explorerEndpoint, item.Address, item.ID)
+}
 
-	if err := c.SendMessage(msg); err != nil {
-		c.l.Debugw("bot send message error", "error", err)
+func (c *Core) renderTransferMessage(item pkg.TransferLog, big bool) string {
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	if big {
+		return fmt.Sprintf("%v Big <a href=\"%s/tx/%s\">transfer</a> of <b>%f FTM</b> from <code>%s</code> to <code>%s</code>",
+			notification.EmojiWhale, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.From), c.getContactName(item.To))
 	}
+	return fmt.Sprintf("A <a href=\"%s/tx/%s\">transfer</a> of <b>%f FTM</b> from <code>%s</code> to <code>%s</code>",
+		explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.From), c.getContactName(item.To))
 }
 
-func (c *Core) sendBigTransferMessage(item pkg.TransferLog) {
+func (c *Core) renderTokenTransferMessage(item pkg.TokenTransferLog, big bool) string {
 	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
-	msg := fmt.Sprintf("%v Big <a href=\"%s/tx/%s\">transfer</a> of <b>%f FTM</b> from <code>%s</code> to <code>%s</code>",
-		notification.EmojiWhale, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.From), c.getContactName(item.To))
-
-	if err := c.SendMessage(msg); err != nil {
-		c.l.Debugw("bot send message error", "error", err)
+	if big {
+		return fmt.Sprintf("%v Big <a href=\"%s/tx/%s\">transfer</a> of <b>%f %s</b> from <code>%s</code> to <code>%s</code>",
+			notification.EmojiWhale, explorerEndpoint, item.TxHash, item.Amount, item.Token.Symbol, c.getContactName(item.From), c.getContactName(item.To))
 	}
+	return fmt.Sprintf("A <a href=\"%s/tx/%s\">transfer</a> of <b>%f %s</b> from <code>%s</code> to <code>%s</code>",
+		explorerEndpoint, item.TxHash, item.Amount, item.Token.Symbol, c.getContactName(item.From), c.getContactName(item.To))
 }
 
-func (c *Core) sendDelegateMessage(item pkg.SFCDelegateInfo) {
+func (c *Core) renderDeactivatedValidatorMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCDeactivatedValidator)
 	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	return fmt.Sprintf("%v %s was <a href=\"%s/tx/%s\">deactivated</a> at epoch %d",
+		notification.EmojiWarning, c.getValidatorLink(item.ValidatorID), explorerEndpoint, item.TxHash, item.DeactivatedEpoch)
+}
 
-	validatorMsg := fmt.Sprintf("validator %v", c.getValidatorName(item.ToValidatorID))
+func (c *Core) renderChangedValidatorStatusMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCChangedValidatorStatus)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
 
-	validator := c.validatorKeeper.GetValidatorById(item.ToValidatorID)
-	if validator != nil {
-		validatorMsg = fmt.Sprintf("<a href=\"%s/address/%s\">validator %v</a>", explorerEndpoint, validator.Address, c.getValidatorName(item.ToValidatorID))
+	var changes = make([]string, 0)
+	if item.Status.IsOffline() {
+		changes = append(changes, "went offline")
 	}
+	if item.Status.IsDoubleSign() {
+		changes = append(changes, "was penalised for double signing")
+	}
+	if item.Status.IsWithdrawn() {
+		changes = append(changes, "withdrew its stake")
+	}
+	if item.Status.IsActive() {
+		return fmt.Sprintf("%v %s is <a href=\"%s/tx/%s\">active</a> again",
+			notification.EmojiCheckMark, c.getValidatorLink(item.ValidatorID), explorerEndpoint, item.TxHash)
+	}
+	if len(changes) == 0 {
+		changes = append(changes, fmt.Sprintf("changed status to %s", item.Status))
+	}
+	return fmt.Sprintf("%v %s <a href=\"%s/tx/%s\">%s</a>",
+		notification.EmojiWarning, c.getValidatorLink(item.ValidatorID), explorerEndpoint, item.TxHash, strings.Join(changes, " and "))
+}
+
+func (c *Core) renderDelegateMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCDelegateInfo)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
 	msg := fmt.Sprintf("%v A <a href=\"%s/tx/%s\">delegation event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
-		notification.EmojiCheckMark, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), validatorMsg)
+		notification.EmojiCheckMark, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), c.getValidatorLink(item.ToValidatorID))
+	// the capacity is read in the state of the event block so it includes the delegation
+	if capacity, err := c.capacityTracker.GetCapacityAt(ctx, item.ToValidatorID, item.BlockNumber); err == nil {
+		msg += fmt.Sprintf(", remaining room <b>%f FTM</b> (%.0f%% full)", capacity.Room(), capacity.Usage()*100)
+	}
+	return msg
+}
 
-	if err := c.SendMessage(msg); err != nil {
-		c.l.Debugw("bot send message error", "error", err)
+func (c *Core) renderUndelegateMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCUndelegateInfo)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	return fmt.Sprintf("%v An <a href=\"%s/tx/%s\">undelegation event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
+		notification.EmojiCrossMark, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), c.getValidatorLink(item.ToValidatorID))
+}
+
+func (c *Core) renderWithdrawnMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCWithdrawn)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	msg := fmt.Sprintf("%v A <a href=\"%s/tx/%s\">withdrawal event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
+		notification.EmojiMoneyWithWings, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), c.getValidatorLink(item.ToValidatorID))
+
+	undelegateInfo, err := c.getUndelegateInfoByWithdrawal(ctx, item)
+	if err != nil {
+		return msg
+	}
+	undelegatedTime, err := c.sfcClient.GetBlockTime(ctx, undelegateInfo.BlockNumber)
+	if err != nil {
+		return msg
+	}
+	withdrawnTime, err := c.sfcClient.GetBlockTime(ctx, item.BlockNumber)
+	if err != nil {
+		return msg
+	}
+	return msg + fmt.Sprintf(", after <b>%s</b> in the withdrawal queue since the <a href=\"%s/tx/%s\">undelegation</a>",
+		pkg.FormatDuration(withdrawnTime.Sub(undelegatedTime)), explorerEndpoint, undelegateInfo.TxHash)
+}
+
+// getUndelegateInfoByWithdrawal returns the undelegation linked to the withdrawal by (delegator, validatorID, wrID),
+// an undelegation missing from the keeper is searched in the UndelegationLookupBlocks before the withdrawal.
+func (c *Core) getUndelegateInfoByWithdrawal(ctx context.Context, item pkg.SFCWithdrawn) (pkg.SFCUndelegateInfo, error) {
+	if info, ok := c.undelegateInfoKeeper.GetByWithdrawalRequest(item.Delegator, item.ToValidatorID, item.WrID); ok {
+		return info, nil
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, UndelegationLookupTimeout)
+	defer cancel()
+
+	fromBlock, toBlock := undelegationLookupRange(item.BlockNumber)
+	info, err := c.sfcClient.GetUndelegateInfoByWithdrawalRequest(lookupCtx, item.Delegator, item.ToValidatorID, item.WrID, fromBlock, toBlock)
+	if err != nil {
+		c.l.Debugw("undelegation of withdrawal not found", "error", err, "tx_hash", item.TxHash)
+		return pkg.SFCUndelegateInfo{}, err
+	}
+	return info, nil
+}
+
+// undelegationLookupRange returns the blocks searched for the undelegation of a withdrawal in withdrawnBlock.
+func undelegationLookupRange(withdrawnBlock uint64) (uint64, uint64) {
+	if withdrawnBlock < UndelegationLookupBlocks {
+		return 0, withdrawnBlock
+	}
+	return withdrawnBlock - UndelegationLookupBlocks, withdrawnBlock
+}
+
+func (c *Core) renderClaimRewardMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCRewardInfo)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	return fmt.Sprintf("%v A <a href=\"%s/tx/%s\">reward claim event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
+		notification.EmojiStar, explorerEndpoint, item.TxHash, item.UnlockedReward, c.getContactName(item.Delegator), c.getValidatorLink(item.ToValidatorID))
+}
+
+func (c *Core) renderRestakeRewardMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCRestakedRewards)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	return fmt.Sprintf("%v A <a href=\"%s/tx/%s\">reward restake event</a> of <b>%f FTM</b> (lockup extra %f, lockup base %f, unlocked %f) from <code>%s</code> to %s",
+		notification.EmojiRepeat, explorerEndpoint, item.TxHash, item.TotalReward(), item.LockupExtraReward, item.LockupBaseReward, item.UnlockedReward,
+		c.getContactName(item.Delegator), c.getValidatorLink(item.ToValidatorID))
+}
+
+// previousBlock returns the block before the event, used to read the contract state before a change.
+func previousBlock(log pkg.EventLog) *uint64 {
+	blockNumber := log.BlockNumber - 1
+	return &blockNumber
+}
+
+func (c *Core) renderUpdatedBaseRewardPerSecMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCUpdatedBaseRewardPerSec)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	oldValue := "unknown"
+	if value, err := c.sfcClient.GetBaseRewardPerSecond(ctx, previousBlock(item.EventLog)); err == nil {
+		oldValue = fmt.Sprintf("%f FTM", value)
+	}
+	return fmt.Sprintf("%v The <a href=\"%s/tx/%s\">base reward per second</a> was updated from <b>%s</b> to <b>%f FTM</b>",
+		notification.EmojiWarning, explorerEndpoint, item.TxHash, oldValue, item.Value)
+}
+
+func (c *Core) renderUpdatedOfflinePenaltyThresholdMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCUpdatedOfflinePenaltyThreshold)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	oldValue := "unknown"
+	if blocksNum, period, err := c.sfcClient.GetOfflinePenaltyThreshold(ctx, previousBlock(item.EventLog)); err == nil {
+		oldValue = fmt.Sprintf("%d blocks and %s", blocksNum, pkg.FormatDuration(time.Duration(period)*time.Second))
+	}
+	return fmt.Sprintf("%v The <a href=\"%s/tx/%s\">offline penalty threshold</a> was updated from <b>%s</b> to <b>%d blocks and %s</b>",
+		notification.EmojiWarning, explorerEndpoint, item.TxHash, oldValue, item.BlocksNum, pkg.FormatDuration(time.Duration(item.Period)*time.Second))
+}
+
+func (c *Core) renderUpdatedSlashingRefundRatioMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCUpdatedSlashingRefundRatio)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	oldValue := "unknown"
+	if value, err := c.sfcClient.GetSlashingRefundRatio(ctx, item.ValidatorID, previousBlock(item.EventLog)); err == nil {
+		oldValue = fmt.Sprintf("%.2f%%", value*100)
 	}
+	return fmt.Sprintf("%v The <a href=\"%s/tx/%s\">slashing refund ratio</a> of %s was updated from <b>%s</b> to <b>%.2f%%</b>",
+		notification.EmojiWarning, explorerEndpoint, item.TxHash, c.getValidatorLink(item.ValidatorID), oldValue, item.RefundRatio*100)
 }
 
-func (c *Core) sendUndelegateMessage(item pkg.SFCUndelegateInfo) {
+func (c *Core) renderOwnershipTransferredMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCOwnershipTransferred)
 	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	return fmt.Sprintf("%v The <a href=\"%s/tx/%s\">ownership</a> of the SFC contract was transferred from <code>%s</code> to <code>%s</code>",
+		notification.EmojiWarning, explorerEndpoint, item.TxHash, c.getContactName(item.PreviousOwner), c.getContactName(item.NewOwner))
+}
+
+func (c *Core) renderLockedUpStakeMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCLockedUpStake)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	return fmt.Sprintf("%v A <a href=\"%s/tx/%s\">locked up stake event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
+		notification.EmojiLock, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), c.getValidatorLink(item.ValidatorID))
+}
 
-	validatorMsg := fmt.Sprintf("validator %v", c.getValidatorName(item.ToValidatorID))
+func (c *Core) renderUnlockedStakeMessage(ctx context.Context, event pkg.Event) string {
+	item := event.(pkg.SFCUnlockedStake)
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	return fmt.Sprintf("%v An <a href=\"%s/tx/%s\">unlocked stake event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
+		notification.EmojiUnlock, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), c.getValidatorLink(item.ValidatorID))
+}
+
+// getValidatorLink returns the validator name linked to its address on the explorer when the validator is known.
+func (c *Core) getValidatorLink(id uint64) string {
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+
+	validator := c.validatorKeeper.GetValidatorById(id)
+	if validator == nil {
+		return fmt.Sprintf("validator %v", c.getValidatorName(id))
+	}
+	return fmt.Sprintf("<a href=\"%s/address/%s\">validator %v</a>", explorerEndpoint, validator.Address, c.getValidatorName(id))
+}
+
+// getEventAddresses returns the delegator, when not empty, and the address of the validator.
+func (c *Core) getEventAddresses(delegator string, validatorID uint64) []string {
+	var addresses = make([]string, 0, 2)
+	if delegator != "" {
+		addresses = append(addresses, delegator)
+	}
+	if validator := c.validatorKeeper.GetValidatorById(validatorID); validator != nil {
+		addresses = append(addresses, validator.Address)
+	}
+	return addresses
+}
+
+func (c *Core) getMinStakingAmount() float64 {
+	return c.minStakingAmount
+}
+
+func (c *Core) getMinClaimAmount() float64 {
+	return c.minClaimAmount
+}
+
+func (c *Core) getMinRestakeAmount() float64 {
+	return c.minRestakeAmount
+}
 
-	validator := c.validatorKeeper.GetValidatorById(item.ToValidatorID)
-	if validator != nil {
-		validatorMsg = fmt.Sprintf("<a href=\"%s/address/%s\">validator %v</a>", explorerEndpoint, validator.Address, c.getValidatorName(item.ToValidatorID))
+func (c *Core) getContactName(address string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if name, ok := c.contactBook[strings.ToLower(address)]; ok {
+		return name
 	}
+	return address
+}
 
-	msg := fmt.Sprintf("%v An <a href=\"%s/tx/%s\">undelegation event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
-		notification.EmojiCrossMark, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), validatorMsg)
+func (c *Core) sendEpochSummaryMessage(ctx context.Context, snapshot pkg.EpochSnapshot) {
+	msg := fmt.Sprintf("%v Epoch <b>%d</b> sealed", notification.EmojiBarChart, snapshot.Epoch)
+	if previous, err := c.epochStore.Get(snapshot.Epoch - 1); err == nil && previous.EndTime > 0 {
+		msg += fmt.Sprintf(" after %s", pkg.FormatDuration(time.Duration(snapshot.EndTime-previous.EndTime)*time.Second))
+	}
+	msg += fmt.Sprintf(": <b>%d</b> validators, total stake <b>%f FTM</b>, base reward <b>%f FTM/s</b>, fees <b>%f FTM</b>",
+		len(snapshot.Validators), snapshot.TotalStake, snapshot.BaseRewardPerSecond, snapshot.EpochFee)
 
 	if err := c.SendMessage(msg); err != nil {
 		c.l.Debugw("bot send message error", "error", err)
 	}
 }
 
-func (c *Core) sendClaimRewardMessage(item pkg.SFCRewardInfo) {
-	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+func (c *Core) sendNetworkReportMessage(ctx context.Context, report NetworkReport) {
+	stats := report.Stats
+	msg := fmt.Sprintf("%v <b>Daily network report</b> at epoch %d\n", notification.EmojiBarChart, stats.Epoch)
+	msg += fmt.Sprintf("Total stake: <b>%f FTM</b>%s\n", stats.TotalStake, formatNetworkDeltas(report, func(d *NetworkStatsDelta) string {
+		return fmt.Sprintf("%+f FTM", d.TotalStake)
+	}))
+	msg += fmt.Sprintf("Active stake: <b>%f FTM</b>%s\n", stats.TotalActiveStake, formatNetworkDeltas(report, func(d *NetworkStatsDelta) string {
+		return fmt.Sprintf("%+f FTM", d.TotalActiveStake)
+	}))
+	msg += fmt.Sprintf("Total supply: <b>%f FTM</b>%s\n", stats.TotalSupply, formatNetworkDeltas(report, func(d *NetworkStatsDelta) string {
+		return fmt.Sprintf("%+f FTM", d.TotalSupply)
+	}))
+	msg += fmt.Sprintf("Staking ratio: <b>%.2f%%</b>%s\n", stats.StakingRatio()*100, formatNetworkDeltas(report, func(d *NetworkStatsDelta) string {
+		return fmt.Sprintf("%+.2f pts", d.StakingRatio*100)
+	}))
+	msg += fmt.Sprintf("Base reward: <b>%f FTM/s</b>", stats.BaseRewardPerSecond)
 
-	validatorMsg := fmt.Sprintf("validator %v", c.getValidatorName(item.ToValidatorID))
+	if err := c.SendMessage(msg); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
+
+// formatNetworkDeltas returns the available 24h and 7d deltas of a value as " (24h x, 7d y)".
+func formatNetworkDeltas(report NetworkReport, format func(d *NetworkStatsDelta) string) string {
+	var deltas = make([]string, 0, 2)
+	if report.Day != nil {
+		deltas = append(deltas, "24h "+format(report.Day))
+	}
+	if report.Week != nil {
+		deltas = append(deltas, "7d "+format(report.Week))
+	}
+	if len(deltas) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(deltas, ", "))
+}
 
-	validator := c.validatorKeeper.GetValidatorById(item.ToValidatorID)
-	if validator != nil {
-		validatorMsg = fmt.Sprintf("<a href=\"%s/address/%s\">validator %v</a>", explorerEndpoint, validator.Address, c.getValidatorName(item.ToValidatorID))
+func (c *Core) sendPendingRewardsMessage(ctx context.Context, rewards DelegationRewards) {
+	last, ok := rewards.Last()
+	if !ok {
+		return
 	}
+	msg := fmt.Sprintf("%v <code>%s</code> has <b>%f FTM</b> of pending rewards (stashed %f) on %s at epoch %d, it is worth claiming or restaking them",
+		notification.EmojiStar, c.getContactName(rewards.Delegator), last.Pending, last.Stashed, c.getValidatorLink(rewards.ValidatorID), last.Epoch)
 
-	msg := fmt.Sprintf("%v A <a href=\"%s/tx/%s\">reward claim event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
-		notification.EmojiStar, explorerEndpoint, item.TxHash, item.UnlockedReward, c.getContactName(item.Delegator), validatorMsg)
+	if err := c.sendWatchlistMessage(msg, notification.PriorityNormal, false); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
+
+func (c *Core) sendAPRDropMessage(ctx context.Context, alert APRDropAlert) {
+	params := c.aprEngine.GetRewardParams()
+	msg := fmt.Sprintf("%v The APR of %s dropped to <b>%.2f%%</b> (unlocked <b>%.2f%%</b>) over epochs %d to %d, the network median is <b>%.2f%%</b>",
+		notification.EmojiWarning, c.getValidatorLink(alert.APR.ValidatorID), alert.APR.FullAPR*100,
+		alert.APR.FullAPR*LockupRewardMultiplier(params, 0)*100, alert.APR.FromEpoch, alert.APR.ToEpoch, alert.Median*100)
 
 	if err := c.SendMessage(msg); err != nil {
 		c.l.Debugw("bot send message error", "error", err)
 	}
 }
 
-func (c *Core) sendLockedUpStakeMessage(item pkg.SFCLockedUpStake) {
+// SlashingReportMaxDelegators is the number of most impacted delegators listed in a slashing alert.
+const SlashingReportMaxDelegators = 10
+
+func (c *Core) sendSlashingMessage(ctx context.Context, report SlashingReport) {
 	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	msg := fmt.Sprintf("%v %s was <a href=\"%s/tx/%s\">slashed</a> for double signing: estimated slashed amount <b>%f FTM</b> of <b>%f FTM</b> staked, refund ratio <b>%.2f%%</b>, network total slashed stake <b>%f FTM</b>",
+		notification.EmojiSiren, c.getValidatorLink(report.ValidatorID), explorerEndpoint, report.TxHash,
+		report.SlashedAmount, report.ReceivedStake, report.RefundRatio*100, report.TotalSlashedStake)
+
+	for i, impact := range report.Impacts {
+		if i == SlashingReportMaxDelegators {
+			msg += fmt.Sprintf("\nand %d more known delegators", len(report.Impacts)-i)
+			break
+		}
+		msg += fmt.Sprintf("\n- <code>%s</code> loses <b>%f FTM</b> of <b>%f FTM</b>", c.getContactName(impact.Delegator), impact.Penalty, impact.Stake)
+	}
 
-	validatorMsg := fmt.Sprintf("validator %v", c.getValidatorName(item.ValidatorID))
+	if err := c.SendMessageWithPriority(msg, notification.PriorityHigh); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
 
-	validator := c.validatorKeeper.GetValidatorById(item.ValidatorID)
-	if validator != nil {
-		validatorMsg = fmt.Sprintf("<a href=\"%s/address/%s\">validator %v</a>", explorerEndpoint, validator.Address, c.getValidatorName(item.ValidatorID))
+func (c *Core) sendWithdrawalReminderMessage(ctx context.Context, withdrawal PendingWithdrawal, kind WithdrawalReminderKind) {
+	var msg string
+	switch kind {
+	case WithdrawalMatured:
+		msg = fmt.Sprintf("%v The withdrawal request %d of <b>%f FTM</b> from <code>%s</code> to %s is withdrawable",
+			notification.EmojiAlarmClock, withdrawal.WrID, withdrawal.Amount, c.getContactName(withdrawal.Delegator), c.getValidatorLink(withdrawal.ValidatorID))
+	case WithdrawalUnclaimed:
+		msg = fmt.Sprintf("%v The withdrawal request %d of <b>%f FTM</b> from <code>%s</code> to %s is still unclaimed %s after becoming withdrawable",
+			notification.EmojiWarning, withdrawal.WrID, withdrawal.Amount, c.getContactName(withdrawal.Delegator), c.getValidatorLink(withdrawal.ValidatorID),
+			pkg.FormatDuration(time.Since(time.Unix(int64(withdrawal.MaturedTime), 0))))
+	default:
+		return
 	}
 
-	msg := fmt.Sprintf("%v A <a href=\"%s/tx/%s\">locked up stake event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
-		notification.EmojiLock, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), validatorMsg)
+	if err := c.SendMessage(msg); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
+
+func (c *Core) sendLockupReminderMessage(ctx context.Context, lockup Lockup, kind LockupReminderKind) {
+	endTime := time.Unix(int64(lockup.EndTime), 0).UTC()
+	var msg string
+	switch kind {
+	case LockupExpiringSoon:
+		msg = fmt.Sprintf("%v The lockup of <b>%f FTM</b> from <code>%s</code> to %s ends in %s, on %s",
+			notification.EmojiAlarmClock, lockup.LockedStake, c.getContactName(lockup.Delegator), c.getValidatorLink(lockup.ValidatorID),
+			pkg.FormatDuration(time.Until(endTime)), endTime.Format(time.RFC1123))
+	case LockupExpired:
+		msg = fmt.Sprintf("%v The lockup of <b>%f FTM</b> from <code>%s</code> to %s has ended, it can be relocked or unlocked without penalty",
+			notification.EmojiUnlock, lockup.LockedStake, c.getContactName(lockup.Delegator), c.getValidatorLink(lockup.ValidatorID))
+	default:
+		return
+	}
 
 	if err := c.SendMessage(msg); err != nil {
 		c.l.Debugw("bot send message error", "error", err)
 	}
 }
 
-func (c *Core) sendUnlockedStakeMessage(item pkg.SFCUnlockedStake) {
-	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+func (c *Core) sendCapacityMessage(ctx context.Context, alert CapacityAlert) {
+	validator := c.getValidatorLink(alert.Capacity.ValidatorID)
+	var msg string
+	switch {
+	case alert.Level == CapacityFull:
+		msg = fmt.Sprintf("%v %s is full, new delegations are rejected until its self stake of <b>%f FTM</b> grows",
+			notification.EmojiLock, validator, alert.Capacity.SelfStake)
+	case alert.Level == CapacityNearlyFull && alert.PreviousLevel == CapacityOpen:
+		msg = fmt.Sprintf("%v %s is <b>%.0f%%</b> full, remaining room <b>%f FTM</b>",
+			notification.EmojiWarning, validator, alert.Capacity.Usage()*100, alert.Capacity.Room())
+	case alert.PreviousLevel == CapacityFull:
+		msg = fmt.Sprintf("%v %s accepts delegations again, remaining room <b>%f FTM</b>",
+			notification.EmojiUnlock, validator, alert.Capacity.Room())
+	default:
+		return
+	}
+
+	if err := c.SendMessage(msg); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
 
-	validatorMsg := fmt.Sprintf("validator %v", c.getValidatorName(item.ValidatorID))
+func (c *Core) sendDowntimeMessage(ctx context.Context, alert DowntimeAlert) {
+	validator := c.getValidatorLink(alert.Validator.ID)
+	threshold := fmt.Sprintf("%s and %d blocks", pkg.FormatDuration(time.Duration(alert.ThresholdTime)*time.Second), alert.ThresholdBlocks)
+	downtime := fmt.Sprintf("%s (%d blocks)", pkg.FormatDuration(time.Duration(alert.Validator.OfflineTime)*time.Second), alert.Validator.OfflineBlocks)
 
-	validator := c.validatorKeeper.GetValidatorById(item.ValidatorID)
-	if validator != nil {
-		validatorMsg = fmt.Sprintf("<a href=\"%s/address/%s\">validator %v</a>", explorerEndpoint, validator.Address, c.getValidatorName(item.ValidatorID))
+	var (
+		msg      string
+		priority = notification.PriorityNormal
+	)
+	switch alert.Level {
+	case DowntimeCritical:
+		msg = fmt.Sprintf("%v %s is offline for <b>%s</b> at epoch %d, <b>%.0f%%</b> of the penalty threshold of %s, it will be deactivated soon",
+			notification.EmojiSiren, validator, downtime, alert.Epoch, alert.Ratio*100, threshold)
+		priority = notification.PriorityHigh
+	case DowntimeWarning:
+		msg = fmt.Sprintf("%v %s is offline for <b>%s</b> at epoch %d, <b>%.0f%%</b> of the penalty threshold of %s",
+			notification.EmojiWarning, validator, downtime, alert.Epoch, alert.Ratio*100, threshold)
+	default:
+		msg = fmt.Sprintf("%v %s is producing blocks again at epoch %d", notification.EmojiCheckMark, validator, alert.Epoch)
 	}
 
-	msg := fmt.Sprintf("%v An <a href=\"%s/tx/%s\">unlocked stake event</a> of <b>%f FTM</b> from <code>%s</code> to %s",
-		notification.EmojiUnlock, explorerEndpoint, item.TxHash, item.Amount, c.getContactName(item.Delegator), validatorMsg)
+	if err := c.SendMessageWithPriority(msg, priority); err != nil {
+		c.l.Debugw("bot send message error", "error", err)
+	}
+}
+
+func (c *Core) sendValidatorChangeMessage(ctx context.Context, change ValidatorChange) {
+	validator := c.getValidatorLink(change.Validator.ID)
+	var msg string
+	switch change.Kind {
+	case ValidatorWentOffline:
+		msg = fmt.Sprintf("%v %s went offline", notification.EmojiWarning, validator)
+	case ValidatorBackOnline:
+		msg = fmt.Sprintf("%v %s is back online", notification.EmojiCheckMark, validator)
+	case ValidatorDeactivated:
+		msg = fmt.Sprintf("%v %s was deactivated at epoch %d", notification.EmojiWarning, validator, change.Validator.DeactivatedEpoch)
+	case ValidatorStakeAbove:
+		msg = fmt.Sprintf("%v The total stake of %s rose above <b>%f FTM</b>, now <b>%f FTM</b>",
+			notification.EmojiInformation, validator, change.Threshold, change.Validator.TotalStake)
+	case ValidatorStakeBelow:
+		msg = fmt.Sprintf("%v The total stake of %s fell below <b>%f FTM</b>, now <b>%f FTM</b>",
+			notification.EmojiInformation, validator, change.Threshold, change.Validator.TotalStake)
+	default:
+		return
+	}
 
 	if err := c.SendMessage(msg); err != nil {
 		c.l.Debugw("bot send message error", "error", err)
 	}
 }
 
-func (c *Core) getContactName(address string) string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if name, ok := c.contactBook[strings.ToLower(address)]; ok {
-		return name
+// updateValidator updates the validator in the keeper, an unknown validator is loaded from the SFC contract.
+func (c *Core) updateValidator(ctx context.Context, id uint64, update func(v *pkg.SFCValidator)) {
+	if c.validatorKeeper.Update(id, update) {
+		return
 	}
-	return address
+	validator, err := c.sfcClient.GetValidatorByID(ctx, id)
+	if err != nil {
+		return
+	}
+	c.validatorKeeper.Add(validator)
+}
+
+// reloadValidator replaces the validator with its state on chain, a validator unknown on chain is forgotten.
+func (c *Core) reloadValidator(ctx context.Context, id uint64) {
+	validator, err := c.sfcClient.GetValidatorByID(ctx, id)
+	if err != nil {
+		return
+	}
+	if validator.CreatedTime == 0 {
+		c.validatorKeeper.Remove(id)
+		return
+	}
+	c.validatorKeeper.Add(validator)
 }
 
 func (c *Core) getValidatorName(id uint64) string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if name, ok := c.validatorBook[id]; ok {
-		return name
+		return fmt.Sprintf("%s (%d)", name, id)
 	}
 	return strconv.FormatUint(id, 10)
 }
@@ -2,8 +2,10 @@ package core
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/quangkeu95/fantom-bot/lib/notification"
+	"github.com/quangkeu95/fantom-bot/pkg"
 )
 
 func (c *Core) AddChatGroup(token string, chatId int64) error {
@@ -41,3 +43,40 @@ func (c *Core) DeleteChatGroup(chatId int64) error {
 	c.socialBots = append(c.socialBots[:index], c.socialBots[index])
 	return nil
 }
+
+func (c *Core) AddWatchedAddress(address string) error {
+	return c.watchlist.Add(address)
+}
+
+func (c *Core) RemoveWatchedAddress(address string) error {
+	return c.watchlist.Remove(address)
+}
+
+func (c *Core) GetWatchlist() []string {
+	return c.watchlist.List()
+}
+
+// GetValidatorAPR returns the trailing APR of the delegations to the validator locked for lockupDuration, 0 means unlocked.
+func (c *Core) GetValidatorAPR(validatorID uint64, lockupDuration time.Duration) (float64, error) {
+	return c.aprEngine.GetLockupAPR(validatorID, lockupDuration)
+}
+
+// GetListValidatorAPR returns the trailing APR of every validator with the maximum lockup duration.
+func (c *Core) GetListValidatorAPR() map[uint64]pkg.ValidatorAPR {
+	return c.aprEngine.GetListValidatorAPR()
+}
+
+// GetWithdrawalCalendar returns the pending withdrawal requests of the watched addresses.
+func (c *Core) GetWithdrawalCalendar() []PendingWithdrawal {
+	return c.withdrawalScheduler.GetCalendar()
+}
+
+// GetPendingRewardsHistory returns the pending rewards history of the delegations of a watched address.
+func (c *Core) GetPendingRewardsHistory(delegator string) []DelegationRewards {
+	return c.rewardsTracker.GetHistory(delegator)
+}
+
+// GetListNetworkStats returns the network stats snapshots of the last days sorted by time.
+func (c *Core) GetListNetworkStats() []pkg.NetworkStats {
+	return c.statsCollector.GetListStats()
+}
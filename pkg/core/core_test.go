@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/quangkeu95/fantom-bot/lib/notification"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// fakeContractBackend answers the contract calls with the outputs set by method signature,
+// it records the block of every call.
+type fakeContractBackend struct {
+	bind.ContractBackend
+	outputs map[string][]byte
+	blocks  []*big.Int
+}
+
+func (b *fakeContractBackend) setOutput(signature string, values ...int64) {
+	var words = make([]*big.Int, 0, len(values))
+	for _, value := range values {
+		words = append(words, big.NewInt(value))
+	}
+	b.setBigOutput(signature, words...)
+}
+
+func (b *fakeContractBackend) setBigOutput(signature string, values ...*big.Int) {
+	var output []byte
+	for _, value := range values {
+		output = append(output, etherCommon.LeftPadBytes(value.Bytes(), 32)...)
+	}
+	b.outputs[string(selector(signature))] = output
+}
+
+// ftm converts an amount in FTM to wei.
+func ftm(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e18))
+}
+
+// selector returns the 4 bytes identifying the method in the call data.
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+// newFakeSFCClient returns an SFC client whose contract calls are answered by the backend.
+func newFakeSFCClient(backend *fakeContractBackend) (*SFCClient, error) {
+	sfc, err := contracts.NewSFC(etherCommon.HexToAddress("0xfc00face00000000000000000000000000000000"), backend)
+	if err != nil {
+		return nil, err
+	}
+	return &SFCClient{l: zap.S(), sfcContract: sfc}, nil
+}
+
+func (b *fakeContractBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.blocks = append(b.blocks, blockNumber)
+	if output, ok := b.outputs[string(call.Data[:4])]; ok {
+		return output, nil
+	}
+	return nil, errors.New("execution reverted")
+}
+
+type CoreTestSuite struct {
+	suite.Suite
+	backend *fakeContractBackend
+	core    *Core
+}
+
+func TestCoreTestSuite(t *testing.T) {
+	suite.Run(t, new(CoreTestSuite))
+}
+
+func (ts *CoreTestSuite) SetupTest() {
+	ts.backend = &fakeContractBackend{outputs: make(map[string][]byte)}
+	sfcClient, err := newFakeSFCClient(ts.backend)
+	ts.Assert().NoError(err)
+
+	ts.core = &Core{
+		sfcClient:       sfcClient,
+		validatorKeeper: keeper.NewValidatorsKeeper(),
+		contactBook:     map[string]string{"0x00000000000000000000000000000000000000aa": "alice"},
+		validatorBook:   map[uint64]string{12: "fantom"},
+	}
+	ts.core.validatorKeeper.Add(pkg.SFCValidator{ID: 12, Address: "0x00000000000000000000000000000000000000BB"})
+}
+
+func (ts *CoreTestSuite) TestRenderRestakeRewardMessage() {
+	assert := ts.Assert()
+
+	msg := ts.core.renderRestakeRewardMessage(context.Background(), pkg.SFCRestakedRewards{
+		Delegator:         "0x00000000000000000000000000000000000000AA",
+		ToValidatorID:     12,
+		LockupExtraReward: 20,
+		LockupBaseReward:  30,
+		UnlockedReward:    1500,
+		EventLog:          pkg.EventLog{TxHash: "0x01"},
+	})
+	assert.Equal(notification.EmojiRepeat+" A <a href=\"/tx/0x01\">reward restake event</a> of <b>1550.000000 FTM</b>"+
+		" (lockup extra 20.000000, lockup base 30.000000, unlocked 1500.000000) from <code>alice</code> to"+
+		" <a href=\"/address/0x00000000000000000000000000000000000000BB\">validator fantom (12)</a>", msg)
+}
+
+func (ts *CoreTestSuite) TestRenderUpdatedBaseRewardPerSecMessage() {
+	assert := ts.Assert()
+	ts.backend.setOutput("baseRewardPerSecond()", 1e18)
+
+	msg := ts.core.renderUpdatedBaseRewardPerSecMessage(context.Background(), pkg.SFCUpdatedBaseRewardPerSec{
+		Value:    2,
+		EventLog: pkg.EventLog{TxHash: "0x01", BlockNumber: 100},
+	})
+	assert.Equal(notification.EmojiWarning+" The <a href=\"/tx/0x01\">base reward per second</a> was updated"+
+		" from <b>1.000000 FTM</b> to <b>2.000000 FTM</b>", msg)
+	// the old value is read in the block before the update
+	assert.Equal([]*big.Int{big.NewInt(99)}, ts.backend.blocks)
+}
+
+func (ts *CoreTestSuite) TestRenderUpdatedOfflinePenaltyThresholdMessage() {
+	assert := ts.Assert()
+	ts.backend.setOutput("offlinePenaltyThreshold()", 1000, 3*24*3600)
+
+	msg := ts.core.renderUpdatedOfflinePenaltyThresholdMessage(context.Background(), pkg.SFCUpdatedOfflinePenaltyThreshold{
+		BlocksNum: 2000,
+		Period:    5 * 24 * 3600,
+		EventLog:  pkg.EventLog{TxHash: "0x01", BlockNumber: 100},
+	})
+	assert.Equal(notification.EmojiWarning+" The <a href=\"/tx/0x01\">offline penalty threshold</a> was updated"+
+		" from <b>1000 blocks and 3d 0h 0m</b> to <b>2000 blocks and 5d 0h 0m</b>", msg)
+	assert.Equal([]*big.Int{big.NewInt(99)}, ts.backend.blocks)
+}
+
+func (ts *CoreTestSuite) TestRenderUpdatedSlashingRefundRatioMessage() {
+	assert := ts.Assert()
+	ts.backend.setOutput("slashingRefundRatio(uint256)", 5e17)
+
+	item := pkg.SFCUpdatedSlashingRefundRatio{
+		ValidatorID: 12,
+		RefundRatio: 0.75,
+		EventLog:    pkg.EventLog{TxHash: "0x01", BlockNumber: 100},
+	}
+	msg := ts.core.renderUpdatedSlashingRefundRatioMessage(context.Background(), item)
+	assert.Equal(notification.EmojiWarning+" The <a href=\"/tx/0x01\">slashing refund ratio</a> of"+
+		" <a href=\"/address/0x00000000000000000000000000000000000000BB\">validator fantom (12)</a>"+
+		" was updated from <b>50.00%</b> to <b>75.00%</b>", msg)
+	assert.Equal([]*big.Int{big.NewInt(99)}, ts.backend.blocks)
+
+	// the update is still notified when the old value cannot be read
+	ts.backend.outputs = make(map[string][]byte)
+	msg = ts.core.renderUpdatedSlashingRefundRatioMessage(context.Background(), item)
+	assert.Contains(msg, "was updated from <b>unknown</b> to <b>75.00%</b>")
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	DowntimeWarningRatioFlag  = "downtime.warning_ratio"
+	DowntimeCriticalRatioFlag = "downtime.critical_ratio"
+	DowntimeValidatorIDsFlag  = "downtime.validator_ids"
+
+	DefaultDowntimeWarningRatio  = 0.5
+	DefaultDowntimeCriticalRatio = 0.8
+
+	DowntimeLevelsStorageKey = "downtime_levels"
+)
+
+type DowntimeLevel int
+
+const (
+	DowntimeOK DowntimeLevel = iota
+	DowntimeWarning
+	DowntimeCritical
+)
+
+// DowntimeRatio returns how close a validator is to the offline penalty, 1 means it will be deactivated.
+// A validator is deactivated when both its offline time and offline blocks reach the threshold,
+// so the ratio is the lowest of both.
+func DowntimeRatio(offlineTime, offlineBlocks, thresholdTime, thresholdBlocks uint64) float64 {
+	if thresholdTime == 0 || thresholdBlocks == 0 {
+		return 0
+	}
+	timeRatio := float64(offlineTime) / float64(thresholdTime)
+	blocksRatio := float64(offlineBlocks) / float64(thresholdBlocks)
+	if timeRatio < blocksRatio {
+		return timeRatio
+	}
+	return blocksRatio
+}
+
+// DowntimeAlert is sent when the downtime level of a validator changes.
+type DowntimeAlert struct {
+	Epoch           uint64
+	Validator       pkg.EpochValidator
+	Level           DowntimeLevel
+	PreviousLevel   DowntimeLevel
+	Ratio           float64
+	ThresholdTime   uint64
+	ThresholdBlocks uint64
+}
+
+// DowntimeMonitor compares the downtime of the validators of every sealed epoch with the offline penalty threshold.
+type DowntimeMonitor struct {
+	l             *zap.SugaredLogger
+	sfcClient     *SFCClient
+	storage       storage.KeyValueStorage
+	notify        func(ctx context.Context, alert DowntimeAlert)
+	warningRatio  float64
+	criticalRatio float64
+	validatorIDs  map[uint64]bool
+
+	levels map[uint64]DowntimeLevel
+	mu     sync.Mutex
+}
+
+func NewDowntimeMonitor(sfcClient *SFCClient, keyValueStorage storage.KeyValueStorage, notify func(ctx context.Context, alert DowntimeAlert)) *DowntimeMonitor {
+	l := zap.S()
+
+	warningRatio := DefaultDowntimeWarningRatio
+	if viper.IsSet(DowntimeWarningRatioFlag) {
+		warningRatio = viper.GetFloat64(DowntimeWarningRatioFlag)
+	}
+	criticalRatio := DefaultDowntimeCriticalRatio
+	if viper.IsSet(DowntimeCriticalRatioFlag) {
+		criticalRatio = viper.GetFloat64(DowntimeCriticalRatioFlag)
+	}
+
+	var ids = make([]uint64, 0)
+	if err := viper.UnmarshalKey(DowntimeValidatorIDsFlag, &ids); err != nil {
+		l.Errorw("error parse downtime validator ids", "error", err)
+	}
+	var validatorIDs = make(map[uint64]bool)
+	for _, id := range ids {
+		validatorIDs[id] = true
+	}
+
+	// the levels are kept across restarts, a validator still offline is not alerted again
+	var levels = make(map[uint64]DowntimeLevel)
+	if err := keyValueStorage.Get(DowntimeLevelsStorageKey, &levels); err != nil {
+		l.Debugw("no downtime levels found in storage", "error", err)
+		levels = make(map[uint64]DowntimeLevel)
+	}
+
+	return &DowntimeMonitor{
+		l:             l,
+		sfcClient:     sfcClient,
+		storage:       keyValueStorage,
+		notify:        notify,
+		warningRatio:  warningRatio,
+		criticalRatio: criticalRatio,
+		validatorIDs:  validatorIDs,
+		levels:        levels,
+		mu:            sync.Mutex{},
+	}
+}
+
+func (m *DowntimeMonitor) level(ratio float64) DowntimeLevel {
+	switch {
+	case ratio >= m.criticalRatio:
+		return DowntimeCritical
+	case ratio >= m.warningRatio:
+		return DowntimeWarning
+	default:
+		return DowntimeOK
+	}
+}
+
+// HandleEpoch notifies the validators whose downtime level changed during the epoch,
+// all the validators are monitored when no validator ID is configured.
+func (m *DowntimeMonitor) HandleEpoch(ctx context.Context, snapshot pkg.EpochSnapshot) {
+	thresholdBlocks, thresholdTime, err := m.sfcClient.GetOfflinePenaltyThreshold(ctx, nil)
+	if err != nil {
+		return
+	}
+
+	var alerts = make([]DowntimeAlert, 0)
+	m.mu.Lock()
+	for _, validator := range snapshot.Validators {
+		if len(m.validatorIDs) > 0 && !m.validatorIDs[validator.ID] {
+			continue
+		}
+		ratio := DowntimeRatio(validator.OfflineTime, validator.OfflineBlocks, thresholdTime, thresholdBlocks)
+		level, previousLevel := m.level(ratio), m.levels[validator.ID]
+		m.levels[validator.ID] = level
+		if level == previousLevel {
+			continue
+		}
+		alerts = append(alerts, DowntimeAlert{
+			Epoch:           snapshot.Epoch,
+			Validator:       validator,
+			Level:           level,
+			PreviousLevel:   previousLevel,
+			Ratio:           ratio,
+			ThresholdTime:   thresholdTime,
+			ThresholdBlocks: thresholdBlocks,
+		})
+	}
+	if len(alerts) > 0 {
+		m.saveLocked()
+	}
+	m.mu.Unlock()
+
+	for _, alert := range alerts {
+		m.l.Infow("validator downtime level changed", "validator_id", alert.Validator.ID, "level", alert.Level, "ratio", alert.Ratio)
+		m.notify(ctx, alert)
+	}
+}
+
+func (m *DowntimeMonitor) saveLocked() {
+	if err := m.storage.Set(DowntimeLevelsStorageKey, m.levels); err != nil {
+		m.l.Warnw("save downtime levels error", "error", err)
+	}
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type DowntimeMonitorTestSuite struct {
+	suite.Suite
+	monitor *DowntimeMonitor
+}
+
+func TestDowntimeMonitorTestSuite(t *testing.T) {
+	suite.Run(t, new(DowntimeMonitorTestSuite))
+}
+
+func (ts *DowntimeMonitorTestSuite) SetupTest() {
+	ts.monitor = &DowntimeMonitor{
+		warningRatio:  DefaultDowntimeWarningRatio,
+		criticalRatio: DefaultDowntimeCriticalRatio,
+	}
+}
+
+func (ts *DowntimeMonitorTestSuite) TestDowntimeRatio() {
+	assert := ts.Assert()
+
+	assert.Equal(float64(0), DowntimeRatio(100, 100, 0, 0))
+	// the lowest ratio is kept since both thresholds must be reached
+	assert.Equal(0.25, DowntimeRatio(500, 25, 1000, 100))
+	assert.Equal(0.5, DowntimeRatio(500, 100, 1000, 100))
+	assert.Equal(float64(2), DowntimeRatio(2000, 200, 1000, 100))
+}
+
+func (ts *DowntimeMonitorTestSuite) TestLevel() {
+	assert := ts.Assert()
+
+	assert.Equal(DowntimeOK, ts.monitor.level(0.1))
+	assert.Equal(DowntimeWarning, ts.monitor.level(0.5))
+	assert.Equal(DowntimeWarning, ts.monitor.level(0.79))
+	assert.Equal(DowntimeCritical, ts.monitor.level(0.8))
+	assert.Equal(DowntimeCritical, ts.monitor.level(1.5))
+}
+
+func (ts *DowntimeMonitorTestSuite) TestLevelsPersisted() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	backend := &fakeContractBackend{outputs: make(map[string][]byte)}
+	backend.setOutput("offlinePenaltyThreshold()", 100, 1000)
+	sfcClient, err := newFakeSFCClient(backend)
+	assert.NoError(err)
+
+	var (
+		storage = newMemoryStorage()
+		alerts  []DowntimeAlert
+	)
+	notify := func(ctx context.Context, alert DowntimeAlert) {
+		alerts = append(alerts, alert)
+	}
+	snapshot := pkg.EpochSnapshot{
+		Epoch:      100,
+		Validators: []pkg.EpochValidator{{ID: 12, OfflineTime: 600, OfflineBlocks: 60}, {ID: 13}},
+	}
+
+	NewDowntimeMonitor(sfcClient, storage, notify).HandleEpoch(ctx, snapshot)
+	assert.Equal(1, len(alerts))
+	assert.Equal(DowntimeWarning, alerts[0].Level)
+	assert.Equal(DowntimeOK, alerts[0].PreviousLevel)
+
+	// after a restart the validator still offline is not alerted again
+	monitor := NewDowntimeMonitor(sfcClient, storage, notify)
+	snapshot.Epoch++
+	monitor.HandleEpoch(ctx, snapshot)
+	assert.Equal(1, len(alerts))
+
+	snapshot.Epoch++
+	snapshot.Validators[0].OfflineTime, snapshot.Validators[0].OfflineBlocks = 0, 0
+	monitor.HandleEpoch(ctx, snapshot)
+	assert.Equal(2, len(alerts))
+	assert.Equal(DowntimeOK, alerts[1].Level)
+	assert.Equal(DowntimeWarning, alerts[1].PreviousLevel)
+}
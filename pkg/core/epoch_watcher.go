@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	EpochPollIntervalFlag    = "epoch_poll_interval"
+	EpochSummaryFlag         = "epoch_summary"
+	DefaultEpochPollInterval = 30 * time.Second
+	// MaxEpochCatchUp is the maximum number of missed epochs collected after a restart.
+	MaxEpochCatchUp = uint64(10)
+)
+
+// EpochWatcher detects the newly sealed epochs, saves their snapshot and passes it to the handlers.
+type EpochWatcher struct {
+	l         *zap.SugaredLogger
+	sfcClient *SFCClient
+	store     *storage.EpochStore
+	interval  time.Duration
+
+	handlers []func(ctx context.Context, snapshot pkg.EpochSnapshot)
+	mu       sync.RWMutex
+}
+
+func NewEpochWatcher(sfcClient *SFCClient, store *storage.EpochStore) *EpochWatcher {
+	interval := DefaultEpochPollInterval
+	if viper.IsSet(EpochPollIntervalFlag) {
+		interval = viper.GetDuration(EpochPollIntervalFlag)
+	}
+	return &EpochWatcher{
+		l:         zap.S(),
+		sfcClient: sfcClient,
+		store:     store,
+		interval:  interval,
+		handlers:  make([]func(ctx context.Context, snapshot pkg.EpochSnapshot), 0),
+		mu:        sync.RWMutex{},
+	}
+}
+
+// OnSealed registers a handler called with the snapshot of every sealed epoch, in epoch order.
+func (w *EpochWatcher) OnSealed(handler func(ctx context.Context, snapshot pkg.EpochSnapshot)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Run polls the sealed epoch until ctx is done.
+func (w *EpochWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *EpochWatcher) check(ctx context.Context) {
+	sealedEpoch, err := w.sfcClient.GetCurrentSealedEpoch(ctx)
+	if err != nil {
+		return
+	}
+
+	fromEpoch := sealedEpoch
+	if lastEpoch, ok := w.store.GetLastEpoch(); ok {
+		if lastEpoch >= sealedEpoch {
+			return
+		}
+		fromEpoch = lastEpoch + 1
+	}
+	if sealedEpoch-fromEpoch >= MaxEpochCatchUp {
+		w.l.Infow("too many missed epochs, skip the oldest ones", "from_epoch", fromEpoch, "sealed_epoch", sealedEpoch)
+		fromEpoch = sealedEpoch - MaxEpochCatchUp + 1
+	}
+
+	for epoch := fromEpoch; epoch <= sealedEpoch; epoch++ {
+		snapshot, err := w.sfcClient.GetEpochSnapshot(ctx, epoch)
+		if err != nil {
+			return
+		}
+		if err := w.store.Save(snapshot); err != nil {
+			w.l.Warnw("save epoch snapshot error", "error", err, "epoch", epoch)
+			return
+		}
+		w.l.Debugw("epoch sealed", "epoch", epoch, "validators", len(snapshot.Validators))
+
+		w.mu.RLock()
+		handlers := w.handlers
+		w.mu.RUnlock()
+		for _, handler := range handlers {
+			handler(ctx, snapshot)
+		}
+	}
+}
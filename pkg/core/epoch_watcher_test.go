@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type EpochWatcherTestSuite struct {
+	suite.Suite
+	backend *fakeContractBackend
+	store   *storage.EpochStore
+	watcher *EpochWatcher
+	sealed  []uint64
+}
+
+func TestEpochWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(EpochWatcherTestSuite))
+}
+
+func (ts *EpochWatcherTestSuite) SetupTest() {
+	ts.backend = &fakeContractBackend{outputs: make(map[string][]byte)}
+	sfcClient, err := newFakeSFCClient(ts.backend)
+	ts.Assert().NoError(err)
+
+	// every epoch has the same snapshot without validators
+	ts.backend.setOutput("getEpochSnapshot(uint256)", 1650000000, 0, 0, 0, 0, 0, 0)
+	ts.backend.setOutput("getEpochValidatorIDs(uint256)", 32, 0)
+
+	ts.sealed = nil
+	ts.store = storage.NewEpochStore(newMemoryStorage())
+	ts.watcher = &EpochWatcher{
+		l:         zap.S(),
+		sfcClient: sfcClient,
+		store:     ts.store,
+	}
+	ts.watcher.OnSealed(func(ctx context.Context, snapshot pkg.EpochSnapshot) {
+		ts.sealed = append(ts.sealed, snapshot.Epoch)
+	})
+}
+
+func (ts *EpochWatcherTestSuite) check(sealedEpoch int64) {
+	ts.backend.setOutput("currentSealedEpoch()", sealedEpoch)
+	ts.watcher.check(context.Background())
+}
+
+func (ts *EpochWatcherTestSuite) TestSealedEpochs() {
+	assert := ts.Assert()
+
+	// the first check only takes the current sealed epoch
+	ts.check(100)
+	assert.Equal([]uint64{100}, ts.sealed)
+
+	ts.check(100)
+	assert.Equal([]uint64{100}, ts.sealed)
+
+	ts.check(103)
+	assert.Equal([]uint64{100, 101, 102, 103}, ts.sealed)
+
+	lastEpoch, ok := ts.store.GetLastEpoch()
+	assert.True(ok)
+	assert.Equal(uint64(103), lastEpoch)
+	snapshot, err := ts.store.Get(102)
+	assert.NoError(err)
+	assert.Equal(uint64(102), snapshot.Epoch)
+	assert.Equal(uint64(1650000000), snapshot.EndTime)
+}
+
+func (ts *EpochWatcherTestSuite) TestMaxEpochCatchUp() {
+	assert := ts.Assert()
+	assert.NoError(ts.store.Save(pkg.EpochSnapshot{Epoch: 100}))
+
+	ts.check(200)
+	assert.Equal(int(MaxEpochCatchUp), len(ts.sealed))
+	assert.Equal(uint64(191), ts.sealed[0])
+	assert.Equal(uint64(200), ts.sealed[len(ts.sealed)-1])
+}
+
+func (ts *EpochWatcherTestSuite) TestSnapshotError() {
+	assert := ts.Assert()
+	assert.NoError(ts.store.Save(pkg.EpochSnapshot{Epoch: 100}))
+
+	// the epochs are retried at the next check when a snapshot cannot be read
+	delete(ts.backend.outputs, string(selector("getEpochSnapshot(uint256)")))
+	ts.check(102)
+	assert.Equal(0, len(ts.sealed))
+
+	ts.backend.setOutput("getEpochSnapshot(uint256)", 1650000000, 0, 0, 0, 0, 0, 0)
+	ts.check(102)
+	assert.Equal([]uint64{101, 102}, ts.sealed)
+}
@@ -0,0 +1,488 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/notification"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	MaxCatchUpBlocksFlag = "max_catch_up_blocks"
+
+	MinResubscribeBackoff = 1 * time.Second
+	MaxResubscribeBackoff = 1 * time.Minute
+	EventMetricsInterval  = 10 * time.Minute
+)
+
+// EventType declares an SFC event watched by the EventSource.
+type EventType struct {
+	// Name identifies the stream in checkpoints, metrics and logs.
+	Name string
+	// Topic is the ABI event topic of the logs.
+	Topic etherCommon.Hash
+	// Address is the contract emitting the logs, the SFC contract when empty.
+	Address etherCommon.Address
+	// Decode converts a raw log into a pkg type which keeps the position of the log, the abigen
+	// Parse functions leave the raw log of the parsed event empty so it is set before the conversion.
+	Decode func(log types.Log) (pkg.Event, error)
+	// Handle is called for every confirmed event, before the threshold is checked. Optional.
+	Handle func(ctx context.Context, event pkg.Event)
+	// Amount and Threshold filter out small events, the event is always notified when Amount is nil.
+	Amount    func(event pkg.Event) float64
+	Threshold func() float64
+	// Addresses returns the addresses involved in the event, an event of a watched address
+	// is always notified. Optional.
+	Addresses func(event pkg.Event) []string
+	// Render builds the notification message of the event.
+	Render func(ctx context.Context, event pkg.Event) string
+	// Priority of the notification, normal by default.
+	Priority notification.Priority
+	// Done is called for every confirmed event after it has been notified, it releases the
+	// state kept for Render. Optional.
+	Done func(ctx context.Context, event pkg.Event)
+	// Retract undoes the side effects of Handle when a confirmed event is removed from the chain
+	// by a reorg. Optional.
+	Retract func(ctx context.Context, event pkg.Event)
+}
+
+// Announcement tells the chats which received the notification of an event.
+type Announcement struct {
+	Main    bool
+	Watched bool
+}
+
+func (a Announcement) announced() bool {
+	return a.Main || a.Watched
+}
+
+type EventMetrics struct {
+	Received        uint64
+	Notified        uint64
+	Retracted       uint64
+	DecodeErrors    uint64
+	Resubscriptions uint64
+	LastBlock       uint64
+}
+
+// EventSource subscribes to the registered event types, replays the blocks missed since the
+// last checkpoint, passes the events through the confirmation manager and sends the notifications.
+type EventSource struct {
+	l                *zap.SugaredLogger
+	sfcClient        *SFCClient
+	feed             *ChainFeed
+	checkpoint       *storage.Checkpoint
+	confirmations    *ConfirmationManager
+	notify           func(msg string, priority notification.Priority) error
+	maxCatchUpBlocks uint64
+
+	watchlist     *Watchlist
+	notifyWatched func(msg string, priority notification.Priority, notified bool) error
+
+	eventTypes []EventType
+	metrics    map[string]*EventMetrics
+	// handled is the last block of every stream whose events have been handed to the confirmation manager
+	handled map[string]uint64
+
+	mu sync.RWMutex
+	wg sync.WaitGroup
+}
+
+func NewEventSource(sfcClient *SFCClient, feed *ChainFeed, checkpoint *storage.Checkpoint, confirmations *ConfirmationManager, notify func(msg string, priority notification.Priority) error) *EventSource {
+	return &EventSource{
+		l:                zap.S(),
+		sfcClient:        sfcClient,
+		feed:             feed,
+		checkpoint:       checkpoint,
+		confirmations:    confirmations,
+		notify:           notify,
+		maxCatchUpBlocks: viper.GetUint64(MaxCatchUpBlocksFlag),
+		eventTypes:       make([]EventType, 0),
+		metrics:          make(map[string]*EventMetrics),
+		handled:          make(map[string]uint64),
+		mu:               sync.RWMutex{},
+	}
+}
+
+// SetWatchlist makes the events of the watched addresses notified regardless of the thresholds,
+// notifyWatched receives them with whether they have already been sent by notify.
+func (s *EventSource) SetWatchlist(watchlist *Watchlist, notifyWatched func(msg string, priority notification.Priority, notified bool) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchlist = watchlist
+	s.notifyWatched = notifyWatched
+}
+
+func (s *EventSource) isWatched(eventType EventType, event pkg.Event) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.watchlist == nil || eventType.Addresses == nil {
+		return false
+	}
+	return s.watchlist.ContainsAny(eventType.Addresses(event)...)
+}
+
+func (s *EventSource) Register(eventType EventType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventTypes = append(s.eventTypes, eventType)
+	s.metrics[eventType.Name] = &EventMetrics{}
+}
+
+// Run starts watching every registered event type until ctx is done.
+func (s *EventSource) Run(ctx context.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, eventType := range s.eventTypes {
+		s.wg.Add(1)
+		go s.watch(ctx, eventType)
+	}
+
+	s.wg.Add(1)
+	go s.logMetrics(ctx)
+}
+
+// Wait blocks until all the watchers have stopped.
+func (s *EventSource) Wait() {
+	s.wg.Wait()
+}
+
+func (s *EventSource) Metrics() map[string]EventMetrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result = make(map[string]EventMetrics)
+	for name, metrics := range s.metrics {
+		result[name] = *metrics
+	}
+	return result
+}
+
+func (s *EventSource) updateMetrics(name string, update func(metrics *EventMetrics)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if metrics, ok := s.metrics[name]; ok {
+		update(metrics)
+	}
+}
+
+func (s *EventSource) logMetrics(ctx context.Context) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(EventMetricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for name, metrics := range s.Metrics() {
+				s.l.Infow("event metrics", "stream", name, "received", metrics.Received, "notified", metrics.Notified,
+					"retracted", metrics.Retracted, "decode_errors", metrics.DecodeErrors,
+					"resubscriptions", metrics.Resubscriptions, "last_block", metrics.LastBlock)
+			}
+		}
+	}
+}
+
+func (s *EventSource) watch(ctx context.Context, eventType EventType) {
+	defer s.wg.Done()
+	var (
+		logCh   = make(chan types.Log)
+		errCh   = make(chan error)
+		backoff = MinResubscribeBackoff
+	)
+
+	s.l.Infow("watch event", "stream", eventType.Name)
+
+	go s.feed.SubscribeLogs(ctx, s.contractAddress(eventType), eventType.Topic, logCh, errCh)
+	replayedBlock, synced := s.catchUpEventType(ctx, eventType, nil), false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-errCh:
+			s.l.Warnw("reset event subscription", "error", err, "stream", eventType.Name, "backoff", backoff)
+			s.updateMetrics(eventType.Name, func(metrics *EventMetrics) {
+				metrics.Resubscriptions++
+			})
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > MaxResubscribeBackoff {
+				backoff = MaxResubscribeBackoff
+			}
+			go s.feed.SubscribeLogs(ctx, s.contractAddress(eventType), eventType.Topic, logCh, errCh)
+			replayedBlock, synced = s.catchUpEventType(ctx, eventType, nil), false
+		case log := <-logCh:
+			backoff = MinResubscribeBackoff
+			event, err := s.decode(eventType, log)
+			if err != nil {
+				continue
+			}
+			if log.Removed {
+				s.confirmEventType(ctx, eventType, event)
+				continue
+			}
+			if log.BlockNumber <= replayedBlock {
+				continue
+			}
+			if !synced {
+				prevBlock := log.BlockNumber - 1
+				replayedBlock, synced = s.catchUpEventType(ctx, eventType, &prevBlock), true
+			}
+			s.confirmEventType(ctx, eventType, event)
+			s.saveCheckpoint(eventType.Name, log.BlockNumber)
+		}
+	}
+}
+
+func (s *EventSource) contractAddress(eventType EventType) etherCommon.Address {
+	if eventType.Address == (etherCommon.Address{}) {
+		return s.sfcClient.sfcAddress
+	}
+	return eventType.Address
+}
+
+func (s *EventSource) decode(eventType EventType, log types.Log) (pkg.Event, error) {
+	event, err := eventType.Decode(log)
+	if err != nil {
+		s.l.Warnw("decode event error", "error", err, "stream", eventType.Name, "tx_hash", log.TxHash.Hex())
+		s.updateMetrics(eventType.Name, func(metrics *EventMetrics) {
+			metrics.DecodeErrors++
+		})
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *EventSource) catchUpEventType(ctx context.Context, eventType EventType, toBlock *uint64) uint64 {
+	lastBlock, _ := s.catchUp(ctx, eventType.Name, toBlock, func(fromBlock, toBlock uint64) error {
+		logs, err := s.sfcClient.GetLogsByBlock(ctx, s.contractAddress(eventType), eventType.Topic, fromBlock, toBlock)
+		if err != nil {
+			return err
+		}
+		for _, log := range logs {
+			if event, err := s.decode(eventType, log); err == nil {
+				s.confirmEventType(ctx, eventType, event)
+			}
+		}
+		return nil
+	})
+	return lastBlock
+}
+
+// catchUp replays the blocks a stream has missed since the last handled block up to toBlock
+// (the latest block when toBlock is nil) and moves the checkpoint forward.
+// It returns the last block handled by the stream.
+// After a restart the stream resumes from its checkpoint, a stream without checkpoint starts
+// from toBlock, nothing is replayed.
+func (s *EventSource) catchUp(ctx context.Context, stream string, toBlock *uint64, replay func(fromBlock, toBlock uint64) error) (uint64, error) {
+	var endBlock uint64
+	if toBlock == nil {
+		latestBlock, err := s.sfcClient.GetLatestBlock(ctx)
+		if err != nil {
+			s.l.Warnw("get latest block error", "error", err, "stream", stream)
+			return 0, err
+		}
+		endBlock = latestBlock
+	} else {
+		endBlock = *toBlock
+	}
+
+	lastBlock, ok := s.getHandled(stream)
+	if !ok {
+		lastBlock, ok = s.checkpoint.Get(stream)
+	}
+	if !ok {
+		s.l.Infow("no checkpoint found, start from current block", "stream", stream, "block_number", endBlock)
+		s.setHandled(stream, endBlock)
+		return endBlock, s.checkpoint.Set(stream, endBlock)
+	}
+	if lastBlock >= endBlock {
+		return lastBlock, nil
+	}
+
+	startBlock := lastBlock + 1
+	if s.maxCatchUpBlocks > 0 && endBlock-startBlock+1 > s.maxCatchUpBlocks {
+		s.l.Warnw("too many missed blocks, skip the oldest ones", "stream", stream, "from_block", startBlock, "to_block", endBlock, "max_catch_up_blocks", s.maxCatchUpBlocks)
+		startBlock = endBlock - s.maxCatchUpBlocks + 1
+	}
+
+	s.l.Infow("catch up missed blocks", "stream", stream, "from_block", startBlock, "to_block", endBlock)
+	for startBlock <= endBlock {
+		nextBlock := startBlock + BlockRange - 1
+		if nextBlock > endBlock {
+			nextBlock = endBlock
+		}
+		if err := replay(startBlock, nextBlock); err != nil {
+			s.l.Warnw("catch up error", "error", err, "stream", stream, "from_block", startBlock, "to_block", nextBlock)
+			return lastBlock, err
+		}
+		s.saveCheckpoint(stream, nextBlock)
+		lastBlock = nextBlock
+		startBlock = nextBlock + 1
+	}
+	return lastBlock, nil
+}
+
+func (s *EventSource) getHandled(stream string) (uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	blockNumber, ok := s.handled[stream]
+	return blockNumber, ok
+}
+
+func (s *EventSource) setHandled(stream string, blockNumber uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if blockNumber > s.handled[stream] {
+		s.handled[stream] = blockNumber
+	}
+	if metrics, ok := s.metrics[stream]; ok && blockNumber > metrics.LastBlock {
+		metrics.LastBlock = blockNumber
+	}
+}
+
+// saveCheckpoint records the last block handled by the stream. The checkpoint only moves up to
+// the last confirmed block, so that a restart replays the events still waiting for confirmations.
+func (s *EventSource) saveCheckpoint(stream string, blockNumber uint64) {
+	s.setHandled(stream, blockNumber)
+	s.storeCheckpoint(stream, blockNumber)
+}
+
+// saveCheckpoints moves the checkpoint of every stream forward as its events get confirmed,
+// it is called on every new head.
+func (s *EventSource) saveCheckpoints() {
+	s.mu.RLock()
+	var handled = make(map[string]uint64, len(s.handled))
+	for stream, blockNumber := range s.handled {
+		handled[stream] = blockNumber
+	}
+	s.mu.RUnlock()
+
+	for stream, blockNumber := range handled {
+		s.storeCheckpoint(stream, blockNumber)
+	}
+}
+
+func (s *EventSource) storeCheckpoint(stream string, blockNumber uint64) {
+	if confirmedBlock := s.confirmations.ConfirmedBlock(); blockNumber > confirmedBlock {
+		blockNumber = confirmedBlock
+	}
+	if blockNumber == 0 {
+		return
+	}
+	if err := s.checkpoint.Set(stream, blockNumber); err != nil {
+		s.l.Warnw("save checkpoint error", "error", err, "stream", stream, "block_number", blockNumber)
+	}
+}
+
+func (s *EventSource) confirmEventType(ctx context.Context, eventType EventType, event pkg.Event) {
+	if !event.GetEventLog().Removed {
+		s.updateMetrics(eventType.Name, func(metrics *EventMetrics) {
+			metrics.Received++
+		})
+	}
+	s.confirm(ctx, event, func(event pkg.Event) Announcement {
+		if eventType.Handle != nil {
+			eventType.Handle(ctx, event)
+		}
+		if eventType.Done != nil {
+			defer eventType.Done(ctx, event)
+		}
+		aboveThreshold := eventType.Amount == nil || eventType.Amount(event) > eventType.Threshold()
+		watched := s.isWatched(eventType, event)
+		if !aboveThreshold && !watched {
+			return Announcement{}
+		}
+
+		log := event.GetEventLog()
+		s.l.Debugw("new event", "stream", eventType.Name, "tx_hash", log.TxHash, "block_number", log.BlockNumber, "watched", watched)
+		msg := eventType.Render(ctx, event)
+		if aboveThreshold {
+			if err := s.notify(msg, eventType.Priority); err != nil {
+				s.l.Debugw("bot send message error", "error", err)
+			}
+		}
+		if watched {
+			if err := s.notifyWatched(msg, eventType.Priority, aboveThreshold); err != nil {
+				s.l.Debugw("bot send message error", "error", err)
+			}
+		}
+		s.updateMetrics(eventType.Name, func(metrics *EventMetrics) {
+			metrics.Notified++
+		})
+		return Announcement{Main: aboveThreshold, Watched: watched}
+	}, func(event pkg.Event) {
+		if eventType.Retract != nil {
+			eventType.Retract(ctx, event)
+		}
+		s.updateMetrics(eventType.Name, func(metrics *EventMetrics) {
+			metrics.Retracted++
+		})
+	})
+}
+
+// confirm hands the event to the confirmation manager, handle is called once the event
+// is confirmed and returns the chats it has been announced to, onRetract is optional.
+// A retracted event is called onRetract even when it has not been announced, the retraction
+// message only goes to the chats which received the announcement.
+func (s *EventSource) confirm(ctx context.Context, event pkg.Event, handle func(event pkg.Event) Announcement, onRetract func(event pkg.Event)) {
+	var announcement Announcement
+	s.confirmations.Add(ctx, ConfirmationItem{
+		Event: event,
+		OnConfirm: func(event pkg.Event, corrected bool) bool {
+			announcement = handle(event)
+			if corrected {
+				s.sendCorrectionMessage(event, announcement)
+			}
+			return announcement.announced() || onRetract != nil
+		},
+		OnRetract: func(event pkg.Event) {
+			if onRetract != nil {
+				onRetract(event)
+			}
+			s.sendRetractionMessage(event, announcement)
+		},
+	})
+}
+
+func (s *EventSource) sendRetractionMessage(event pkg.Event, announcement Announcement) {
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	log := event.GetEventLog()
+	msg := fmt.Sprintf("%v The event of <a href=\"%s/tx/%s\">transaction</a> in block <b>%d</b> was removed from the chain by a reorg, please ignore its previous notification",
+		notification.EmojiWarning, explorerEndpoint, log.TxHash, log.BlockNumber)
+	s.announce(msg, announcement)
+}
+
+func (s *EventSource) sendCorrectionMessage(event pkg.Event, announcement Announcement) {
+	explorerEndpoint := viper.GetString("fantom_chain.explorer_tx_endpoint")
+	log := event.GetEventLog()
+	msg := fmt.Sprintf("%v Correction: the <a href=\"%s/tx/%s\">transaction</a> retracted before is included again in block <b>%d</b>",
+		notification.EmojiInformation, explorerEndpoint, log.TxHash, log.BlockNumber)
+	s.announce(msg, announcement)
+}
+
+// announce sends the message to the chats of the announcement.
+func (s *EventSource) announce(msg string, announcement Announcement) {
+	if announcement.Main {
+		if err := s.notify(msg, notification.PriorityNormal); err != nil {
+			s.l.Debugw("bot send message error", "error", err)
+		}
+	}
+	if announcement.Watched && s.notifyWatched != nil {
+		if err := s.notifyWatched(msg, notification.PriorityNormal, announcement.Main); err != nil {
+			s.l.Debugw("bot send message error", "error", err)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/quangkeu95/fantom-bot/lib/notification"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventSourceTestSuite struct {
+	suite.Suite
+	reader     *fakeBlockHeadReader
+	checkpoint *storage.Checkpoint
+	source     *EventSource
+	eventType  EventType
+	messages   []string
+}
+
+func TestEventSourceTestSuite(t *testing.T) {
+	suite.Run(t, new(EventSourceTestSuite))
+}
+
+func (ts *EventSourceTestSuite) SetupTest() {
+	assert := ts.Assert()
+
+	ts.messages = nil
+	ts.reader = &fakeBlockHeadReader{heads: make(map[string]pkg.BlockHead)}
+	ts.checkpoint = storage.NewCheckpoint(newMemoryStorage())
+
+	sfc, err := contracts.NewSFC(etherCommon.HexToAddress("0xfc00face00000000000000000000000000000000"), nil)
+	assert.NoError(err)
+	ts.eventType = EventType{
+		Name:  DelegateStream,
+		Topic: contracts.DelegatedTopics,
+		Decode: func(log types.Log) (pkg.Event, error) {
+			event, err := sfc.ParseDelegated(log)
+			if err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return pkg.ToSFCDelegateInfo(event), nil
+		},
+		Render: func(ctx context.Context, event pkg.Event) string {
+			item := event.(pkg.SFCDelegateInfo)
+			return fmt.Sprintf("%s delegated %.0f to %d in tx %s of block %d", item.Delegator, item.Amount, item.ToValidatorID, item.TxHash, item.BlockNumber)
+		},
+	}
+	ts.source = ts.newSource()
+}
+
+// newSource creates an event source on the checkpoint of the suite, like the bot does after a restart.
+func (ts *EventSourceTestSuite) newSource() *EventSource {
+	source := NewEventSource(nil, nil, ts.checkpoint, NewConfirmationManager(ts.reader, 2), func(msg string, priority notification.Priority) error {
+		ts.messages = append(ts.messages, msg)
+		return nil
+	})
+	source.Register(ts.eventType)
+	return source
+}
+
+func (ts *EventSourceTestSuite) addHead(number uint64) {
+	head := pkg.BlockHead{
+		Number:     number,
+		Hash:       blockHash(number).Hex(),
+		ParentHash: blockHash(number - 1).Hex(),
+	}
+	ts.reader.heads[head.Hash] = head
+	ts.source.confirmations.AddHead(context.Background(), head)
+	ts.source.saveCheckpoints()
+}
+
+// catchUp replays the blocks up to toBlock with the logs, it returns the replayed block ranges.
+func (ts *EventSourceTestSuite) catchUp(toBlock uint64, logs ...types.Log) [][2]uint64 {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	var replayed [][2]uint64
+	_, err := ts.source.catchUp(ctx, DelegateStream, &toBlock, func(fromBlock, toBlock uint64) error {
+		replayed = append(replayed, [2]uint64{fromBlock, toBlock})
+		for _, log := range logs {
+			if log.BlockNumber < fromBlock || log.BlockNumber > toBlock {
+				continue
+			}
+			event, err := ts.source.decode(ts.eventType, log)
+			if err != nil {
+				return err
+			}
+			ts.source.confirmEventType(ctx, ts.eventType, event)
+		}
+		return nil
+	})
+	assert.NoError(err)
+	return replayed
+}
+
+func (ts *EventSourceTestSuite) getCheckpoint() uint64 {
+	checkpoint, ok := ts.checkpoint.Get(DelegateStream)
+	ts.Assert().True(ok)
+	return checkpoint
+}
+
+func blockHash(number uint64) etherCommon.Hash {
+	return etherCommon.BigToHash(new(big.Int).SetUint64(0xb000 + number))
+}
+
+func delegatedLog(blockNumber uint64, txHash etherCommon.Hash) types.Log {
+	delegator := etherCommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	return types.Log{
+		Topics: []etherCommon.Hash{
+			contracts.DelegatedTopics,
+			delegator.Hash(),
+			etherCommon.BigToHash(big.NewInt(12)),
+		},
+		Data:        etherCommon.LeftPadBytes(new(big.Int).Mul(big.NewInt(5000), big.NewInt(1e18)).Bytes(), 32),
+		BlockNumber: blockNumber,
+		BlockHash:   blockHash(blockNumber),
+		TxHash:      txHash,
+		Index:       3,
+	}
+}
+
+func (ts *EventSourceTestSuite) TestDecodeKeepsPosition() {
+	assert := ts.Assert()
+
+	log := delegatedLog(10, etherCommon.HexToHash("0x01"))
+	event, err := ts.source.decode(ts.eventType, log)
+	assert.NoError(err)
+	assert.Equal(pkg.ToEventLog(log), event.GetEventLog())
+
+	item := event.(pkg.SFCDelegateInfo)
+	assert.Equal("0x00000000000000000000000000000000000000AA", item.Delegator)
+	assert.Equal(uint64(12), item.ToValidatorID)
+	assert.Equal(float64(5000), item.Amount)
+
+	_, err = ts.source.decode(ts.eventType, types.Log{Topics: []etherCommon.Hash{contracts.DelegatedTopics}})
+	assert.Error(err)
+	assert.Equal(uint64(1), ts.source.Metrics()[DelegateStream].DecodeErrors)
+}
+
+func (ts *EventSourceTestSuite) TestCatchUpConfirmAndCheckpoint() {
+	assert := ts.Assert()
+	assert.NoError(ts.checkpoint.Set(DelegateStream, 8))
+
+	txHash := etherCommon.HexToHash("0x01")
+	assert.Equal([][2]uint64{{9, 10}}, ts.catchUp(10, delegatedLog(10, txHash)))
+	// the event of block 10 waits for confirmations, the checkpoint does not move past it
+	assert.Equal(uint64(8), ts.getCheckpoint())
+
+	ts.addHead(10)
+	ts.addHead(11)
+	assert.Equal(0, len(ts.messages))
+	assert.Equal(uint64(9), ts.getCheckpoint())
+
+	ts.addHead(12)
+	assert.Equal([]string{
+		fmt.Sprintf("0x00000000000000000000000000000000000000AA delegated 5000 to 12 in tx %s of block 10", txHash.Hex()),
+	}, ts.messages)
+	assert.Equal(uint64(1), ts.source.Metrics()[DelegateStream].Notified)
+	assert.Equal(uint64(10), ts.getCheckpoint())
+
+	// the next catch up resumes after the handled blocks, the event is not replayed
+	assert.Equal([][2]uint64{{11, 12}}, ts.catchUp(12, delegatedLog(10, txHash)))
+	ts.addHead(13)
+	ts.addHead(14)
+	assert.Equal(1, len(ts.messages))
+	assert.Equal(uint64(12), ts.getCheckpoint())
+}
+
+func (ts *EventSourceTestSuite) TestRestartReplaysPendingEvents() {
+	assert := ts.Assert()
+	assert.NoError(ts.checkpoint.Set(DelegateStream, 8))
+
+	log := delegatedLog(10, etherCommon.HexToHash("0x01"))
+	ts.catchUp(10, log)
+	ts.addHead(10)
+	ts.addHead(11)
+	assert.Equal(0, len(ts.messages))
+
+	// the bot restarts before the event is confirmed, the backfill starts from the checkpoint
+	ts.source = ts.newSource()
+	assert.Equal([][2]uint64{{10, 11}}, ts.catchUp(11, log))
+	ts.addHead(11)
+	ts.addHead(12)
+	assert.Equal(1, len(ts.messages))
+	assert.Equal(uint64(10), ts.getCheckpoint())
+}
+
+func (ts *EventSourceTestSuite) TestCatchUpWithoutCheckpoint() {
+	assert := ts.Assert()
+
+	assert.Equal(0, len(ts.catchUp(10)))
+	assert.Equal(uint64(10), ts.getCheckpoint())
+}
+
+func (ts *EventSourceTestSuite) TestCatchUpMaxBlocks() {
+	assert := ts.Assert()
+	assert.NoError(ts.checkpoint.Set(DelegateStream, 8))
+
+	ts.source.maxCatchUpBlocks = 5
+	assert.Equal([][2]uint64{{96, 100}}, ts.catchUp(100))
+}
+
+func (ts *EventSourceTestSuite) TestDoneAfterRender() {
+	assert := ts.Assert()
+	assert.NoError(ts.checkpoint.Set(DelegateStream, 8))
+
+	var calls []string
+	ts.eventType.Handle = func(ctx context.Context, event pkg.Event) {
+		calls = append(calls, "handle")
+	}
+	render := ts.eventType.Render
+	ts.eventType.Render = func(ctx context.Context, event pkg.Event) string {
+		calls = append(calls, "render")
+		return render(ctx, event)
+	}
+	ts.eventType.Done = func(ctx context.Context, event pkg.Event) {
+		calls = append(calls, "done")
+	}
+	ts.eventType.Amount = func(event pkg.Event) float64 {
+		return event.(pkg.SFCDelegateInfo).Amount
+	}
+	threshold := float64(1000)
+	ts.eventType.Threshold = func() float64 {
+		return threshold
+	}
+	ts.source = ts.newSource()
+
+	ts.catchUp(10, delegatedLog(10, etherCommon.HexToHash("0x01")))
+	ts.addHead(10)
+	ts.addHead(11)
+	ts.addHead(12)
+	assert.Equal([]string{"handle", "render", "done"}, calls)
+
+	// an event below the threshold is not rendered, it is still done
+	calls = nil
+	threshold = 10000
+	ts.catchUp(13, delegatedLog(13, etherCommon.HexToHash("0x02")))
+	ts.addHead(13)
+	ts.addHead(14)
+	ts.addHead(15)
+	assert.Equal([]string{"handle", "done"}, calls)
+}
+
+// addForkHeads replaces the blocks from fromBlock up to toBlock by the blocks of a fork.
+func (ts *EventSourceTestSuite) addForkHeads(fromBlock, toBlock uint64) {
+	forkHash := func(number uint64) etherCommon.Hash {
+		if number < fromBlock {
+			return blockHash(number)
+		}
+		return etherCommon.BigToHash(new(big.Int).SetUint64(0xf000 + number))
+	}
+	for number := fromBlock; number <= toBlock; number++ {
+		head := pkg.BlockHead{
+			Number:     number,
+			Hash:       forkHash(number).Hex(),
+			ParentHash: forkHash(number - 1).Hex(),
+		}
+		ts.reader.heads[head.Hash] = head
+	}
+	ts.source.confirmations.AddHead(context.Background(), ts.reader.heads[forkHash(toBlock).Hex()])
+}
+
+func (ts *EventSourceTestSuite) TestRetraction() {
+	assert := ts.Assert()
+	assert.NoError(ts.checkpoint.Set(DelegateStream, 8))
+
+	var retracted []uint64
+	ts.eventType.Retract = func(ctx context.Context, event pkg.Event) {
+		retracted = append(retracted, event.GetEventLog().BlockNumber)
+	}
+	ts.eventType.Amount = func(event pkg.Event) float64 {
+		return event.(pkg.SFCDelegateInfo).Amount
+	}
+	ts.eventType.Threshold = func() float64 {
+		return 1000
+	}
+	ts.source = ts.newSource()
+
+	large, small := delegatedLog(10, etherCommon.HexToHash("0x01")), delegatedLog(10, etherCommon.HexToHash("0x02"))
+	small.Data = etherCommon.LeftPadBytes(big.NewInt(1e18).Bytes(), 32)
+	small.Index = 4
+	ts.catchUp(10, large, small)
+	ts.addHead(10)
+	ts.addHead(11)
+	ts.addHead(12)
+	assert.Equal(1, len(ts.messages))
+
+	// both events are undone, only the announced one is retracted in the chat
+	ts.addForkHeads(10, 13)
+	assert.Equal([]uint64{10, 10}, retracted)
+	assert.Equal(2, len(ts.messages))
+	assert.Contains(ts.messages[1], "removed from the chain by a reorg")
+}
+
+func (ts *EventSourceTestSuite) TestRetractionToWatchlistChat() {
+	assert := ts.Assert()
+	assert.NoError(ts.checkpoint.Set(DelegateStream, 8))
+
+	watchlist := NewWatchlist(newMemoryStorage())
+	assert.NoError(watchlist.Add("0x00000000000000000000000000000000000000aa"))
+	var watchedMessages []string
+	ts.eventType.Addresses = func(event pkg.Event) []string {
+		return []string{event.(pkg.SFCDelegateInfo).Delegator}
+	}
+	ts.eventType.Amount = func(event pkg.Event) float64 {
+		return event.(pkg.SFCDelegateInfo).Amount
+	}
+	ts.eventType.Threshold = func() float64 {
+		return 10000
+	}
+	ts.source = ts.newSource()
+	ts.source.SetWatchlist(watchlist, func(msg string, priority notification.Priority, notified bool) error {
+		watchedMessages = append(watchedMessages, msg)
+		return nil
+	})
+
+	ts.catchUp(10, delegatedLog(10, etherCommon.HexToHash("0x01")))
+	ts.addHead(10)
+	ts.addHead(11)
+	ts.addHead(12)
+	assert.Equal(1, len(watchedMessages))
+
+	ts.addForkHeads(10, 13)
+	assert.Equal(0, len(ts.messages))
+	assert.Equal(2, len(watchedMessages))
+	assert.Contains(watchedMessages[1], "removed from the chain by a reorg")
+}
@@ -57,6 +57,10 @@ func (h *HttpHandler) Run() {
 	api := h.router.Group("/api")
 	api.POST("/addChatGroup", h.AddChatGroupApi)
 	api.GET("/removeChatGroup", h.DeleteChatGroupApi)
+	api.POST("/addWatchedAddress", h.AddWatchedAddressApi)
+	api.GET("/removeWatchedAddress", h.RemoveWatchedAddressApi)
+	api.GET("/watchlist", h.GetWatchlistApi)
+	api.GET("/withdrawalCalendar", h.GetWithdrawalCalendarApi)
 
 	if err := h.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		h.l.Panicw("run http server error", "error", err)
@@ -120,3 +124,73 @@ func (h *HttpHandler) DeleteChatGroupApi(c *gin.Context) {
 		},
 	)
 }
+
+func (h *HttpHandler) AddWatchedAddressApi(c *gin.Context) {
+	var req struct {
+		Address string `json:"address"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			ErrResponse{
+				Error: err.Error(),
+			},
+		)
+		return
+	}
+
+	if err := h.core.AddWatchedAddress(req.Address); err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			ErrResponse{
+				Error: err.Error(),
+			},
+		)
+		return
+	}
+	c.JSON(
+		http.StatusOK,
+		ErrResponse{
+			Success: true,
+		},
+	)
+}
+
+func (h *HttpHandler) RemoveWatchedAddressApi(c *gin.Context) {
+	if err := h.core.RemoveWatchedAddress(c.Query("address")); err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			ErrResponse{
+				Error: err.Error(),
+			},
+		)
+		return
+	}
+	c.JSON(
+		http.StatusOK,
+		ErrResponse{
+			Success: true,
+		},
+	)
+}
+
+func (h *HttpHandler) GetWatchlistApi(c *gin.Context) {
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"addresses": h.core.GetWatchlist(),
+			"success":   true,
+		},
+	)
+}
+
+func (h *HttpHandler) GetWithdrawalCalendarApi(c *gin.Context) {
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"withdrawals": h.core.GetWithdrawalCalendar(),
+			"success":     true,
+		},
+	)
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	LockupReminderDaysFlag     = "lockup_reminder.days_before"
+	LockupReminderIntervalFlag = "lockup_reminder.check_interval"
+
+	DefaultLockupReminderDays     = 7
+	DefaultLockupReminderInterval = 10 * time.Minute
+
+	LockupsStorageKey = "lockups"
+)
+
+type LockupReminderKind int
+
+const (
+	LockupExpiringSoon LockupReminderKind = iota
+	LockupExpired
+)
+
+// Lockup is a tracked lockup with the reminders already sent.
+type Lockup struct {
+	pkg.LockupInfo
+	RemindedBefore bool
+	RemindedExpiry bool
+}
+
+func delegationKey(delegator string, validatorID uint64) string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(delegator), validatorID)
+}
+
+// LockupScheduler records the lockups of the watched addresses and sends reminders
+// before and when they end, the lockups and reminders are persisted.
+type LockupScheduler struct {
+	l               *zap.SugaredLogger
+	sfcClient       *SFCClient
+	storage         storage.KeyValueStorage
+	watchlist       *Watchlist
+	validatorKeeper *keeper.ValidatorsKeeper
+	notify          func(ctx context.Context, lockup Lockup, kind LockupReminderKind)
+	remindBefore    time.Duration
+	interval        time.Duration
+
+	// scanned are the watched addresses whose lockups have been read, the addresses added later are scanned at the next check
+	scanned map[string]bool
+	lockups map[string]Lockup
+	mu      sync.Mutex
+}
+
+func NewLockupScheduler(sfcClient *SFCClient, keyValueStorage storage.KeyValueStorage, watchlist *Watchlist, validatorKeeper *keeper.ValidatorsKeeper, notify func(ctx context.Context, lockup Lockup, kind LockupReminderKind)) *LockupScheduler {
+	l := zap.S()
+
+	days := DefaultLockupReminderDays
+	if viper.IsSet(LockupReminderDaysFlag) {
+		days = viper.GetInt(LockupReminderDaysFlag)
+	}
+	interval := DefaultLockupReminderInterval
+	if viper.IsSet(LockupReminderIntervalFlag) {
+		interval = viper.GetDuration(LockupReminderIntervalFlag)
+	}
+
+	var lockups = make(map[string]Lockup)
+	if err := keyValueStorage.Get(LockupsStorageKey, &lockups); err != nil {
+		l.Debugw("no lockups found in storage", "error", err)
+		lockups = make(map[string]Lockup)
+	}
+
+	return &LockupScheduler{
+		l:               l,
+		sfcClient:       sfcClient,
+		storage:         keyValueStorage,
+		watchlist:       watchlist,
+		validatorKeeper: validatorKeeper,
+		notify:          notify,
+		remindBefore:    time.Duration(days) * 24 * time.Hour,
+		interval:        interval,
+		scanned:         make(map[string]bool),
+		lockups:         lockups,
+		mu:              sync.Mutex{},
+	}
+}
+
+func (s *LockupScheduler) IsTracked(address string) bool {
+	return s.watchlist.Contains(address)
+}
+
+// Run loads the lockups of the watched addresses and sends the reminders until ctx is done.
+func (s *LockupScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.Scan(ctx)
+		s.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan reads the lockups of the watched addresses not scanned yet to every known validator from the SFC contract.
+func (s *LockupScheduler) Scan(ctx context.Context) {
+	for _, address := range s.watchlist.List() {
+		s.mu.Lock()
+		scanned := s.scanned[address]
+		s.mu.Unlock()
+		if scanned {
+			continue
+		}
+
+		for id := range s.validatorKeeper.GetListValidators() {
+			if ctx.Err() != nil {
+				return
+			}
+			s.Refresh(ctx, address, id)
+		}
+		s.mu.Lock()
+		s.scanned[address] = true
+		s.mu.Unlock()
+	}
+}
+
+// Refresh reads the lockup of the delegation from the SFC contract, it is called after every lockup change.
+func (s *LockupScheduler) Refresh(ctx context.Context, delegator string, validatorID uint64) {
+	info, err := s.sfcClient.GetLockupInfo(ctx, delegator, validatorID)
+	if err != nil {
+		return
+	}
+	s.record(info, time.Now())
+}
+
+func (s *LockupScheduler) record(info pkg.LockupInfo, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := delegationKey(info.Delegator, info.ValidatorID)
+	current, ok := s.lockups[key]
+	switch {
+	case info.LockedStake == 0 || info.EndTime == 0:
+		if !ok {
+			return
+		}
+		delete(s.lockups, key)
+	case ok && current.EndTime == info.EndTime:
+		current.LockupInfo = info
+		s.lockups[key] = current
+	default:
+		// a lockup already ended when it is found is not reminded
+		ended := int64(info.EndTime) <= now.Unix()
+		s.lockups[key] = Lockup{
+			LockupInfo:     info,
+			RemindedBefore: ended,
+			RemindedExpiry: ended,
+		}
+		s.l.Infow("track lockup", "delegator", info.Delegator, "validator_id", info.ValidatorID, "end_time", info.EndTime)
+	}
+	s.saveLocked()
+}
+
+func (s *LockupScheduler) check(ctx context.Context, now time.Time) {
+	type reminder struct {
+		lockup Lockup
+		kind   LockupReminderKind
+	}
+	var reminders = make([]reminder, 0)
+
+	s.mu.Lock()
+	for key, lockup := range s.lockups {
+		endTime := time.Unix(int64(lockup.EndTime), 0)
+		switch {
+		case !lockup.RemindedExpiry && !now.Before(endTime):
+			lockup.RemindedBefore, lockup.RemindedExpiry = true, true
+			reminders = append(reminders, reminder{lockup: lockup, kind: LockupExpired})
+		case !lockup.RemindedBefore && !now.Before(endTime.Add(-s.remindBefore)):
+			lockup.RemindedBefore = true
+			reminders = append(reminders, reminder{lockup: lockup, kind: LockupExpiringSoon})
+		default:
+			continue
+		}
+		s.lockups[key] = lockup
+	}
+	if len(reminders) > 0 {
+		s.saveLocked()
+	}
+	s.mu.Unlock()
+
+	for _, r := range reminders {
+		s.notify(ctx, r.lockup, r.kind)
+	}
+}
+
+func (s *LockupScheduler) saveLocked() {
+	if err := s.storage.Set(LockupsStorageKey, s.lockups); err != nil {
+		s.l.Warnw("save lockups error", "error", err)
+	}
+}
+
+func (s *LockupScheduler) GetListLockups() []Lockup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result = make([]Lockup, 0, len(s.lockups))
+	for _, lockup := range s.lockups {
+		result = append(result, lockup)
+	}
+	return result
+}
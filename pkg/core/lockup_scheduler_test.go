@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type LockupSchedulerTestSuite struct {
+	suite.Suite
+	scheduler *LockupScheduler
+	reminders []LockupReminderKind
+}
+
+func TestLockupSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(LockupSchedulerTestSuite))
+}
+
+func (ts *LockupSchedulerTestSuite) SetupTest() {
+	ts.reminders = nil
+	ts.scheduler = &LockupScheduler{
+		l:       zap.S(),
+		storage: newMemoryStorage(),
+		notify: func(ctx context.Context, lockup Lockup, kind LockupReminderKind) {
+			ts.reminders = append(ts.reminders, kind)
+		},
+		remindBefore: 7 * 24 * time.Hour,
+		lockups:      make(map[string]Lockup),
+	}
+}
+
+func (ts *LockupSchedulerTestSuite) TestReminders() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	now := time.Unix(1000000000, 0)
+	endTime := now.Add(30 * 24 * time.Hour)
+	ts.scheduler.record(pkg.LockupInfo{Delegator: "0x1", ValidatorID: 1, LockedStake: 100, EndTime: uint64(endTime.Unix())}, now)
+
+	ts.scheduler.check(ctx, now)
+	assert.Equal(0, len(ts.reminders))
+
+	ts.scheduler.check(ctx, endTime.Add(-6*24*time.Hour))
+	assert.Equal([]LockupReminderKind{LockupExpiringSoon}, ts.reminders)
+
+	ts.scheduler.check(ctx, endTime.Add(-time.Hour))
+	assert.Equal(1, len(ts.reminders))
+
+	ts.scheduler.check(ctx, endTime)
+	assert.Equal([]LockupReminderKind{LockupExpiringSoon, LockupExpired}, ts.reminders)
+
+	ts.scheduler.check(ctx, endTime.Add(time.Hour))
+	assert.Equal(2, len(ts.reminders))
+}
+
+func (ts *LockupSchedulerTestSuite) TestRelockResetsReminders() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	now := time.Unix(1000000000, 0)
+	endTime := now.Add(time.Hour)
+	ts.scheduler.record(pkg.LockupInfo{Delegator: "0x1", ValidatorID: 1, LockedStake: 100, EndTime: uint64(endTime.Unix())}, now)
+	ts.scheduler.check(ctx, now)
+	assert.Equal([]LockupReminderKind{LockupExpiringSoon}, ts.reminders)
+
+	newEndTime := now.Add(60 * 24 * time.Hour)
+	ts.scheduler.record(pkg.LockupInfo{Delegator: "0x1", ValidatorID: 1, LockedStake: 100, EndTime: uint64(newEndTime.Unix())}, now)
+	ts.scheduler.check(ctx, endTime)
+	assert.Equal(1, len(ts.reminders))
+}
+
+func (ts *LockupSchedulerTestSuite) TestIgnoreEndedAndUnlocked() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	now := time.Unix(1000000000, 0)
+	ts.scheduler.record(pkg.LockupInfo{Delegator: "0x1", ValidatorID: 1, LockedStake: 100, EndTime: uint64(now.Add(-time.Hour).Unix())}, now)
+	ts.scheduler.check(ctx, now)
+	assert.Equal(0, len(ts.reminders))
+
+	ts.scheduler.record(pkg.LockupInfo{Delegator: "0x1", ValidatorID: 1}, now)
+	assert.Equal(0, len(ts.scheduler.GetListLockups()))
+}
+
+func (ts *LockupSchedulerTestSuite) TestScanWatchedAddresses() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	backend := &fakeContractBackend{outputs: make(map[string][]byte)}
+	// locked stake, from epoch, end time and duration
+	backend.setBigOutput("getLockupInfo(address,uint256)", ftm(100), big.NewInt(10), big.NewInt(2000000000), big.NewInt(86400))
+	sfcClient, err := newFakeSFCClient(backend)
+	assert.NoError(err)
+	watchlist := NewWatchlist(newMemoryStorage())
+	assert.NoError(watchlist.Add("0x00000000000000000000000000000000000000aa"))
+	ts.scheduler.sfcClient = sfcClient
+	ts.scheduler.watchlist = watchlist
+	ts.scheduler.validatorKeeper = keeper.NewValidatorsKeeper()
+	ts.scheduler.validatorKeeper.Add(pkg.SFCValidator{ID: 12})
+	ts.scheduler.scanned = make(map[string]bool)
+
+	ts.scheduler.Scan(ctx)
+	assert.Equal(1, len(backend.blocks))
+	assert.Equal(1, len(ts.scheduler.GetListLockups()))
+	assert.True(ts.scheduler.IsTracked("0x00000000000000000000000000000000000000AA"))
+
+	// only the addresses added to the watchlist since the last scan are read
+	ts.scheduler.Scan(ctx)
+	assert.Equal(1, len(backend.blocks))
+	assert.NoError(watchlist.Add("0x00000000000000000000000000000000000000bb"))
+	ts.scheduler.Scan(ctx)
+	assert.Equal(2, len(backend.blocks))
+	assert.Equal(2, len(ts.scheduler.GetListLockups()))
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+)
+
+// memoryStorage is an in-memory KeyValueStorage encoding the values like the badger storage does.
+type memoryStorage struct {
+	values map[string][]byte
+	mu     sync.Mutex
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		values: make(map[string][]byte),
+		mu:     sync.Mutex{},
+	}
+}
+
+func (s *memoryStorage) Set(key string, value interface{}) error {
+	data, err := storage.Encode(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = data
+	return nil
+}
+
+func (s *memoryStorage) Get(key string, value interface{}) error {
+	s.mu.Lock()
+	data, ok := s.values[key]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("key %s not found", key)
+	}
+	return storage.Decode(data, value)
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	MempoolEnabledFlag           = "mempool.enabled"
+	MempoolMinTransferAmountFlag = "mempool.min_transfer_amount"
+	MempoolSFCMethodsFlag        = "mempool.sfc_methods"
+	MempoolCheckIntervalFlag     = "mempool.check_interval"
+	MempoolTimeoutFlag           = "mempool.timeout"
+	MempoolWorkersFlag           = "mempool.workers"
+
+	DefaultMempoolCheckInterval = 5 * time.Second
+	DefaultMempoolTimeout       = 10 * time.Minute
+	DefaultMempoolWorkers       = 8
+
+	// MempoolQueueSize is the number of pending hashes waiting for a worker, the newer ones are skipped when it is full
+	MempoolQueueSize = 1024
+)
+
+// DefaultMempoolSFCMethods are the SFC calls announced while pending.
+var DefaultMempoolSFCMethods = []string{"undelegate", "unlockStake"}
+
+type PendingTxKind int
+
+const (
+	PendingTransfer PendingTxKind = iota
+	PendingSFCCall
+)
+
+type PendingTxStatus int
+
+const (
+	PendingTxSeen PendingTxStatus = iota
+	PendingTxMined
+	PendingTxReplaced
+	PendingTxDropped
+)
+
+type TrackedPendingTx struct {
+	pkg.PendingTx
+	Kind PendingTxKind
+}
+
+// PendingTxUpdate is sent when a tracked transaction is seen and when it leaves the mempool,
+// BlockNumber and Success are set once it is mined.
+type PendingTxUpdate struct {
+	Tx          TrackedPendingTx
+	Status      PendingTxStatus
+	BlockNumber uint64
+	Success     bool
+}
+
+// ClassifyPendingTx returns whether the pending transaction is announced: a transfer above the amount
+// or a call of one of the SFC methods.
+func ClassifyPendingTx(tx pkg.PendingTx, sfcAddress string, minTransferAmount float64, sfcMethods map[string]bool) (PendingTxKind, bool) {
+	if strings.EqualFold(tx.To, sfcAddress) {
+		return PendingSFCCall, sfcMethods[tx.Method]
+	}
+	return PendingTransfer, tx.To != "" && tx.Value > minTransferAmount
+}
+
+// PendingTxStatusReader reads the state of the announced transactions.
+type PendingTxStatusReader interface {
+	GetTxStatus(ctx context.Context, txHash string) (uint64, bool, bool, error)
+	GetNonce(ctx context.Context, address string) (uint64, error)
+	IsTxKnown(ctx context.Context, txHash string) (bool, error)
+}
+
+// MempoolWatcher announces the large transfers and the SFC calls waiting in the mempool of the ws node,
+// then follows them until they are mined, replaced or dropped.
+type MempoolWatcher struct {
+	l                 *zap.SugaredLogger
+	sfcClient         *SFCClient
+	reader            PendingTxStatusReader
+	notify            func(ctx context.Context, update PendingTxUpdate)
+	enabled           bool
+	minTransferAmount float64
+	sfcMethods        map[string]bool
+	checkInterval     time.Duration
+	timeout           time.Duration
+	workers           int
+
+	pending map[string]TrackedPendingTx
+	mu      sync.Mutex
+}
+
+func NewMempoolWatcher(sfcClient *SFCClient, minTransferAmount float64, notify func(ctx context.Context, update PendingTxUpdate)) *MempoolWatcher {
+	if viper.IsSet(MempoolMinTransferAmountFlag) {
+		minTransferAmount = viper.GetFloat64(MempoolMinTransferAmountFlag)
+	}
+	methods := DefaultMempoolSFCMethods
+	if viper.IsSet(MempoolSFCMethodsFlag) {
+		methods = viper.GetStringSlice(MempoolSFCMethodsFlag)
+	}
+	var sfcMethods = make(map[string]bool)
+	for _, method := range methods {
+		sfcMethods[method] = true
+	}
+	checkInterval := DefaultMempoolCheckInterval
+	if viper.IsSet(MempoolCheckIntervalFlag) {
+		checkInterval = viper.GetDuration(MempoolCheckIntervalFlag)
+	}
+	timeout := DefaultMempoolTimeout
+	if viper.IsSet(MempoolTimeoutFlag) {
+		timeout = viper.GetDuration(MempoolTimeoutFlag)
+	}
+	workers := DefaultMempoolWorkers
+	if viper.IsSet(MempoolWorkersFlag) {
+		workers = viper.GetInt(MempoolWorkersFlag)
+	}
+
+	return &MempoolWatcher{
+		l:                 zap.S(),
+		sfcClient:         sfcClient,
+		reader:            sfcClient,
+		notify:            notify,
+		enabled:           viper.GetBool(MempoolEnabledFlag),
+		minTransferAmount: minTransferAmount,
+		sfcMethods:        sfcMethods,
+		checkInterval:     checkInterval,
+		timeout:           timeout,
+		workers:           workers,
+		pending:           make(map[string]TrackedPendingTx),
+		mu:                sync.Mutex{},
+	}
+}
+
+// Run watches the mempool until ctx is done, it returns at once when the watcher is disabled
+// or when there is no ws endpoint.
+func (w *MempoolWatcher) Run(ctx context.Context) {
+	if !w.enabled {
+		return
+	}
+	if !w.sfcClient.HasWs() {
+		w.l.Warn("mempool watcher needs a ws endpoint, skip")
+		return
+	}
+
+	var (
+		hashCh  = make(chan string)
+		errCh   = make(chan error)
+		queue   = make(chan string, MempoolQueueSize)
+		backoff = MinResubscribeBackoff
+	)
+	for i := 0; i < w.workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case hash := <-queue:
+					w.handleHash(ctx, hash, time.Now())
+				}
+			}
+		}()
+	}
+
+	w.l.Info("watch mempool")
+	go w.sfcClient.SubscribePendingTransactions(ctx, hashCh, errCh)
+	ticker := time.NewTicker(w.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-errCh:
+			w.l.Warnw("reset pending transactions subscription", "error", err, "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > MaxResubscribeBackoff {
+				backoff = MaxResubscribeBackoff
+			}
+			go w.sfcClient.SubscribePendingTransactions(ctx, hashCh, errCh)
+		case hash := <-hashCh:
+			backoff = MinResubscribeBackoff
+			select {
+			case queue <- hash:
+			default:
+				w.l.Debugw("mempool queue full, skip pending tx", "tx_hash", hash)
+			}
+		case <-ticker.C:
+			w.check(ctx, time.Now())
+		}
+	}
+}
+
+func (w *MempoolWatcher) handleHash(ctx context.Context, hash string, now time.Time) {
+	w.mu.Lock()
+	_, ok := w.pending[hash]
+	w.mu.Unlock()
+	if ok {
+		return
+	}
+
+	tx, isPending, err := w.sfcClient.GetPendingTx(ctx, hash)
+	if err != nil || !isPending {
+		return
+	}
+	kind, announced := ClassifyPendingTx(tx, w.sfcClient.sfcAddress.Hex(), w.minTransferAmount, w.sfcMethods)
+	if !announced {
+		return
+	}
+	tx.SeenTime = uint64(now.Unix())
+	tracked := TrackedPendingTx{PendingTx: tx, Kind: kind}
+
+	w.mu.Lock()
+	if _, ok := w.pending[hash]; ok {
+		w.mu.Unlock()
+		return
+	}
+	w.pending[hash] = tracked
+	w.mu.Unlock()
+
+	w.l.Debugw("new pending tx", "tx_hash", hash, "kind", kind)
+	w.notify(ctx, PendingTxUpdate{Tx: tracked, Status: PendingTxSeen})
+}
+
+// check follows up the tracked transactions: a transaction is replaced when the nonce of its sender
+// has moved past it without it being mined, and dropped when the node forgets it after the timeout.
+func (w *MempoolWatcher) check(ctx context.Context, now time.Time) {
+	w.mu.Lock()
+	var list = make([]TrackedPendingTx, 0, len(w.pending))
+	for _, tx := range w.pending {
+		list = append(list, tx)
+	}
+	w.mu.Unlock()
+
+	for _, tx := range list {
+		update, done := w.checkTx(ctx, tx, now)
+		if !done {
+			continue
+		}
+		w.mu.Lock()
+		delete(w.pending, tx.Hash)
+		w.mu.Unlock()
+		w.notify(ctx, update)
+	}
+}
+
+func (w *MempoolWatcher) checkTx(ctx context.Context, tx TrackedPendingTx, now time.Time) (PendingTxUpdate, bool) {
+	blockNumber, success, mined, err := w.reader.GetTxStatus(ctx, tx.Hash)
+	if err != nil {
+		return PendingTxUpdate{}, false
+	}
+	if mined {
+		return PendingTxUpdate{Tx: tx, Status: PendingTxMined, BlockNumber: blockNumber, Success: success}, true
+	}
+
+	nonce, err := w.reader.GetNonce(ctx, tx.From)
+	if err != nil {
+		return PendingTxUpdate{}, false
+	}
+	if nonce > tx.Nonce {
+		// the transaction may have been mined since its receipt was looked up
+		blockNumber, success, mined, err := w.reader.GetTxStatus(ctx, tx.Hash)
+		if err != nil {
+			return PendingTxUpdate{}, false
+		}
+		if mined {
+			return PendingTxUpdate{Tx: tx, Status: PendingTxMined, BlockNumber: blockNumber, Success: success}, true
+		}
+		return PendingTxUpdate{Tx: tx, Status: PendingTxReplaced}, true
+	}
+
+	if now.Sub(time.Unix(int64(tx.SeenTime), 0)) < w.timeout {
+		return PendingTxUpdate{}, false
+	}
+	known, err := w.reader.IsTxKnown(ctx, tx.Hash)
+	if err != nil || known {
+		return PendingTxUpdate{}, false
+	}
+	return PendingTxUpdate{Tx: tx, Status: PendingTxDropped}, true
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type fakePendingTxStatusReader struct {
+	// receipts are the blocks of the mined transactions
+	receipts map[string]uint64
+	nonces   map[string]uint64
+	known    map[string]bool
+	// onGetNonce is called on every nonce read. Optional.
+	onGetNonce func(address string)
+}
+
+func (r *fakePendingTxStatusReader) GetTxStatus(ctx context.Context, txHash string) (uint64, bool, bool, error) {
+	blockNumber, mined := r.receipts[txHash]
+	return blockNumber, mined, mined, nil
+}
+
+func (r *fakePendingTxStatusReader) GetNonce(ctx context.Context, address string) (uint64, error) {
+	if r.onGetNonce != nil {
+		r.onGetNonce(address)
+	}
+	return r.nonces[strings.ToLower(address)], nil
+}
+
+func (r *fakePendingTxStatusReader) IsTxKnown(ctx context.Context, txHash string) (bool, error) {
+	return r.known[txHash], nil
+}
+
+type MempoolWatcherTestSuite struct {
+	suite.Suite
+	reader  *fakePendingTxStatusReader
+	watcher *MempoolWatcher
+	updates []PendingTxUpdate
+	now     time.Time
+}
+
+func TestMempoolWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(MempoolWatcherTestSuite))
+}
+
+func (ts *MempoolWatcherTestSuite) SetupTest() {
+	ts.updates = nil
+	ts.now = time.Unix(1000000000, 0)
+	ts.reader = &fakePendingTxStatusReader{
+		receipts: make(map[string]uint64),
+		nonces:   make(map[string]uint64),
+		known:    make(map[string]bool),
+	}
+	ts.watcher = &MempoolWatcher{
+		l:      zap.S(),
+		reader: ts.reader,
+		notify: func(ctx context.Context, update PendingTxUpdate) {
+			ts.updates = append(ts.updates, update)
+		},
+		timeout: 10 * time.Minute,
+		pending: make(map[string]TrackedPendingTx),
+	}
+}
+
+// track adds a pending transaction of 0xaa with the nonce 5 seen now.
+func (ts *MempoolWatcherTestSuite) track(hash string) {
+	ts.watcher.pending[hash] = TrackedPendingTx{
+		PendingTx: pkg.PendingTx{Hash: hash, From: "0x00000000000000000000000000000000000000AA", Nonce: 5, SeenTime: uint64(ts.now.Unix())},
+	}
+	ts.reader.nonces["0x00000000000000000000000000000000000000aa"] = 5
+	ts.reader.known[hash] = true
+}
+
+func (ts *MempoolWatcherTestSuite) TestClassifyPendingTx() {
+	assert := ts.Assert()
+	sfcAddress := "0xFC00FACE00000000000000000000000000000000"
+	sfcMethods := map[string]bool{"undelegate": true}
+
+	kind, announced := ClassifyPendingTx(pkg.PendingTx{To: "0x1", Value: 1000}, sfcAddress, 500, sfcMethods)
+	assert.True(announced)
+	assert.Equal(PendingTransfer, kind)
+
+	_, announced = ClassifyPendingTx(pkg.PendingTx{To: "0x1", Value: 100}, sfcAddress, 500, sfcMethods)
+	assert.False(announced)
+
+	_, announced = ClassifyPendingTx(pkg.PendingTx{Value: 1000}, sfcAddress, 500, sfcMethods)
+	assert.False(announced)
+
+	kind, announced = ClassifyPendingTx(pkg.PendingTx{
+		To:          "0xfc00face00000000000000000000000000000000",
+		DecodedCall: pkg.DecodedCall{Method: "undelegate"},
+	}, sfcAddress, 500, sfcMethods)
+	assert.True(announced)
+	assert.Equal(PendingSFCCall, kind)
+
+	_, announced = ClassifyPendingTx(pkg.PendingTx{
+		To:          "0xfc00face00000000000000000000000000000000",
+		Value:       1000,
+		DecodedCall: pkg.DecodedCall{Method: "delegate"},
+	}, sfcAddress, 500, sfcMethods)
+	assert.False(announced)
+}
+
+func (ts *MempoolWatcherTestSuite) TestCheckMined() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	ts.track("0x1")
+	ts.watcher.check(ctx, ts.now)
+	assert.Equal(0, len(ts.updates))
+
+	ts.reader.receipts["0x1"] = 100
+	ts.watcher.check(ctx, ts.now)
+	assert.Equal(1, len(ts.updates))
+	assert.Equal(PendingTxMined, ts.updates[0].Status)
+	assert.Equal(uint64(100), ts.updates[0].BlockNumber)
+	assert.True(ts.updates[0].Success)
+	assert.Equal(0, len(ts.watcher.pending))
+
+	// a transaction left the mempool is not followed anymore
+	ts.watcher.check(ctx, ts.now)
+	assert.Equal(1, len(ts.updates))
+}
+
+func (ts *MempoolWatcherTestSuite) TestCheckReplaced() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	ts.track("0x1")
+	ts.reader.nonces["0x00000000000000000000000000000000000000aa"] = 6
+	ts.watcher.check(ctx, ts.now)
+	assert.Equal(1, len(ts.updates))
+	assert.Equal(PendingTxReplaced, ts.updates[0].Status)
+	assert.Equal(0, len(ts.watcher.pending))
+
+	// the transaction mined while the nonce is read is not reported as replaced
+	ts.track("0x2")
+	ts.reader.onGetNonce = func(address string) {
+		ts.reader.nonces[strings.ToLower(address)] = 6
+		ts.reader.receipts["0x2"] = 101
+	}
+	ts.watcher.check(ctx, ts.now)
+	assert.Equal(2, len(ts.updates))
+	assert.Equal(PendingTxMined, ts.updates[1].Status)
+	assert.Equal(uint64(101), ts.updates[1].BlockNumber)
+}
+
+func (ts *MempoolWatcherTestSuite) TestCheckDropped() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	ts.track("0x1")
+	ts.reader.known["0x1"] = false
+	ts.watcher.check(ctx, ts.now.Add(time.Minute))
+	assert.Equal(0, len(ts.updates))
+
+	// a transaction still known by the node is kept after the timeout
+	ts.reader.known["0x1"] = true
+	ts.watcher.check(ctx, ts.now.Add(10*time.Minute))
+	assert.Equal(0, len(ts.updates))
+
+	ts.reader.known["0x1"] = false
+	ts.watcher.check(ctx, ts.now.Add(10*time.Minute))
+	assert.Equal(1, len(ts.updates))
+	assert.Equal(PendingTxDropped, ts.updates[0].Status)
+	assert.Equal(0, len(ts.watcher.pending))
+}
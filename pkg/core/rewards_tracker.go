@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	PendingRewardsNotifyAmountFlag = "pending_rewards.notify_amount"
+	PendingRewardsHistorySizeFlag  = "pending_rewards.history_size"
+
+	DefaultPendingRewardsNotifyAmount = 100
+	DefaultPendingRewardsHistorySize  = 100
+
+	PendingRewardsStorageKey = "pending_rewards"
+)
+
+// DelegationRewards is the pending rewards history of a delegation of a watched address,
+// Notified is set once the owner has been told the rewards are worth claiming.
+type DelegationRewards struct {
+	Delegator   string
+	ValidatorID uint64
+	History     []pkg.PendingRewards
+	Notified    bool
+}
+
+// Last returns the latest pending rewards of the delegation.
+func (r DelegationRewards) Last() (pkg.PendingRewards, bool) {
+	if len(r.History) == 0 {
+		return pkg.PendingRewards{}, false
+	}
+	return r.History[len(r.History)-1], true
+}
+
+// PendingRewardsTracker polls the pending rewards of the delegations of the watched addresses
+// every sealed epoch and notifies once they exceed the configured amount, the history is persisted.
+type PendingRewardsTracker struct {
+	l               *zap.SugaredLogger
+	sfcClient       *SFCClient
+	storage         storage.KeyValueStorage
+	watchlist       *Watchlist
+	validatorKeeper *keeper.ValidatorsKeeper
+	notify          func(ctx context.Context, rewards DelegationRewards)
+	notifyAmount    float64
+	historySize     int
+
+	// scanned are the addresses whose delegations have been looked up in the SFC contract
+	scanned     map[string]bool
+	delegations map[string]DelegationRewards
+	mu          sync.Mutex
+}
+
+func NewPendingRewardsTracker(sfcClient *SFCClient, keyValueStorage storage.KeyValueStorage, watchlist *Watchlist, validatorKeeper *keeper.ValidatorsKeeper, notify func(ctx context.Context, rewards DelegationRewards)) *PendingRewardsTracker {
+	l := zap.S()
+
+	notifyAmount := float64(DefaultPendingRewardsNotifyAmount)
+	if viper.IsSet(PendingRewardsNotifyAmountFlag) {
+		notifyAmount = viper.GetFloat64(PendingRewardsNotifyAmountFlag)
+	}
+	historySize := DefaultPendingRewardsHistorySize
+	if viper.IsSet(PendingRewardsHistorySizeFlag) {
+		historySize = viper.GetInt(PendingRewardsHistorySizeFlag)
+	}
+
+	var delegations = make(map[string]DelegationRewards)
+	if err := keyValueStorage.Get(PendingRewardsStorageKey, &delegations); err != nil {
+		l.Debugw("no pending rewards found in storage", "error", err)
+		delegations = make(map[string]DelegationRewards)
+	}
+
+	return &PendingRewardsTracker{
+		l:               l,
+		sfcClient:       sfcClient,
+		storage:         keyValueStorage,
+		watchlist:       watchlist,
+		validatorKeeper: validatorKeeper,
+		notify:          notify,
+		notifyAmount:    notifyAmount,
+		historySize:     historySize,
+		scanned:         make(map[string]bool),
+		delegations:     delegations,
+		mu:              sync.Mutex{},
+	}
+}
+
+// AddDelegation starts tracking the delegation, it is called for every delegation of a watched address.
+func (t *PendingRewardsTracker) AddDelegation(delegator string, validatorID uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := delegationKey(delegator, validatorID)
+	if _, ok := t.delegations[key]; ok {
+		return
+	}
+	t.delegations[key] = DelegationRewards{
+		Delegator:   strings.ToLower(delegator),
+		ValidatorID: validatorID,
+	}
+	t.l.Infow("track pending rewards", "delegator", delegator, "validator_id", validatorID)
+}
+
+// HandleEpoch reads the pending rewards of every tracked delegation once the epoch is sealed.
+func (t *PendingRewardsTracker) HandleEpoch(ctx context.Context, snapshot pkg.EpochSnapshot) {
+	watched := t.watchlist.List()
+	for _, address := range watched {
+		t.scan(ctx, address)
+	}
+	t.forgetUnwatched(watched)
+
+	t.mu.Lock()
+	var delegations = make([]DelegationRewards, 0, len(t.delegations))
+	for _, delegation := range t.delegations {
+		delegations = append(delegations, delegation)
+	}
+	t.mu.Unlock()
+
+	for _, delegation := range delegations {
+		if ctx.Err() != nil {
+			return
+		}
+		rewards, err := t.sfcClient.GetPendingRewards(ctx, delegation.Delegator, delegation.ValidatorID)
+		if err != nil {
+			continue
+		}
+		rewards.Epoch = snapshot.Epoch
+		if delegation, ok := t.record(delegation.Delegator, delegation.ValidatorID, rewards); ok {
+			t.notify(ctx, delegation)
+		}
+	}
+
+	t.mu.Lock()
+	t.saveLocked()
+	t.mu.Unlock()
+}
+
+// scan looks up the delegations of a newly watched address to every known validator.
+func (t *PendingRewardsTracker) scan(ctx context.Context, address string) {
+	t.mu.Lock()
+	scanned := t.scanned[address]
+	t.mu.Unlock()
+	if scanned {
+		return
+	}
+
+	for id := range t.validatorKeeper.GetListValidators() {
+		if ctx.Err() != nil {
+			return
+		}
+		stake, err := t.sfcClient.GetStake(ctx, address, id)
+		if err != nil {
+			return
+		}
+		if stake > 0 {
+			t.AddDelegation(address, id)
+		}
+	}
+
+	t.mu.Lock()
+	t.scanned[address] = true
+	t.mu.Unlock()
+}
+
+func (t *PendingRewardsTracker) forgetUnwatched(watched []string) {
+	var watchedSet = make(map[string]bool)
+	for _, address := range watched {
+		watchedSet[address] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, delegation := range t.delegations {
+		if !watchedSet[delegation.Delegator] {
+			delete(t.delegations, key)
+		}
+	}
+	for address := range t.scanned {
+		if !watchedSet[address] {
+			delete(t.scanned, address)
+		}
+	}
+}
+
+// record appends the rewards to the history of the delegation and returns it when the rewards
+// have just exceeded the notify amount. The notification is armed again once they fall back
+// below the amount, after a claim or a restake.
+func (t *PendingRewardsTracker) record(delegator string, validatorID uint64, rewards pkg.PendingRewards) (DelegationRewards, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := delegationKey(delegator, validatorID)
+	delegation, ok := t.delegations[key]
+	if !ok {
+		return DelegationRewards{}, false
+	}
+	delegation.History = append(delegation.History, rewards)
+	if t.historySize > 0 && len(delegation.History) > t.historySize {
+		delegation.History = delegation.History[len(delegation.History)-t.historySize:]
+	}
+
+	notify := false
+	switch {
+	case rewards.Pending < t.notifyAmount:
+		delegation.Notified = false
+	case !delegation.Notified:
+		delegation.Notified = true
+		notify = true
+	}
+	t.delegations[key] = delegation
+	return delegation, notify
+}
+
+func (t *PendingRewardsTracker) saveLocked() {
+	if err := t.storage.Set(PendingRewardsStorageKey, t.delegations); err != nil {
+		t.l.Warnw("save pending rewards error", "error", err)
+	}
+}
+
+// GetHistory returns the pending rewards history of the delegations of the address.
+func (t *PendingRewardsTracker) GetHistory(delegator string) []DelegationRewards {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delegator = strings.ToLower(delegator)
+	var result = make([]DelegationRewards, 0)
+	for _, delegation := range t.delegations {
+		if delegation.Delegator == delegator {
+			result = append(result, delegation)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ValidatorID < result[j].ValidatorID
+	})
+	return result
+}
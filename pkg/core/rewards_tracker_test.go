@@ -0,0 +1,79 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type PendingRewardsTrackerTestSuite struct {
+	suite.Suite
+	tracker *PendingRewardsTracker
+}
+
+func TestPendingRewardsTrackerTestSuite(t *testing.T) {
+	suite.Run(t, new(PendingRewardsTrackerTestSuite))
+}
+
+func (ts *PendingRewardsTrackerTestSuite) SetupTest() {
+	ts.tracker = &PendingRewardsTracker{
+		l:            zap.S(),
+		storage:      newMemoryStorage(),
+		notifyAmount: 100,
+		historySize:  3,
+		scanned:      make(map[string]bool),
+		delegations:  make(map[string]DelegationRewards),
+	}
+}
+
+func (ts *PendingRewardsTrackerTestSuite) TestNotifyOnceAboveAmount() {
+	assert := ts.Assert()
+	ts.tracker.AddDelegation("0xA", 1)
+
+	_, notify := ts.tracker.record("0xa", 1, pkg.PendingRewards{Epoch: 1, Pending: 50})
+	assert.False(notify)
+
+	delegation, notify := ts.tracker.record("0xa", 1, pkg.PendingRewards{Epoch: 2, Pending: 120})
+	assert.True(notify)
+	last, ok := delegation.Last()
+	assert.True(ok)
+	assert.Equal(uint64(2), last.Epoch)
+
+	_, notify = ts.tracker.record("0xa", 1, pkg.PendingRewards{Epoch: 3, Pending: 150})
+	assert.False(notify)
+
+	// claimed, the rewards grow again from zero
+	_, notify = ts.tracker.record("0xa", 1, pkg.PendingRewards{Epoch: 4, Pending: 0})
+	assert.False(notify)
+	_, notify = ts.tracker.record("0xa", 1, pkg.PendingRewards{Epoch: 5, Pending: 101})
+	assert.True(notify)
+}
+
+func (ts *PendingRewardsTrackerTestSuite) TestHistorySize() {
+	assert := ts.Assert()
+	ts.tracker.AddDelegation("0xa", 1)
+	for epoch := uint64(1); epoch <= 5; epoch++ {
+		ts.tracker.record("0xa", 1, pkg.PendingRewards{Epoch: epoch, Pending: 1})
+	}
+
+	history := ts.tracker.GetHistory("0xA")
+	assert.Equal(1, len(history))
+	assert.Equal([]pkg.PendingRewards{{Epoch: 3, Pending: 1}, {Epoch: 4, Pending: 1}, {Epoch: 5, Pending: 1}}, history[0].History)
+
+	_, notify := ts.tracker.record("0xb", 1, pkg.PendingRewards{Epoch: 5, Pending: 500})
+	assert.False(notify)
+}
+
+func (ts *PendingRewardsTrackerTestSuite) TestForgetUnwatched() {
+	assert := ts.Assert()
+	ts.tracker.AddDelegation("0xa", 1)
+	ts.tracker.AddDelegation("0xb", 2)
+	ts.tracker.scanned["0xb"] = true
+
+	ts.tracker.forgetUnwatched([]string{"0xa"})
+	assert.Equal(1, len(ts.tracker.GetHistory("0xa")))
+	assert.Equal(0, len(ts.tracker.GetHistory("0xb")))
+	assert.False(ts.tracker.scanned["0xb"])
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	SFCCallsEnabledFlag      = "sfc_calls.enabled"
+	SFCCallsUsualMethodsFlag = "sfc_calls.usual_methods"
+
+	DefaultSFCCallsEnabled = false
+)
+
+// DefaultSFCUsualMethods are the SFC methods called every day by delegators, validators and the network.
+var DefaultSFCUsualMethods = []string{
+	"delegate", "undelegate", "withdraw", "claimRewards", "restakeRewards", "stashRewards",
+	"lockStake", "unlockStake", "createValidator", "sealEpoch", "sealEpochValidators",
+}
+
+// IsNotableSFCCall returns whether the call failed or is not one of the usual methods.
+func IsNotableSFCCall(tx pkg.DecodedTx, usualMethods map[string]bool) bool {
+	return !tx.Success || !usualMethods[tx.Method]
+}
+
+// SFCCallMonitor decodes the transactions sent to the SFC contract and reports the failed and unusual ones.
+type SFCCallMonitor struct {
+	l            *zap.SugaredLogger
+	sfcClient    *SFCClient
+	notify       func(ctx context.Context, tx pkg.DecodedTx)
+	enabled      bool
+	usualMethods map[string]bool
+}
+
+func NewSFCCallMonitor(sfcClient *SFCClient, notify func(ctx context.Context, tx pkg.DecodedTx)) *SFCCallMonitor {
+	enabled := DefaultSFCCallsEnabled
+	if viper.IsSet(SFCCallsEnabledFlag) {
+		enabled = viper.GetBool(SFCCallsEnabledFlag)
+	}
+	methods := DefaultSFCUsualMethods
+	if viper.IsSet(SFCCallsUsualMethodsFlag) {
+		methods = viper.GetStringSlice(SFCCallsUsualMethodsFlag)
+	}
+	var usualMethods = make(map[string]bool)
+	for _, method := range methods {
+		usualMethods[method] = true
+	}
+
+	return &SFCCallMonitor{
+		l:            zap.S(),
+		sfcClient:    sfcClient,
+		notify:       notify,
+		enabled:      enabled,
+		usualMethods: usualMethods,
+	}
+}
+
+func (m *SFCCallMonitor) Enabled() bool {
+	return m.enabled
+}
+
+// HandleBlock notifies the failed and unusual SFC calls of the block.
+func (m *SFCCallMonitor) HandleBlock(ctx context.Context, blockNumber uint64) error {
+	calls, err := m.sfcClient.DecodeSFCCalls(ctx, blockNumber)
+	if err != nil {
+		return err
+	}
+	for _, tx := range calls {
+		if !IsNotableSFCCall(tx, m.usualMethods) {
+			continue
+		}
+		m.l.Debugw("notable SFC call", "tx_hash", tx.TxHash, "method", tx.Method, "success", tx.Success)
+		m.notify(ctx, tx)
+	}
+	return nil
+}
@@ -2,13 +2,17 @@ package core
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"math/big"
 	"sort"
+	"sync"
+	"time"
 
+	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	etherCommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/quangkeu95/fantom-bot/lib/contracts"
 	"github.com/quangkeu95/fantom-bot/pkg"
@@ -26,10 +30,16 @@ type SFCClient struct {
 	nodeClient *fetcher.NodeClient
 	wsClient   *fetcher.WsClient
 
-	sfcContract   *contracts.SFC
-	sfcWsContract *contracts.SFC
+	sfcAddress  etherCommon.Address
+	sfcContract *contracts.SFC
+	txDecoder   *TxDecoder
+
+	// pendingSub is the pending transactions subscription, the pending transactions are looked up on its endpoint
+	pendingSub fetcher.EndpointSubscription
+	pendingMu  sync.RWMutex
 }
 
+// NewSFCClient creates the SFC client, wsClient is nil when the chain is polled over rpc only.
 func NewSFCClient(nodeClient *fetcher.NodeClient, wsClient *fetcher.WsClient) (*SFCClient, error) {
 	l := zap.S()
 	sfcAddr := viper.GetString("fantom_chain.sfc_contract_address")
@@ -37,27 +47,45 @@ func NewSFCClient(nodeClient *fetcher.NodeClient, wsClient *fetcher.WsClient) (*
 		l.Errorw("sfc contract address is invalid", "error", err)
 		return nil, err
 	}
-	sfcContract, err := contracts.NewSFC(etherCommon.HexToAddress(sfcAddr), nodeClient.GetETHClient())
+	sfcContract, err := contracts.NewSFC(etherCommon.HexToAddress(sfcAddr), nodeClient.GetBackend())
 	if err != nil {
 		l.Warnw("init SFC contract error", "error", err)
 		return nil, err
 	}
 
-	sfcWsContract, err := contracts.NewSFC(etherCommon.HexToAddress(sfcAddr), wsClient.GetETHClient())
+	txDecoder, err := NewTxDecoder(nodeClient)
 	if err != nil {
-		l.Warnw("init SFC ws contract error", "error", err)
+		l.Warnw("init tx decoder error", "error", err)
 		return nil, err
 	}
 
 	return &SFCClient{
-		l:             l,
-		nodeClient:    nodeClient,
-		wsClient:      wsClient,
-		sfcContract:   sfcContract,
-		sfcWsContract: sfcWsContract,
+		l:           l,
+		nodeClient:  nodeClient,
+		wsClient:    wsClient,
+		sfcAddress:  etherCommon.HexToAddress(sfcAddr),
+		sfcContract: sfcContract,
+		txDecoder:   txDecoder,
 	}, nil
 }
 
+// Run checks the health of the rpc and ws endpoints until ctx is done.
+func (c *SFCClient) Run(ctx context.Context) {
+	if c.HasWs() {
+		go c.wsClient.Run(ctx)
+	}
+	c.nodeClient.Run(ctx)
+}
+
+// HasWs returns whether a ws endpoint is available for the subscriptions.
+func (c *SFCClient) HasWs() bool {
+	return c.wsClient != nil
+}
+
+func (c *SFCClient) GetLatestBlock(ctx context.Context) (uint64, error) {
+	return c.nodeClient.GetLatestBlock(ctx)
+}
+
 func (c *SFCClient) GetCreatedValidatorByID(ctx context.Context, ids []uint64) ([]pkg.SFCValidator, error) {
 	var validatorID = make([]*big.Int, 0)
 	for _, id := range ids {
@@ -102,7 +130,7 @@ func (c *SFCClient) GetCreatedValidatorByBlock(ctx context.Context, fromBlock ui
 	}
 
 	for {
-		if startBlock >= endBlock {
+		if startBlock > endBlock {
 			break
 		}
 		nextBlock := startBlock + BlockRange
@@ -139,68 +167,403 @@ func (c *SFCClient) GetCreatedValidatorByBlock(ctx context.Context, fromBlock ui
 	return listValidators, nil
 }
 
-func (c *SFCClient) WatchCreatedValidator(ctx context.Context, validatorCh chan<- pkg.SFCValidator, errCh chan<- error) {
-	opts := &bind.WatchOpts{
+func (c *SFCClient) GetValidatorByID(ctx context.Context, id uint64) (pkg.SFCValidator, error) {
+	return c.GetValidatorAt(ctx, id, nil)
+}
+
+// GetValidatorAt returns the validator in the state of blockNumber, the latest state when blockNumber is nil.
+func (c *SFCClient) GetValidatorAt(ctx context.Context, id uint64, blockNumber *uint64) (pkg.SFCValidator, error) {
+	validator, err := fetcher.GetValidator(ctx, &c.sfcContract.SFCCaller, id, blockNumber)
+	if err != nil {
+		c.l.Warnw("get validator by id error", "error", err, "validator_id", id)
+		return pkg.SFCValidator{}, err
+	}
+	// an unknown validator is returned empty
+	if validator == nil {
+		return pkg.SFCValidator{ID: id}, nil
+	}
+	return *validator, nil
+}
+
+func (c *SFCClient) GetLastValidatorID(ctx context.Context) (uint64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.LastValidatorID(opts)
+	if err != nil {
+		c.l.Warnw("get last validator id error", "error", err)
+		return 0, err
+	}
+	return res.Uint64(), nil
+}
+
+// callOptsAt returns the call options reading the contract state at blockNumber, the latest state when blockNumber is nil.
+func callOptsAt(ctx context.Context, blockNumber *uint64) *bind.CallOpts {
+	opts := &bind.CallOpts{
 		Context: ctx,
 	}
-	sink := make(chan *contracts.SFCCreatedValidator)
+	if blockNumber != nil {
+		opts.BlockNumber = new(big.Int).SetUint64(*blockNumber)
+	}
+	return opts
+}
 
-	sub, err := c.sfcWsContract.WatchCreatedValidator(opts, sink, nil, nil)
+func (c *SFCClient) GetBaseRewardPerSecond(ctx context.Context, blockNumber *uint64) (float64, error) {
+	res, err := c.sfcContract.BaseRewardPerSecond(callOptsAt(ctx, blockNumber))
 	if err != nil {
-		c.l.Warnw("watch created validator error", "error", err)
-		errCh <- err
-		return
+		c.l.Warnw("get base reward per second error", "error", err)
+		return 0, err
 	}
-	defer sub.Unsubscribe()
+	return pkg.WeiToFloat(res, 18), nil
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case validator := <-sink:
-			validatorCh <- pkg.ToSFCValidator(validator)
-		case err := <-sub.Err():
-			errCh <- err
-			return
+// GetOfflinePenaltyThreshold returns the number of blocks and the period a validator may be offline before being penalised.
+func (c *SFCClient) GetOfflinePenaltyThreshold(ctx context.Context, blockNumber *uint64) (uint64, uint64, error) {
+	res, err := c.sfcContract.OfflinePenaltyThreshold(callOptsAt(ctx, blockNumber))
+	if err != nil {
+		c.l.Warnw("get offline penalty threshold error", "error", err)
+		return 0, 0, err
+	}
+	return res.BlocksNum.Uint64(), res.Time.Uint64(), nil
+}
+
+func (c *SFCClient) GetSlashingRefundRatio(ctx context.Context, validatorID uint64, blockNumber *uint64) (float64, error) {
+	res, err := c.sfcContract.SlashingRefundRatio(callOptsAt(ctx, blockNumber), new(big.Int).SetUint64(validatorID))
+	if err != nil {
+		c.l.Warnw("get slashing refund ratio error", "error", err, "validator_id", validatorID)
+		return 0, err
+	}
+	return pkg.WeiToFloat(res, 18), nil
+}
+
+func (c *SFCClient) GetCurrentSealedEpoch(ctx context.Context) (uint64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.CurrentSealedEpoch(opts)
+	if err != nil {
+		c.l.Warnw("get current sealed epoch error", "error", err)
+		return 0, err
+	}
+	return res.Uint64(), nil
+}
+
+// GetEpochSnapshot returns the snapshot of a sealed epoch with its validator set.
+func (c *SFCClient) GetEpochSnapshot(ctx context.Context, epoch uint64) (pkg.EpochSnapshot, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	epochNumber := new(big.Int).SetUint64(epoch)
+	res, err := c.sfcContract.GetEpochSnapshot(opts, epochNumber)
+	if err != nil {
+		c.l.Warnw("get epoch snapshot error", "error", err, "epoch", epoch)
+		return pkg.EpochSnapshot{}, err
+	}
+	validatorIDs, err := c.sfcContract.GetEpochValidatorIDs(opts, epochNumber)
+	if err != nil {
+		c.l.Warnw("get epoch validator ids error", "error", err, "epoch", epoch)
+		return pkg.EpochSnapshot{}, err
+	}
+
+	var validators = make([]pkg.EpochValidator, 0, len(validatorIDs))
+	for _, validatorID := range validatorIDs {
+		receivedStake, err := c.sfcContract.GetEpochReceivedStake(opts, epochNumber, validatorID)
+		if err != nil {
+			c.l.Warnw("get epoch received stake error", "error", err, "epoch", epoch, "validator_id", validatorID)
+			return pkg.EpochSnapshot{}, err
 		}
+		originatedTxsFee, err := c.sfcContract.GetEpochAccumulatedOriginatedTxsFee(opts, epochNumber, validatorID)
+		if err != nil {
+			c.l.Warnw("get epoch originated txs fee error", "error", err, "epoch", epoch, "validator_id", validatorID)
+			return pkg.EpochSnapshot{}, err
+		}
+		offlineTime, err := c.sfcContract.GetEpochOfflineTime(opts, epochNumber, validatorID)
+		if err != nil {
+			c.l.Warnw("get epoch offline time error", "error", err, "epoch", epoch, "validator_id", validatorID)
+			return pkg.EpochSnapshot{}, err
+		}
+		offlineBlocks, err := c.sfcContract.GetEpochOfflineBlocks(opts, epochNumber, validatorID)
+		if err != nil {
+			c.l.Warnw("get epoch offline blocks error", "error", err, "epoch", epoch, "validator_id", validatorID)
+			return pkg.EpochSnapshot{}, err
+		}
+		accumulatedUptime, err := c.sfcContract.GetEpochAccumulatedUptime(opts, epochNumber, validatorID)
+		if err != nil {
+			c.l.Warnw("get epoch accumulated uptime error", "error", err, "epoch", epoch, "validator_id", validatorID)
+			return pkg.EpochSnapshot{}, err
+		}
+		validators = append(validators, pkg.EpochValidator{
+			ID:                validatorID.Uint64(),
+			ReceivedStake:     pkg.WeiToFloat(receivedStake, 18),
+			OriginatedTxsFee:  pkg.WeiToFloat(originatedTxsFee, 18),
+			OfflineTime:       offlineTime.Uint64(),
+			OfflineBlocks:     offlineBlocks.Uint64(),
+			AccumulatedUptime: accumulatedUptime.Uint64(),
+		})
+	}
+
+	return pkg.EpochSnapshot{
+		Epoch:               epoch,
+		EndTime:             res.EndTime.Uint64(),
+		EpochFee:            pkg.WeiToFloat(res.EpochFee, 18),
+		TotalStake:          pkg.WeiToFloat(res.TotalStake, 18),
+		TotalSupply:         pkg.WeiToFloat(res.TotalSupply, 18),
+		BaseRewardPerSecond: pkg.WeiToFloat(res.BaseRewardPerSecond, 18),
+		Validators:          validators,
+	}, nil
+}
+
+// GetNetworkStats returns the current network wide staking values.
+func (c *SFCClient) GetNetworkStats(ctx context.Context) (pkg.NetworkStats, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	epoch, err := c.sfcContract.CurrentSealedEpoch(opts)
+	if err != nil {
+		c.l.Warnw("get current sealed epoch error", "error", err)
+		return pkg.NetworkStats{}, err
+	}
+	totalStake, err := c.sfcContract.TotalStake(opts)
+	if err != nil {
+		c.l.Warnw("get total stake error", "error", err)
+		return pkg.NetworkStats{}, err
+	}
+	totalActiveStake, err := c.sfcContract.TotalActiveStake(opts)
+	if err != nil {
+		c.l.Warnw("get total active stake error", "error", err)
+		return pkg.NetworkStats{}, err
+	}
+	totalSupply, err := c.sfcContract.TotalSupply(opts)
+	if err != nil {
+		c.l.Warnw("get total supply error", "error", err)
+		return pkg.NetworkStats{}, err
 	}
+	baseRewardPerSecond, err := c.sfcContract.BaseRewardPerSecond(opts)
+	if err != nil {
+		c.l.Warnw("get base reward per second error", "error", err)
+		return pkg.NetworkStats{}, err
+	}
+	return pkg.NetworkStats{
+		Epoch:               epoch.Uint64(),
+		TotalStake:          pkg.WeiToFloat(totalStake, 18),
+		TotalActiveStake:    pkg.WeiToFloat(totalActiveStake, 18),
+		TotalSupply:         pkg.WeiToFloat(totalSupply, 18),
+		BaseRewardPerSecond: pkg.WeiToFloat(baseRewardPerSecond, 18),
+	}, nil
 }
 
-func (c *SFCClient) GetValidatorByID(ctx context.Context, id uint64) (pkg.SFCValidator, error) {
+func (c *SFCClient) GetRewardParams(ctx context.Context) (pkg.RewardParams, error) {
 	opts := &bind.CallOpts{
 		Context: ctx,
 	}
-	res, err := c.sfcContract.GetValidator(opts, new(big.Int).SetUint64(id))
+	baseRewardPerSecond, err := c.sfcContract.BaseRewardPerSecond(opts)
 	if err != nil {
-		c.l.Warnw("get validator by id error", "error", err)
-		return pkg.SFCValidator{}, err
+		c.l.Warnw("get base reward per second error", "error", err)
+		return pkg.RewardParams{}, err
+	}
+	unlockedRewardRatio, err := c.sfcContract.UnlockedRewardRatio(opts)
+	if err != nil {
+		c.l.Warnw("get unlocked reward ratio error", "error", err)
+		return pkg.RewardParams{}, err
+	}
+	minLockupDuration, err := c.sfcContract.MinLockupDuration(opts)
+	if err != nil {
+		c.l.Warnw("get min lockup duration error", "error", err)
+		return pkg.RewardParams{}, err
+	}
+	maxLockupDuration, err := c.sfcContract.MaxLockupDuration(opts)
+	if err != nil {
+		c.l.Warnw("get max lockup duration error", "error", err)
+		return pkg.RewardParams{}, err
 	}
 
-	var isActive = res.Status.Cmp(new(big.Int)) == 0
+	return pkg.RewardParams{
+		BaseRewardPerSecond: pkg.WeiToFloat(baseRewardPerSecond, 18),
+		UnlockedRewardRatio: pkg.WeiToFloat(unlockedRewardRatio, 18),
+		MinLockupDuration:   minLockupDuration.Uint64(),
+		MaxLockupDuration:   maxLockupDuration.Uint64(),
+	}, nil
+}
+
+func (c *SFCClient) GetEpochEndTime(ctx context.Context, epoch uint64) (uint64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.GetEpochSnapshot(opts, new(big.Int).SetUint64(epoch))
+	if err != nil {
+		c.l.Warnw("get epoch snapshot error", "error", err, "epoch", epoch)
+		return 0, err
+	}
+	return res.EndTime.Uint64(), nil
+}
 
-	return pkg.SFCValidator{
-		ID:               id,
-		Address:          res.Auth.Hex(),
-		CreatedTime:      res.CreatedTime.Uint64(),
-		CreatedEpoch:     res.CreatedEpoch.Uint64(),
-		DeactivatedTime:  res.DeactivatedTime.Uint64(),
-		DeactivatedEpoch: res.DeactivatedEpoch.Uint64(),
-		IsActive:         isActive,
+func (c *SFCClient) GetEpochValidatorIDs(ctx context.Context, epoch uint64) ([]uint64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.GetEpochValidatorIDs(opts, new(big.Int).SetUint64(epoch))
+	if err != nil {
+		c.l.Warnw("get epoch validator ids error", "error", err, "epoch", epoch)
+		return nil, err
+	}
+	var result = make([]uint64, 0, len(res))
+	for _, id := range res {
+		result = append(result, id.Uint64())
+	}
+	return result, nil
+}
+
+// GetEpochAccumulatedRewardPerToken returns the reward per staked FTM accumulated by the delegations to the validator.
+func (c *SFCClient) GetEpochAccumulatedRewardPerToken(ctx context.Context, epoch uint64, validatorID uint64) (float64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.GetEpochAccumulatedRewardPerToken(opts, new(big.Int).SetUint64(epoch), new(big.Int).SetUint64(validatorID))
+	if err != nil {
+		c.l.Warnw("get epoch accumulated reward per token error", "error", err, "epoch", epoch, "validator_id", validatorID)
+		return 0, err
+	}
+	return pkg.WeiToFloat(res, 18), nil
+}
+
+func (c *SFCClient) IsSlashed(ctx context.Context, validatorID uint64, blockNumber *uint64) (bool, error) {
+	res, err := c.sfcContract.IsSlashed(callOptsAt(ctx, blockNumber), new(big.Int).SetUint64(validatorID))
+	if err != nil {
+		c.l.Warnw("get is slashed error", "error", err, "validator_id", validatorID)
+		return false, err
+	}
+	return res, nil
+}
+
+func (c *SFCClient) GetTotalSlashedStake(ctx context.Context) (float64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.TotalSlashedStake(opts)
+	if err != nil {
+		c.l.Warnw("get total slashed stake error", "error", err)
+		return 0, err
+	}
+	return pkg.WeiToFloat(res, 18), nil
+}
+
+func (c *SFCClient) GetStake(ctx context.Context, delegator string, validatorID uint64) (float64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.GetStake(opts, etherCommon.HexToAddress(delegator), new(big.Int).SetUint64(validatorID))
+	if err != nil {
+		c.l.Warnw("get stake error", "error", err, "delegator", delegator, "validator_id", validatorID)
+		return 0, err
+	}
+	return pkg.WeiToFloat(res, 18), nil
+}
+
+// GetMaxDelegatedRatio returns the maximum ratio between the received stake and the self stake of a validator.
+func (c *SFCClient) GetMaxDelegatedRatio(ctx context.Context) (float64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.MaxDelegatedRatio(opts)
+	if err != nil {
+		c.l.Warnw("get max delegated ratio error", "error", err)
+		return 0, err
+	}
+	return pkg.WeiToFloat(res, 18), nil
+}
+
+func (c *SFCClient) GetLockupInfo(ctx context.Context, delegator string, validatorID uint64) (pkg.LockupInfo, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.GetLockupInfo(opts, etherCommon.HexToAddress(delegator), new(big.Int).SetUint64(validatorID))
+	if err != nil {
+		c.l.Warnw("get lockup info error", "error", err, "delegator", delegator, "validator_id", validatorID)
+		return pkg.LockupInfo{}, err
+	}
+	return pkg.LockupInfo{
+		Delegator:   delegator,
+		ValidatorID: validatorID,
+		LockedStake: pkg.WeiToFloat(res.LockedStake, 18),
+		FromEpoch:   res.FromEpoch.Uint64(),
+		EndTime:     res.EndTime.Uint64(),
+		Duration:    res.Duration.Uint64(),
 	}, nil
 }
 
-func (c *SFCClient) GetLastValidatorID(ctx context.Context) (uint64, error) {
+// GetPendingRewards returns the rewards of the delegation not claimed yet, the epoch is left to the caller.
+func (c *SFCClient) GetPendingRewards(ctx context.Context, delegator string, validatorID uint64) (pkg.PendingRewards, error) {
 	opts := &bind.CallOpts{
 		Context: ctx,
 	}
-	res, err := c.sfcContract.LastValidatorID(opts)
+	pending, err := c.sfcContract.PendingRewards(opts, etherCommon.HexToAddress(delegator), new(big.Int).SetUint64(validatorID))
 	if err != nil {
-		c.l.Warnw("get last validator id error", "error", err)
+		c.l.Warnw("get pending rewards error", "error", err, "delegator", delegator, "validator_id", validatorID)
+		return pkg.PendingRewards{}, err
+	}
+	stashed, err := c.sfcContract.RewardsStash(opts, etherCommon.HexToAddress(delegator), new(big.Int).SetUint64(validatorID))
+	if err != nil {
+		c.l.Warnw("get rewards stash error", "error", err, "delegator", delegator, "validator_id", validatorID)
+		return pkg.PendingRewards{}, err
+	}
+	return pkg.PendingRewards{
+		Pending: pkg.WeiToFloat(pending, 18),
+		Stashed: pkg.WeiToFloat(stashed, 18),
+	}, nil
+}
+
+func (c *SFCClient) GetCurrentEpoch(ctx context.Context) (uint64, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.CurrentEpoch(opts)
+	if err != nil {
+		c.l.Warnw("get current epoch error", "error", err)
 		return 0, err
 	}
 	return res.Uint64(), nil
 }
 
+// GetWithdrawalRequest returns the withdrawal request, its amount is 0 once it has been withdrawn.
+func (c *SFCClient) GetWithdrawalRequest(ctx context.Context, delegator string, validatorID uint64, wrID uint64) (pkg.WithdrawalRequest, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	res, err := c.sfcContract.GetWithdrawalRequest(opts, etherCommon.HexToAddress(delegator), new(big.Int).SetUint64(validatorID), new(big.Int).SetUint64(wrID))
+	if err != nil {
+		c.l.Warnw("get withdrawal request error", "error", err, "delegator", delegator, "validator_id", validatorID, "wr_id", wrID)
+		return pkg.WithdrawalRequest{}, err
+	}
+	return pkg.WithdrawalRequest{
+		Delegator:   delegator,
+		ValidatorID: validatorID,
+		WrID:        wrID,
+		Epoch:       res.Epoch.Uint64(),
+		Time:        res.Time.Uint64(),
+		Amount:      pkg.WeiToFloat(res.Amount, 18),
+	}, nil
+}
+
+func (c *SFCClient) GetWithdrawalPeriod(ctx context.Context) (pkg.WithdrawalPeriod, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	epochs, err := c.sfcContract.WithdrawalPeriodEpochs(opts)
+	if err != nil {
+		c.l.Warnw("get withdrawal period epochs error", "error", err)
+		return pkg.WithdrawalPeriod{}, err
+	}
+	period, err := c.sfcContract.WithdrawalPeriodTime(opts)
+	if err != nil {
+		c.l.Warnw("get withdrawal period time error", "error", err)
+		return pkg.WithdrawalPeriod{}, err
+	}
+	return pkg.WithdrawalPeriod{
+		Epochs: epochs.Uint64(),
+		Time:   period.Uint64(),
+	}, nil
+}
+
 func (c *SFCClient) GetDelegateInfoByBlock(ctx context.Context, fromBlock uint64, toBlock *uint64) ([]pkg.SFCDelegateInfo, error) {
 	var result = make([]pkg.SFCDelegateInfo, 0)
 	opts := &bind.FilterOpts{
@@ -229,73 +592,95 @@ func (c *SFCClient) GetDelegateInfoByBlock(ctx context.Context, fromBlock uint64
 	return result, nil
 }
 
-func (c *SFCClient) WatchDelegateEvent(ctx context.Context, delegateInfoCh chan<- pkg.SFCDelegateInfo, errCh chan<- error) {
-	opts := &bind.WatchOpts{
+// GetUndelegateInfoByWithdrawalRequest returns the undelegation which created the withdrawal request.
+func (c *SFCClient) GetUndelegateInfoByWithdrawalRequest(ctx context.Context, delegator string, validatorID uint64, wrID uint64, fromBlock uint64, toBlock uint64) (pkg.SFCUndelegateInfo, error) {
+	opts := &bind.FilterOpts{
+		Start:   fromBlock,
+		End:     &toBlock,
 		Context: ctx,
 	}
-	var (
-		sink = make(chan *contracts.SFCDelegated)
-	)
-	sub, err := c.sfcWsContract.WatchDelegated(opts, sink, nil, nil)
+
+	iterator, err := c.sfcContract.FilterUndelegated(opts,
+		[]etherCommon.Address{etherCommon.HexToAddress(delegator)},
+		[]*big.Int{new(big.Int).SetUint64(validatorID)},
+		[]*big.Int{new(big.Int).SetUint64(wrID)})
 	if err != nil {
-		c.l.Warnw("watch delegate error", "error", err)
-		errCh <- err
-		return
+		c.l.Warnw("get undelegate by withdrawal request error", "error", err)
+		return pkg.SFCUndelegateInfo{}, err
 	}
-	defer sub.Unsubscribe()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case info := <-sink:
-			delegateInfoCh <- pkg.ToSFCDelegateInfo(info)
-		case err := <-sub.Err():
-			errCh <- err
-			return
+	var (
+		result pkg.SFCUndelegateInfo
+		found  bool
+	)
+	for iterator.Next() {
+		if err := iterator.Error(); err != nil {
+			c.l.Debugw("parse undelegate error", "error", err)
+			continue
 		}
+		result, found = pkg.ToSFCUndelegateInfo(iterator.Event), true
+	}
+	if !found {
+		return pkg.SFCUndelegateInfo{}, fmt.Errorf("undelegation of withdrawal request %d not found", wrID)
 	}
+	return result, nil
 }
 
-func (c *SFCClient) GetUndelegateInfoByBlock(ctx context.Context, fromBlock uint64, toBlock *uint64) ([]pkg.SFCUndelegateInfo, error) {
-	var result = make([]pkg.SFCUndelegateInfo, 0)
-	opts := &bind.FilterOpts{
-		Context: ctx,
-		Start:   fromBlock,
-		End:     toBlock,
+// GetBlockHead returns the head of the block by number, the latest block when number is nil.
+func (c *SFCClient) GetBlockHead(ctx context.Context, number *uint64) (pkg.BlockHead, error) {
+	head, err := c.nodeClient.GetBlockHead(ctx, number)
+	if err != nil {
+		c.l.Warnw("get block head error", "error", err)
+		return pkg.BlockHead{}, err
 	}
+	return head, nil
+}
 
-	iterator, err := c.sfcContract.FilterUndelegated(opts, nil, nil, nil)
+func (c *SFCClient) GetBlockTime(ctx context.Context, blockNumber uint64) (time.Time, error) {
+	head, err := c.nodeClient.GetBlockHead(ctx, &blockNumber)
 	if err != nil {
-		c.l.Warnw("get undelegate error", "error", err)
-		return nil, err
+		c.l.Warnw("get block time error", "error", err, "block_number", blockNumber)
+		return time.Time{}, err
 	}
+	return time.Unix(int64(head.Time), 0), nil
+}
 
-	for iterator.Next() {
-		if err := iterator.Error(); err != nil {
-			c.l.Debugw("parse undelegate error", "error", err)
-			continue
-		}
-		result = append(result, pkg.ToSFCUndelegateInfo(iterator.Event))
-	}
+// GetLogsByBlock returns the logs of the event topic emitted by the contract between fromBlock and toBlock.
+func (c *SFCClient) GetLogsByBlock(ctx context.Context, address etherCommon.Address, topic etherCommon.Hash, fromBlock uint64, toBlock uint64) ([]types.Log, error) {
+	return c.GetLogsByTopics(ctx, []etherCommon.Address{address}, []etherCommon.Hash{topic}, fromBlock, toBlock)
+}
 
-	sort.SliceStable(result, func(i, j int) bool {
-		return result[i].BlockNumber < result[j].BlockNumber
-	})
-	return result, nil
+// GetLogsByTopics returns the logs of any of the event topics emitted by any of the contracts in a single query.
+func (c *SFCClient) GetLogsByTopics(ctx context.Context, addresses []etherCommon.Address, topics []etherCommon.Hash, fromBlock uint64, toBlock uint64) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: addresses,
+		Topics:    [][]etherCommon.Hash{topics},
+	}
+	logs, err := c.nodeClient.GetBackend().FilterLogs(ctx, query)
+	if err != nil {
+		c.l.Warnw("get logs error", "error", err, "addresses", addresses, "topics", topics, "from_block", fromBlock, "to_block", toBlock)
+		return nil, err
+	}
+	return logs, nil
 }
 
-func (c *SFCClient) WatchUndelegateEvent(ctx context.Context, undelegateInfoCh chan<- pkg.SFCUndelegateInfo, errCh chan<- error) {
-	opts := &bind.WatchOpts{
-		Context: ctx,
+// SubscribeLogs subscribes to the logs of the event topic emitted by the contract, errors are sent to errCh
+// and end the subscription.
+func (c *SFCClient) SubscribeLogs(ctx context.Context, address etherCommon.Address, topic etherCommon.Hash, logCh chan<- types.Log, errCh chan<- error) {
+	query := ethereum.FilterQuery{
+		Addresses: []etherCommon.Address{address},
+		Topics:    [][]etherCommon.Hash{{topic}},
 	}
-	var (
-		sink = make(chan *contracts.SFCUndelegated)
-	)
-	sub, err := c.sfcWsContract.WatchUndelegated(opts, sink, nil, nil, nil)
+	sink := make(chan types.Log)
+	sub, err := c.wsClient.SubscribeFilterLogs(ctx, query, sink)
 	if err != nil {
-		c.l.Warnw("watch undelegate error", "error", err)
-		errCh <- err
+		c.l.Warnw("subscribe logs error", "error", err, "address", address.Hex(), "topic", topic.Hex())
+		select {
+		case errCh <- err:
+		case <-ctx.Done():
+		}
 		return
 	}
 	defer sub.Unsubscribe()
@@ -304,26 +689,31 @@ func (c *SFCClient) WatchUndelegateEvent(ctx context.Context, undelegateInfoCh c
 		select {
 		case <-ctx.Done():
 			return
-		case info := <-sink:
-			undelegateInfoCh <- pkg.ToSFCUndelegateInfo(info)
+		case item := <-sink:
+			select {
+			case logCh <- item:
+			case <-ctx.Done():
+				return
+			}
 		case err := <-sub.Err():
-			errCh <- err
+			select {
+			case errCh <- err:
+			case <-ctx.Done():
+			}
 			return
 		}
 	}
 }
 
-func (c *SFCClient) WatchClaimRewardEvent(ctx context.Context, rewardInfoCh chan<- pkg.SFCRewardInfo, errCh chan<- error) {
-	opts := &bind.WatchOpts{
-		Context: ctx,
-	}
-	var (
-		sink = make(chan *contracts.SFCClaimedRewards)
-	)
-	sub, err := c.sfcWsContract.WatchClaimedRewards(opts, sink, nil, nil)
+func (c *SFCClient) SubscribeNewHead(ctx context.Context, headCh chan<- pkg.BlockHead, errCh chan<- error) {
+	sink := make(chan *fetcher.BlockHeadResponse)
+	sub, err := c.wsClient.SubscribeNewHead(ctx, sink)
 	if err != nil {
-		c.l.Warnw("watch claim reward error", "error", err)
-		errCh <- err
+		c.l.Warnw("subscribe new head error", "error", err)
+		select {
+		case errCh <- err:
+		case <-ctx.Done():
+		}
 		return
 	}
 	defer sub.Unsubscribe()
@@ -332,97 +722,184 @@ func (c *SFCClient) WatchClaimRewardEvent(ctx context.Context, rewardInfoCh chan
 		select {
 		case <-ctx.Done():
 			return
-		case info := <-sink:
-			rewardInfoCh <- pkg.ToSFCRewardInfo(info)
+		case head := <-sink:
+			select {
+			case headCh <- head.ToBlockHead():
+			case <-ctx.Done():
+				return
+			}
 		case err := <-sub.Err():
-			errCh <- err
+			select {
+			case errCh <- err:
+			case <-ctx.Done():
+			}
 			return
 		}
 	}
 }
 
-func (c *SFCClient) WatchLockedUpStakeEvent(ctx context.Context, lockedUpStakeCh chan<- pkg.SFCLockedUpStake, errCh chan<- error) {
-	opts := &bind.WatchOpts{
-		Context: ctx,
-	}
-	var (
-		sink = make(chan *contracts.SFCLockedUpStake)
-	)
-	sub, err := c.sfcWsContract.WatchLockedUpStake(opts, sink, nil, nil)
+func (c *SFCClient) SubscribePendingTransactions(ctx context.Context, hashCh chan<- string, errCh chan<- error) {
+	sink := make(chan etherCommon.Hash)
+	sub, err := c.wsClient.SubscribePendingTransactions(ctx, sink)
 	if err != nil {
-		c.l.Warnw("watch locked up stake error", "error", err)
-		errCh <- err
+		c.l.Warnw("subscribe pending transactions error", "error", err)
+		select {
+		case errCh <- err:
+		case <-ctx.Done():
+		}
 		return
 	}
+	c.pendingMu.Lock()
+	c.pendingSub = sub
+	c.pendingMu.Unlock()
 	defer sub.Unsubscribe()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case info := <-sink:
-			lockedUpStakeCh <- pkg.ToSFCLockedUpStake(info)
+		case hash := <-sink:
+			select {
+			case hashCh <- hash.Hex():
+			case <-ctx.Done():
+				return
+			}
 		case err := <-sub.Err():
-			errCh <- err
+			select {
+			case errCh <- err:
+			case <-ctx.Done():
+			}
 			return
 		}
 	}
 }
 
-func (c *SFCClient) WatchUnlockedStakeEvent(ctx context.Context, unlockedStakeCh chan<- pkg.SFCUnlockedStake, errCh chan<- error) {
-	opts := &bind.WatchOpts{
-		Context: ctx,
+// pendingTxClient returns the client of the endpoint serving the pending transactions subscription,
+// the healthiest ws endpoint before the subscription is made.
+func (c *SFCClient) pendingTxClient() (*ethclient.Client, error) {
+	c.pendingMu.RLock()
+	sub := c.pendingSub
+	c.pendingMu.RUnlock()
+
+	var client *ethclient.Client
+	if sub != nil {
+		client = sub.Endpoint().ETHClient()
+	} else {
+		client = c.wsClient.GetETHClient()
 	}
-	var (
-		sink = make(chan *contracts.SFCUnlockedStake)
-	)
-	sub, err := c.sfcWsContract.WatchUnlockedStake(opts, sink, nil, nil)
+	if client == nil {
+		return nil, fetcher.ErrNoEndpoint
+	}
+	return client, nil
+}
+
+// GetPendingTx returns the transaction from the mempool of the ws node serving the pending transactions subscription,
+// false when it is not pending anymore.
+func (c *SFCClient) GetPendingTx(ctx context.Context, txHash string) (pkg.PendingTx, bool, error) {
+	client, err := c.pendingTxClient()
 	if err != nil {
-		c.l.Warnw("watch unlocked stake error", "error", err)
-		errCh <- err
-		return
+		return pkg.PendingTx{}, false, err
+	}
+	tx, isPending, err := client.TransactionByHash(ctx, etherCommon.HexToHash(txHash))
+	if err != nil {
+		return pkg.PendingTx{}, false, err
+	}
+	if !isPending {
+		return pkg.PendingTx{}, false, nil
+	}
+	msg, err := tx.AsMessage(types.NewEIP155Signer(c.nodeClient.GetChainID()))
+	if err != nil {
+		c.l.Warnw("get tx as message error", "error", err, "tx_hash", txHash)
+		return pkg.PendingTx{}, false, err
 	}
-	defer sub.Unsubscribe()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case info := <-sink:
-			unlockedStakeCh <- pkg.ToSFCUnlockedStake(info)
-		case err := <-sub.Err():
-			errCh <- err
-			return
-		}
+	result := pkg.PendingTx{
+		Hash:  tx.Hash().Hex(),
+		From:  msg.From().Hex(),
+		Value: pkg.WeiToFloat(tx.Value(), 18),
+		Nonce: tx.Nonce(),
+	}
+	if tx.To() != nil {
+		result.To = tx.To().Hex()
+	}
+	if call, err := c.txDecoder.DecodeInput(tx.Data()); err == nil {
+		result.DecodedCall = call
 	}
+	return result, true, nil
 }
 
-func (c *SFCClient) SubscribeNewHead(ctx context.Context, headCh chan<- *types.Header, errCh chan<- error) {
-	sub, err := c.wsClient.GetETHClient().SubscribeNewHead(ctx, headCh)
+// GetTxStatus returns the block number and the success of a mined transaction, and whether it has been mined.
+func (c *SFCClient) GetTxStatus(ctx context.Context, txHash string) (uint64, bool, bool, error) {
+	receipt, err := c.nodeClient.GetTxReceipt(ctx, etherCommon.HexToHash(txHash))
+	if err == ethereum.NotFound {
+		return 0, false, false, nil
+	}
 	if err != nil {
-		c.l.Warnf("subscribe new head error", "error", err)
-		errCh <- err
-		return
+		c.l.Warnw("get tx receipt error", "error", err, "tx_hash", txHash)
+		return 0, false, false, err
 	}
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-sub.Err():
-			errCh <- err
-			return
-		}
+	return receipt.BlockNumber.Uint64(), receipt.Status == types.ReceiptStatusSuccessful, true, nil
+}
+
+// IsTxKnown returns whether the ws node serving the pending transactions subscription still knows the transaction,
+// pending or mined.
+func (c *SFCClient) IsTxKnown(ctx context.Context, txHash string) (bool, error) {
+	client, err := c.pendingTxClient()
+	if err != nil {
+		return false, err
 	}
+	_, _, err = client.TransactionByHash(ctx, etherCommon.HexToHash(txHash))
+	if err == ethereum.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetNonce returns the nonce of the address at the latest block.
+func (c *SFCClient) GetNonce(ctx context.Context, address string) (uint64, error) {
+	nonce, err := c.nodeClient.GetNonce(ctx, etherCommon.HexToAddress(address))
+	if err != nil {
+		c.l.Warnw("get nonce error", "error", err, "address", address)
+		return 0, err
+	}
+	return nonce, nil
 }
 
-func (c *SFCClient) GetTxByHash(ctx context.Context, txHash string) error {
-	tx, _, err := c.nodeClient.GetETHClient().TransactionByHash(ctx, etherCommon.HexToHash(txHash))
+// GetTokenInfo resolves the symbol and the decimals of an ERC20 token.
+func (c *SFCClient) GetTokenInfo(ctx context.Context, address etherCommon.Address) (pkg.TokenInfo, error) {
+	token, err := contracts.NewERC20Caller(address, c.nodeClient.GetBackend())
 	if err != nil {
-		c.l.Warnw("get tx by hash error", "error", err)
-		return err
+		return pkg.TokenInfo{}, err
 	}
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	symbol, err := token.Symbol(opts)
+	if err != nil {
+		c.l.Warnw("get token symbol error", "error", err, "token", address.Hex())
+		return pkg.TokenInfo{}, err
+	}
+	decimals, err := token.Decimals(opts)
+	if err != nil {
+		c.l.Warnw("get token decimals error", "error", err, "token", address.Hex())
+		return pkg.TokenInfo{}, err
+	}
+	return pkg.TokenInfo{
+		Address:  address.Hex(),
+		Symbol:   symbol,
+		Decimals: decimals,
+	}, nil
+}
+
+// DecodeTx returns the transaction with its call data, status and events decoded.
+func (c *SFCClient) DecodeTx(ctx context.Context, txHash string) (pkg.DecodedTx, error) {
+	return c.txDecoder.Decode(ctx, txHash)
+}
 
-	data := etherCommon.Bytes2Hex(tx.Data())
-	log.Println(data)
-	return nil
+// DecodeSFCCalls returns the decoded transactions of the block sent to the SFC contract.
+func (c *SFCClient) DecodeSFCCalls(ctx context.Context, blockNumber uint64) ([]pkg.DecodedTx, error) {
+	return c.txDecoder.DecodeBlockCalls(ctx, blockNumber, c.sfcAddress)
 }
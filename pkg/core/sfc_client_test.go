@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/quangkeu95/fantom-bot/config"
+	"github.com/quangkeu95/fantom-bot/pkg/fetcher"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -22,10 +23,10 @@ func (ts *SFCCLientTestSuite) SetupSuite() {
 	config.InitConfig()
 
 	assert := ts.Assert()
-	nodeClient, err := NewNodeClient()
+	nodeClient, err := fetcher.NewNodeClient()
 	assert.NoError(err)
 	assert.NotNil(nodeClient)
-	wsClient, err := NewWsClient()
+	wsClient, err := fetcher.NewWsClient()
 	assert.NoError(err)
 	assert.NotNil(wsClient)
 	client, err := NewSFCClient(nodeClient, wsClient)
@@ -85,10 +86,14 @@ func (ts *SFCCLientTestSuite) TestGetDelegate() {
 	log.Println(lastDelegate)
 }
 
-func (ts *SFCCLientTestSuite) TestGetTxByHash() {
+func (ts *SFCCLientTestSuite) TestDecodeTx() {
 	assert := ts.Assert()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	err := ts.client.GetTxByHash(ctx, "0x217c056f854f99842fb40ace08f3d57f9cc78379bac0b09c6850cce742ae4c92")
+	tx, err := ts.client.DecodeTx(ctx, "0x217c056f854f99842fb40ace08f3d57f9cc78379bac0b09c6850cce742ae4c92")
 	assert.NoError(err)
+	assert.Equal(SFCContractName, tx.Contract)
+	assert.NotEmpty(tx.Method)
+
+	log.Println(tx)
 }
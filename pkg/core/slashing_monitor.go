@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"go.uber.org/zap"
+)
+
+const SlashingReportedStorageKey = "slashing_reported"
+
+// SlashingPenalty returns the part of a stake lost when it is withdrawn from a slashed validator.
+func SlashingPenalty(stake, refundRatio float64) float64 {
+	if refundRatio >= 1 {
+		return 0
+	}
+	return stake * (1 - refundRatio)
+}
+
+type DelegatorImpact struct {
+	Delegator string
+	Stake     float64
+	Penalty   float64
+}
+
+// SlashingReport describes a newly slashed validator, amounts are in FTM.
+type SlashingReport struct {
+	ValidatorID       uint64
+	ReceivedStake     float64
+	RefundRatio       float64
+	SlashedAmount     float64
+	TotalSlashedStake float64
+	// Impacts are the known delegators of the validator sorted by penalty, the self stake included.
+	Impacts []DelegatorImpact
+	pkg.EventLog
+}
+
+// SlashingMonitor reports the validators slashed for double signing with the estimated loss of their delegators.
+type SlashingMonitor struct {
+	l                  *zap.SugaredLogger
+	sfcClient          *SFCClient
+	storage            storage.KeyValueStorage
+	validatorKeeper    *keeper.ValidatorsKeeper
+	delegateInfoKeeper *keeper.DelegateInfoKeeper
+	notify             func(ctx context.Context, report SlashingReport)
+
+	reported map[uint64]bool
+	mu       sync.Mutex
+}
+
+func NewSlashingMonitor(sfcClient *SFCClient, keyValueStorage storage.KeyValueStorage, validatorKeeper *keeper.ValidatorsKeeper, delegateInfoKeeper *keeper.DelegateInfoKeeper, notify func(ctx context.Context, report SlashingReport)) *SlashingMonitor {
+	l := zap.S()
+
+	// the reported validators are kept across restarts so the replayed events are not alerted again
+	var reported map[uint64]bool
+	if err := keyValueStorage.Get(SlashingReportedStorageKey, &reported); err != nil {
+		l.Debugw("no reported slashings found in storage", "error", err)
+		reported = make(map[uint64]bool)
+	}
+
+	return &SlashingMonitor{
+		l:                  l,
+		sfcClient:          sfcClient,
+		storage:            keyValueStorage,
+		validatorKeeper:    validatorKeeper,
+		delegateInfoKeeper: delegateInfoKeeper,
+		notify:             notify,
+		reported:           reported,
+		mu:                 sync.Mutex{},
+	}
+}
+
+// HandleStatusChange reports the validator once when its new status makes it slashed.
+func (m *SlashingMonitor) HandleStatusChange(ctx context.Context, event pkg.SFCChangedValidatorStatus) {
+	if !event.Status.IsDoubleSign() {
+		return
+	}
+	m.mu.Lock()
+	reported := m.reported[event.ValidatorID]
+	m.mu.Unlock()
+	if reported {
+		return
+	}
+
+	blockNumber := event.BlockNumber
+	slashed, err := m.sfcClient.IsSlashed(ctx, event.ValidatorID, &blockNumber)
+	if err != nil || !slashed {
+		return
+	}
+
+	report, err := m.report(ctx, event)
+	if err != nil {
+		m.l.Warnw("build slashing report error", "error", err, "validator_id", event.ValidatorID)
+		return
+	}
+
+	m.mu.Lock()
+	m.reported[event.ValidatorID] = true
+	m.saveLocked()
+	m.mu.Unlock()
+
+	m.l.Infow("validator slashed", "validator_id", report.ValidatorID, "slashed_amount", report.SlashedAmount, "refund_ratio", report.RefundRatio)
+	m.notify(ctx, report)
+}
+
+func (m *SlashingMonitor) saveLocked() {
+	if err := m.storage.Set(SlashingReportedStorageKey, m.reported); err != nil {
+		m.l.Warnw("save reported slashings error", "error", err)
+	}
+}
+
+func (m *SlashingMonitor) report(ctx context.Context, event pkg.SFCChangedValidatorStatus) (SlashingReport, error) {
+	validator, err := m.sfcClient.GetValidatorByID(ctx, event.ValidatorID)
+	if err != nil {
+		return SlashingReport{}, err
+	}
+	refundRatio, err := m.sfcClient.GetSlashingRefundRatio(ctx, event.ValidatorID, nil)
+	if err != nil {
+		return SlashingReport{}, err
+	}
+	totalSlashedStake, err := m.sfcClient.GetTotalSlashedStake(ctx)
+	if err != nil {
+		return SlashingReport{}, err
+	}
+
+	var (
+		delegators = append([]string{validator.Address}, m.delegateInfoKeeper.GetDelegators(event.ValidatorID)...)
+		impacts    = make([]DelegatorImpact, 0)
+		seen       = make(map[string]bool)
+	)
+	for _, delegator := range delegators {
+		if seen[strings.ToLower(delegator)] {
+			continue
+		}
+		seen[strings.ToLower(delegator)] = true
+
+		stake, err := m.sfcClient.GetStake(ctx, delegator, event.ValidatorID)
+		if err != nil || stake == 0 {
+			continue
+		}
+		impacts = append(impacts, DelegatorImpact{
+			Delegator: delegator,
+			Stake:     stake,
+			Penalty:   SlashingPenalty(stake, refundRatio),
+		})
+	}
+	sort.SliceStable(impacts, func(i, j int) bool {
+		return impacts[i].Penalty > impacts[j].Penalty
+	})
+
+	return SlashingReport{
+		ValidatorID:       event.ValidatorID,
+		ReceivedStake:     validator.TotalStake,
+		RefundRatio:       refundRatio,
+		SlashedAmount:     SlashingPenalty(validator.TotalStake, refundRatio),
+		TotalSlashedStake: totalSlashedStake,
+		Impacts:           impacts,
+		EventLog:          event.EventLog,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/stretchr/testify/suite"
+)
+
+type SlashingMonitorTestSuite struct {
+	suite.Suite
+}
+
+func TestSlashingMonitorTestSuite(t *testing.T) {
+	suite.Run(t, new(SlashingMonitorTestSuite))
+}
+
+func (ts *SlashingMonitorTestSuite) TestSlashingPenalty() {
+	assert := ts.Assert()
+
+	assert.Equal(float64(1000), SlashingPenalty(1000, 0))
+	assert.Equal(float64(250), SlashingPenalty(1000, 0.75))
+	assert.Equal(float64(0), SlashingPenalty(1000, 1))
+	assert.Equal(float64(0), SlashingPenalty(1000, 1.5))
+}
+
+func (ts *SlashingMonitorTestSuite) TestHandleStatusChange() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	backend := &fakeContractBackend{outputs: make(map[string][]byte)}
+	// status, deactivated time, deactivated epoch, received stake, created epoch, created time and auth
+	backend.setBigOutput("getValidator(uint256)", big.NewInt(int64(pkg.ValidatorStatusDoubleSign)), big.NewInt(0), big.NewInt(0), ftm(1000),
+		big.NewInt(1), big.NewInt(1650000000), big.NewInt(0xbb))
+	backend.setBigOutput("getSelfStake(uint256)", ftm(100))
+	backend.setBigOutput("slashingRefundRatio(uint256)", new(big.Int).Div(ftm(1), big.NewInt(4)))
+	backend.setBigOutput("totalSlashedStake()", ftm(5000))
+	backend.setBigOutput("getStake(address,uint256)", ftm(100))
+	backend.setOutput("isSlashed(uint256)", 0)
+	sfcClient, err := newFakeSFCClient(backend)
+	assert.NoError(err)
+
+	var (
+		storage            = newMemoryStorage()
+		delegateInfoKeeper = keeper.NewDelegateInfoKeeper()
+		reports            []SlashingReport
+	)
+	// the self stake is known twice, as the validator address and as a delegator
+	delegateInfoKeeper.AddDelegation(pkg.SFCDelegateInfo{Delegator: "0x00000000000000000000000000000000000000bb", ToValidatorID: 12, Amount: 100})
+	delegateInfoKeeper.AddDelegation(pkg.SFCDelegateInfo{Delegator: "0x00000000000000000000000000000000000000aa", ToValidatorID: 12, Amount: 100})
+	notify := func(ctx context.Context, report SlashingReport) {
+		reports = append(reports, report)
+	}
+	monitor := NewSlashingMonitor(sfcClient, storage, keeper.NewValidatorsKeeper(), delegateInfoKeeper, notify)
+	event := pkg.SFCChangedValidatorStatus{ValidatorID: 12, Status: pkg.ValidatorStatusDoubleSign, EventLog: pkg.EventLog{BlockNumber: 100}}
+
+	// only the double sign status is checked
+	monitor.HandleStatusChange(ctx, pkg.SFCChangedValidatorStatus{ValidatorID: 12, Status: pkg.ValidatorStatusOffline})
+	assert.Equal(0, len(backend.blocks))
+
+	// a validator not slashed at the event block is not reported
+	monitor.HandleStatusChange(ctx, event)
+	assert.Equal([]*big.Int{big.NewInt(100)}, backend.blocks)
+	assert.Equal(0, len(reports))
+
+	backend.setOutput("isSlashed(uint256)", 1)
+	monitor.HandleStatusChange(ctx, event)
+	assert.Equal(1, len(reports))
+	assert.Equal(uint64(12), reports[0].ValidatorID)
+	assert.Equal(float64(1000), reports[0].ReceivedStake)
+	assert.Equal(0.25, reports[0].RefundRatio)
+	assert.Equal(float64(750), reports[0].SlashedAmount)
+	assert.Equal(float64(5000), reports[0].TotalSlashedStake)
+	assert.Equal(2, len(reports[0].Impacts))
+	for _, impact := range reports[0].Impacts {
+		assert.Equal(float64(100), impact.Stake)
+		assert.Equal(float64(75), impact.Penalty)
+	}
+
+	// the validator is reported once, even after a restart
+	monitor.HandleStatusChange(ctx, event)
+	NewSlashingMonitor(sfcClient, storage, keeper.NewValidatorsKeeper(), delegateInfoKeeper, notify).HandleStatusChange(ctx, event)
+	assert.Equal(1, len(reports))
+}
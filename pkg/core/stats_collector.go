@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	NetworkStatsIntervalFlag    = "network_stats.interval"
+	NetworkStatsDailyReportFlag = "network_stats.daily_report"
+	NetworkStatsReportTimeFlag  = "network_stats.report_time"
+
+	DefaultNetworkStatsInterval   = 1 * time.Hour
+	DefaultNetworkStatsReportTime = "09:00"
+
+	// NetworkStatsRetention keeps enough snapshots for the weekly delta
+	NetworkStatsRetention = 8 * 24 * time.Hour
+	reportDateLayout      = "2006-01-02"
+)
+
+// NetworkStatsDelta is the change of the network stats since a previous snapshot.
+type NetworkStatsDelta struct {
+	Since            time.Duration
+	TotalStake       float64
+	TotalActiveStake float64
+	TotalSupply      float64
+	StakingRatio     float64
+}
+
+// NetworkReport holds the current network stats with their 24h and 7d deltas, a delta is nil
+// when there is no snapshot old enough.
+type NetworkReport struct {
+	Stats pkg.NetworkStats
+	Day   *NetworkStatsDelta
+	Week  *NetworkStatsDelta
+}
+
+func ComputeNetworkStatsDelta(current pkg.NetworkStats, previous pkg.NetworkStats) NetworkStatsDelta {
+	return NetworkStatsDelta{
+		Since:            time.Duration(current.Time-previous.Time) * time.Second,
+		TotalStake:       current.TotalStake - previous.TotalStake,
+		TotalActiveStake: current.TotalActiveStake - previous.TotalActiveStake,
+		TotalSupply:      current.TotalSupply - previous.TotalSupply,
+		StakingRatio:     current.StakingRatio() - previous.StakingRatio(),
+	}
+}
+
+// IsReportDue returns whether the daily report of the UTC day of now has not been sent yet
+// and the report time of the day has passed.
+func IsReportDue(now time.Time, reportTime time.Duration, lastReport string) bool {
+	now = now.UTC()
+	if now.Format(reportDateLayout) == lastReport {
+		return false
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return !now.Before(midnight.Add(reportTime))
+}
+
+// StatsCollector snapshots the network stats on a schedule and sends a daily report.
+type StatsCollector struct {
+	l           *zap.SugaredLogger
+	sfcClient   *SFCClient
+	store       *storage.NetworkStatsStore
+	notify      func(ctx context.Context, report NetworkReport)
+	interval    time.Duration
+	dailyReport bool
+	// reportTime is the time of the day of the report in UTC
+	reportTime time.Duration
+}
+
+func NewStatsCollector(sfcClient *SFCClient, store *storage.NetworkStatsStore, notify func(ctx context.Context, report NetworkReport)) *StatsCollector {
+	l := zap.S()
+
+	interval := DefaultNetworkStatsInterval
+	if viper.IsSet(NetworkStatsIntervalFlag) {
+		interval = viper.GetDuration(NetworkStatsIntervalFlag)
+	}
+	dailyReport := true
+	if viper.IsSet(NetworkStatsDailyReportFlag) {
+		dailyReport = viper.GetBool(NetworkStatsDailyReportFlag)
+	}
+	reportTimeStr := DefaultNetworkStatsReportTime
+	if viper.IsSet(NetworkStatsReportTimeFlag) {
+		reportTimeStr = viper.GetString(NetworkStatsReportTimeFlag)
+	}
+	reportTime, err := time.Parse("15:04", reportTimeStr)
+	if err != nil {
+		l.Warnw("invalid network report time, fallback default", "error", err, "report_time", reportTimeStr)
+		reportTime, _ = time.Parse("15:04", DefaultNetworkStatsReportTime)
+	}
+
+	return &StatsCollector{
+		l:           l,
+		sfcClient:   sfcClient,
+		store:       store,
+		notify:      notify,
+		interval:    interval,
+		dailyReport: dailyReport,
+		reportTime:  time.Duration(reportTime.Hour())*time.Hour + time.Duration(reportTime.Minute())*time.Minute,
+	}
+}
+
+// Run snapshots the network stats every interval until ctx is done.
+func (s *StatsCollector) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.collect(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *StatsCollector) collect(ctx context.Context, now time.Time) {
+	stats, err := s.sfcClient.GetNetworkStats(ctx)
+	if err != nil {
+		return
+	}
+	stats.Time = uint64(now.Unix())
+	if err := s.store.Add(stats); err != nil {
+		s.l.Warnw("save network stats error", "error", err)
+	}
+
+	if !s.dailyReport || !IsReportDue(now, s.reportTime, s.store.GetLastReport()) {
+		return
+	}
+	s.notify(ctx, s.BuildReport(stats))
+	if err := s.store.SetLastReport(now.UTC().Format(reportDateLayout)); err != nil {
+		s.l.Warnw("save network report date error", "error", err)
+	}
+}
+
+// BuildReport computes the 24h and 7d deltas of the stats from the stored snapshots.
+func (s *StatsCollector) BuildReport(stats pkg.NetworkStats) NetworkReport {
+	report := NetworkReport{Stats: stats}
+	if previous, ok := s.store.GetAt(stats.Time - uint64((24 * time.Hour).Seconds())); ok {
+		delta := ComputeNetworkStatsDelta(stats, previous)
+		report.Day = &delta
+	}
+	if previous, ok := s.store.GetAt(stats.Time - uint64((7 * 24 * time.Hour).Seconds())); ok {
+		delta := ComputeNetworkStatsDelta(stats, previous)
+		report.Week = &delta
+	}
+	return report
+}
+
+// GetListStats returns the stored snapshots sorted by time.
+func (s *StatsCollector) GetListStats() []pkg.NetworkStats {
+	return s.store.List()
+}
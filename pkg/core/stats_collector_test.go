@@ -0,0 +1,64 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type StatsCollectorTestSuite struct {
+	suite.Suite
+	collector *StatsCollector
+}
+
+func TestStatsCollectorTestSuite(t *testing.T) {
+	suite.Run(t, new(StatsCollectorTestSuite))
+}
+
+func (ts *StatsCollectorTestSuite) SetupTest() {
+	ts.collector = &StatsCollector{
+		l:     zap.S(),
+		store: storage.NewNetworkStatsStore(newMemoryStorage(), NetworkStatsRetention),
+	}
+}
+
+func (ts *StatsCollectorTestSuite) TestIsReportDue() {
+	assert := ts.Assert()
+	reportTime := 9 * time.Hour
+
+	now := time.Date(2021, 6, 1, 8, 59, 0, 0, time.UTC)
+	assert.False(IsReportDue(now, reportTime, ""))
+	now = time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	assert.True(IsReportDue(now, reportTime, "2021-05-31"))
+	assert.False(IsReportDue(now, reportTime, "2021-06-01"))
+	now = time.Date(2021, 6, 2, 0, 30, 0, 0, time.UTC)
+	assert.False(IsReportDue(now, reportTime, "2021-06-01"))
+}
+
+func (ts *StatsCollectorTestSuite) TestBuildReport() {
+	assert := ts.Assert()
+	day := uint64((24 * time.Hour).Seconds())
+	now := uint64(1000000000)
+
+	report := ts.collector.BuildReport(pkg.NetworkStats{Time: now, TotalStake: 100, TotalSupply: 1000})
+	assert.Nil(report.Day)
+	assert.Nil(report.Week)
+
+	ts.Require().NoError(ts.collector.store.Add(pkg.NetworkStats{Time: now - 9*day, TotalStake: 10, TotalSupply: 1000}))
+	ts.Require().NoError(ts.collector.store.Add(pkg.NetworkStats{Time: now - 7*day, TotalStake: 50, TotalSupply: 1000}))
+	ts.Require().NoError(ts.collector.store.Add(pkg.NetworkStats{Time: now - day, TotalStake: 80, TotalSupply: 1000}))
+	ts.Require().NoError(ts.collector.store.Add(pkg.NetworkStats{Time: now - day/2, TotalStake: 90, TotalSupply: 1000}))
+	assert.Equal(3, len(ts.collector.GetListStats()))
+
+	report = ts.collector.BuildReport(pkg.NetworkStats{Time: now, TotalStake: 100, TotalSupply: 1000})
+	assert.NotNil(report.Day)
+	assert.InDelta(20, report.Day.TotalStake, 1e-9)
+	assert.InDelta(0.02, report.Day.StakingRatio, 1e-9)
+	assert.Equal(24*time.Hour, report.Day.Since)
+	assert.NotNil(report.Week)
+	assert.InDelta(50, report.Week.TotalStake, 1e-9)
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	TokenWatchTokensFlag           = "token_watch.tokens"
+	TokenWatchDefaultThresholdFlag = "token_watch.default_threshold"
+
+	DefaultTokenThreshold = 100000
+	TokenResolveTimeout   = 30 * time.Second
+	// TokenResolveRetryInterval is the delay between two attempts to resolve a token
+	TokenResolveRetryInterval = 1 * time.Second
+
+	// TokenTransferStreamPrefix is followed by the lowercase token address in the stream names
+	TokenTransferStreamPrefix = "token_transfer_"
+)
+
+// TokenConfig is a token of the token watch config, the threshold is in token units.
+type TokenConfig struct {
+	Address   string
+	Threshold float64
+}
+
+// WatchedToken is a configured token resolved on chain.
+type WatchedToken struct {
+	pkg.TokenInfo
+	Threshold float64
+	contract  *contracts.ERC20
+}
+
+func NewWatchedToken(info pkg.TokenInfo, threshold float64) (WatchedToken, error) {
+	contract, err := contracts.NewERC20(etherCommon.HexToAddress(info.Address), nil)
+	if err != nil {
+		return WatchedToken{}, err
+	}
+	return WatchedToken{
+		TokenInfo: info,
+		Threshold: threshold,
+		contract:  contract,
+	}, nil
+}
+
+func (t WatchedToken) StreamName() string {
+	return TokenTransferStreamPrefix + strings.ToLower(t.Address)
+}
+
+func (t WatchedToken) DecodeTransfer(log types.Log) (pkg.Event, error) {
+	event, err := t.contract.ParseTransfer(log)
+	if err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return pkg.ToTokenTransferLog(event, t.TokenInfo), nil
+}
+
+// ParseTokenConfigs drops the invalid and duplicated token addresses and applies the default
+// threshold to the tokens without one.
+func ParseTokenConfigs(configs []TokenConfig, defaultThreshold float64) []TokenConfig {
+	l := zap.S()
+	var (
+		result = make([]TokenConfig, 0)
+		seen   = make(map[string]bool)
+	)
+	for _, item := range configs {
+		if !etherCommon.IsHexAddress(item.Address) {
+			l.Warnw("invalid token address, skip", "address", item.Address)
+			continue
+		}
+		address := etherCommon.HexToAddress(item.Address).Hex()
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+
+		if item.Threshold <= 0 {
+			item.Threshold = defaultThreshold
+		}
+		result = append(result, TokenConfig{Address: address, Threshold: item.Threshold})
+	}
+	return result
+}
+
+// LoadWatchedTokens resolves the symbol and the decimals of the configured tokens. The event types are
+// only registered at startup, so a token which cannot be resolved before ctx is done is an error.
+func LoadWatchedTokens(ctx context.Context, sfcClient *SFCClient) ([]WatchedToken, error) {
+	l := zap.S()
+
+	var configs = make([]TokenConfig, 0)
+	if err := viper.UnmarshalKey(TokenWatchTokensFlag, &configs); err != nil {
+		l.Errorw("error parse watched tokens", "error", err)
+		return nil, err
+	}
+	defaultThreshold := float64(DefaultTokenThreshold)
+	if viper.IsSet(TokenWatchDefaultThresholdFlag) {
+		defaultThreshold = viper.GetFloat64(TokenWatchDefaultThresholdFlag)
+	}
+
+	var tokens = make([]WatchedToken, 0)
+	for _, item := range ParseTokenConfigs(configs, defaultThreshold) {
+		info, err := resolveToken(ctx, sfcClient.GetTokenInfo, etherCommon.HexToAddress(item.Address))
+		if err != nil {
+			return nil, fmt.Errorf("resolve token %s: %w", item.Address, err)
+		}
+		token, err := NewWatchedToken(info, item.Threshold)
+		if err != nil {
+			return nil, fmt.Errorf("init token contract %s: %w", item.Address, err)
+		}
+		l.Infow("watch token transfers", "token", token.Address, "symbol", token.Symbol, "threshold", token.Threshold)
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// resolveToken retries the token info until it is read or ctx is done, the last error is returned.
+func resolveToken(ctx context.Context, getTokenInfo func(ctx context.Context, address etherCommon.Address) (pkg.TokenInfo, error), address etherCommon.Address) (pkg.TokenInfo, error) {
+	l := zap.S()
+	for {
+		info, err := getTokenInfo(ctx, address)
+		if err == nil {
+			return info, nil
+		}
+		l.Warnw("resolve token error, retry", "error", err, "token", address.Hex(), "retry_interval", TokenResolveRetryInterval)
+		select {
+		case <-ctx.Done():
+			return pkg.TokenInfo{}, err
+		case <-time.After(TokenResolveRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenWatchTestSuite struct {
+	suite.Suite
+}
+
+func TestTokenWatchTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenWatchTestSuite))
+}
+
+func (ts *TokenWatchTestSuite) TestParseTokenConfigs() {
+	assert := ts.Assert()
+
+	configs := ParseTokenConfigs([]TokenConfig{
+		{Address: "0x04068da6c83afcfa0e13ba15a6696662335d5b75", Threshold: 500000},
+		{Address: "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83"},
+		{Address: "0x04068DA6C83AFCFA0e13ba15A6696662335D5B75", Threshold: 1},
+		{Address: "not an address", Threshold: 1},
+	}, 100000)
+
+	assert.Equal([]TokenConfig{
+		{Address: "0x04068DA6C83AFCFA0e13ba15A6696662335D5B75", Threshold: 500000},
+		{Address: "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83", Threshold: 100000},
+	}, configs)
+}
+
+func (ts *TokenWatchTestSuite) TestDecodeTransfer() {
+	assert := ts.Assert()
+
+	info := pkg.TokenInfo{Address: "0x04068DA6C83AFCFA0e13ba15A6696662335D5B75", Symbol: "USDC", Decimals: 6}
+	token, err := NewWatchedToken(info, 100000)
+	assert.NoError(err)
+	assert.Equal("token_transfer_0x04068da6c83afcfa0e13ba15a6696662335d5b75", token.StreamName())
+
+	from := etherCommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := etherCommon.HexToAddress("0x00000000000000000000000000000000000000bb")
+	event, err := token.DecodeTransfer(types.Log{
+		Address: etherCommon.HexToAddress(info.Address),
+		Topics:  []etherCommon.Hash{contracts.TransferTopics, from.Hash(), to.Hash()},
+		Data:    etherCommon.LeftPadBytes(big.NewInt(250000000).Bytes(), 32),
+	})
+	assert.NoError(err)
+
+	item := event.(pkg.TokenTransferLog)
+	assert.Equal(info, item.Token)
+	assert.Equal(from.Hex(), item.From)
+	assert.Equal(to.Hex(), item.To)
+	assert.Equal(float64(250), item.Amount)
+
+	_, err = token.DecodeTransfer(types.Log{Topics: []etherCommon.Hash{contracts.TransferTopics}})
+	assert.Error(err)
+}
+
+func (ts *TokenWatchTestSuite) TestResolveTokenRetry() {
+	assert := ts.Assert()
+	address := etherCommon.HexToAddress("0x04068da6c83afcfa0e13ba15a6696662335d5b75")
+
+	var calls int
+	getTokenInfo := func(ctx context.Context, address etherCommon.Address) (pkg.TokenInfo, error) {
+		if calls++; calls == 1 {
+			return pkg.TokenInfo{}, errors.New("connection refused")
+		}
+		return pkg.TokenInfo{Address: address.Hex(), Symbol: "USDC", Decimals: 6}, nil
+	}
+	info, err := resolveToken(context.Background(), getTokenInfo, address)
+	assert.NoError(err)
+	assert.Equal(2, calls)
+	assert.Equal("USDC", info.Symbol)
+
+	// the token still unresolved when ctx is done is an error
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = resolveToken(ctx, func(ctx context.Context, address etherCommon.Address) (pkg.TokenInfo, error) {
+		return pkg.TokenInfo{}, errors.New("connection refused")
+	}, address)
+	assert.EqualError(err, "connection refused")
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/fetcher"
+	"go.uber.org/zap"
+)
+
+const (
+	SFCContractName   = "SFC"
+	ERC20ContractName = "ERC20"
+)
+
+type contractABI struct {
+	name string
+	abi  abi.ABI
+}
+
+// TxDecoder decodes the call data, the status and the events of transactions with the SFC and ERC20 ABIs.
+type TxDecoder struct {
+	l          *zap.SugaredLogger
+	nodeClient *fetcher.NodeClient
+	abis       []contractABI
+}
+
+func NewTxDecoder(nodeClient *fetcher.NodeClient) (*TxDecoder, error) {
+	// the SFC ABI is tried first
+	var definitions = []struct {
+		name string
+		abi  string
+	}{
+		{name: SFCContractName, abi: contracts.SFCABI},
+		{name: ERC20ContractName, abi: contracts.ERC20ABI},
+	}
+	var abis = make([]contractABI, 0, len(definitions))
+	for _, definition := range definitions {
+		parsed, err := abi.JSON(strings.NewReader(definition.abi))
+		if err != nil {
+			return nil, err
+		}
+		abis = append(abis, contractABI{name: definition.name, abi: parsed})
+	}
+
+	return &TxDecoder{
+		l:          zap.S(),
+		nodeClient: nodeClient,
+		abis:       abis,
+	}, nil
+}
+
+// Decode loads the transaction and its receipt and decodes them.
+func (d *TxDecoder) Decode(ctx context.Context, txHash string) (pkg.DecodedTx, error) {
+	tx, err := d.nodeClient.GetTransaction(ctx, etherCommon.HexToHash(txHash))
+	if err != nil {
+		d.l.Warnw("get tx by hash error", "error", err, "tx_hash", txHash)
+		return pkg.DecodedTx{}, err
+	}
+	return d.decodeTx(ctx, tx)
+}
+
+// DecodeBlockCalls decodes the transactions of the block sent to the contract, a transaction which
+// cannot be decoded is skipped.
+func (d *TxDecoder) DecodeBlockCalls(ctx context.Context, blockNumber uint64, contract etherCommon.Address) ([]pkg.DecodedTx, error) {
+	block, err := d.nodeClient.GetBlock(ctx, blockNumber)
+	if err != nil {
+		d.l.Warnw("get block error", "error", err, "block_number", blockNumber)
+		return nil, err
+	}
+
+	var result = make([]pkg.DecodedTx, 0)
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || *tx.To() != contract {
+			continue
+		}
+		decoded, err := d.decodeTx(ctx, tx)
+		if err != nil {
+			d.l.Warnw("decode tx error, skip", "error", err, "tx_hash", tx.Hash().Hex(), "block_number", blockNumber)
+			continue
+		}
+		result = append(result, decoded)
+	}
+	return result, nil
+}
+
+func (d *TxDecoder) decodeTx(ctx context.Context, tx *types.Transaction) (pkg.DecodedTx, error) {
+	receipt, err := d.nodeClient.GetTxReceipt(ctx, tx.Hash())
+	if err != nil {
+		d.l.Warnw("get tx receipt error", "error", err, "tx_hash", tx.Hash().Hex())
+		return pkg.DecodedTx{}, err
+	}
+	msg, err := tx.AsMessage(types.NewEIP155Signer(d.nodeClient.GetChainID()))
+	if err != nil {
+		d.l.Warnw("get tx as message error", "error", err, "tx_hash", tx.Hash().Hex())
+		return pkg.DecodedTx{}, err
+	}
+
+	result := pkg.DecodedTx{
+		From:    msg.From().Hex(),
+		Value:   pkg.WeiToFloat(tx.Value(), 18),
+		Success: receipt.Status == types.ReceiptStatusSuccessful,
+		GasUsed: receipt.GasUsed,
+		Events:  d.DecodeLogs(receipt.Logs),
+		EventLog: pkg.EventLog{
+			BlockNumber: receipt.BlockNumber.Uint64(),
+			BlockHash:   receipt.BlockHash.Hex(),
+			TxHash:      tx.Hash().Hex(),
+		},
+	}
+	if tx.To() != nil {
+		result.To = tx.To().Hex()
+	}
+	if call, err := d.DecodeInput(tx.Data()); err == nil {
+		result.DecodedCall = call
+	}
+	if !result.Success {
+		result.Error = d.getRevertReason(ctx, tx, msg.From(), result.BlockNumber)
+	}
+	return result, nil
+}
+
+// getRevertReason replays the failed call on the state before its block, the node returns the revert reason as error.
+func (d *TxDecoder) getRevertReason(ctx context.Context, tx *types.Transaction, from etherCommon.Address, blockNumber uint64) string {
+	_, err := d.nodeClient.GetBackend().CallContract(ctx, ethereum.CallMsg{
+		From:     from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}, new(big.Int).SetUint64(blockNumber-1))
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// DecodeInput returns the contract name, the method and the arguments of the call data.
+func (d *TxDecoder) DecodeInput(data []byte) (pkg.DecodedCall, error) {
+	if len(data) < 4 {
+		return pkg.DecodedCall{}, fmt.Errorf("call data too short")
+	}
+	for _, contract := range d.abis {
+		method, err := contract.abi.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		values, err := method.Inputs.UnpackValues(data[4:])
+		if err != nil {
+			return pkg.DecodedCall{}, err
+		}
+		var args = make([]pkg.DecodedArg, 0, len(values))
+		for i, value := range values {
+			args = append(args, pkg.DecodedArg{
+				Name:  method.Inputs[i].Name,
+				Type:  method.Inputs[i].Type.String(),
+				Value: formatArgValue(value),
+			})
+		}
+		return pkg.DecodedCall{
+			Contract: contract.name,
+			Method:   method.Name,
+			Args:     args,
+		}, nil
+	}
+	return pkg.DecodedCall{}, fmt.Errorf("unknown method selector %s", hexutil.Encode(data[:4]))
+}
+
+// DecodeLogs decodes the logs of the known events, the others are skipped.
+func (d *TxDecoder) DecodeLogs(logs []*types.Log) []pkg.DecodedEvent {
+	var result = make([]pkg.DecodedEvent, 0, len(logs))
+	for _, log := range logs {
+		if event, err := d.decodeLog(log); err == nil {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func (d *TxDecoder) decodeLog(log *types.Log) (pkg.DecodedEvent, error) {
+	if len(log.Topics) == 0 {
+		return pkg.DecodedEvent{}, fmt.Errorf("anonymous event")
+	}
+	for _, contract := range d.abis {
+		event, err := contract.abi.EventByID(log.Topics[0])
+		if err != nil {
+			continue
+		}
+
+		var (
+			values  = make(map[string]interface{})
+			indexed = make(abi.Arguments, 0)
+		)
+		for _, input := range event.Inputs {
+			if input.Indexed {
+				indexed = append(indexed, input)
+			}
+		}
+		if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+			continue
+		}
+		if len(log.Data) > 0 {
+			if err := event.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+				continue
+			}
+		}
+
+		var args = make([]pkg.DecodedArg, 0, len(event.Inputs))
+		for _, input := range event.Inputs {
+			args = append(args, pkg.DecodedArg{
+				Name:  input.Name,
+				Type:  input.Type.String(),
+				Value: formatArgValue(values[input.Name]),
+			})
+		}
+		return pkg.DecodedEvent{
+			Contract: contract.name,
+			Address:  log.Address.Hex(),
+			Name:     event.Name,
+			Args:     args,
+		}, nil
+	}
+	return pkg.DecodedEvent{}, fmt.Errorf("unknown event topic %s", log.Topics[0].Hex())
+}
+
+func formatArgValue(value interface{}) string {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case etherCommon.Address:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type TxDecoderTestSuite struct {
+	suite.Suite
+	decoder *TxDecoder
+}
+
+func TestTxDecoderTestSuite(t *testing.T) {
+	suite.Run(t, new(TxDecoderTestSuite))
+}
+
+func (ts *TxDecoderTestSuite) SetupSuite() {
+	decoder, err := NewTxDecoder(nil)
+	ts.Require().NoError(err)
+	ts.decoder = decoder
+}
+
+func (ts *TxDecoderTestSuite) TestDecodeInput() {
+	assert := ts.Assert()
+
+	data, err := ts.decoder.abis[0].abi.Pack("undelegate", big.NewInt(12), big.NewInt(3), big.NewInt(1000))
+	assert.NoError(err)
+	call, err := ts.decoder.DecodeInput(data)
+	assert.NoError(err)
+	assert.Equal(SFCContractName, call.Contract)
+	assert.Equal("undelegate", call.Method)
+	assert.Equal("undelegate(toValidatorID=12, wrID=3, amount=1000)", call.Call())
+	assert.Equal([]pkg.DecodedArg{
+		{Name: "toValidatorID", Type: "uint256", Value: "12"},
+		{Name: "wrID", Type: "uint256", Value: "3"},
+		{Name: "amount", Type: "uint256", Value: "1000"},
+	}, call.Args)
+
+	_, err = ts.decoder.DecodeInput([]byte{0xde, 0xad, 0xbe, 0xef})
+	assert.Error(err)
+	_, err = ts.decoder.DecodeInput(nil)
+	assert.Error(err)
+}
+
+func (ts *TxDecoderTestSuite) TestDecodeLogs() {
+	assert := ts.Assert()
+
+	from := etherCommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := etherCommon.HexToAddress("0x00000000000000000000000000000000000000bb")
+	token := etherCommon.HexToAddress("0x00000000000000000000000000000000000000cc")
+	events := ts.decoder.DecodeLogs([]*types.Log{
+		{
+			Address: token,
+			Topics:  []etherCommon.Hash{contracts.TransferTopics, from.Hash(), to.Hash()},
+			Data:    etherCommon.LeftPadBytes(big.NewInt(42).Bytes(), 32),
+		},
+		{
+			Address: token,
+			Topics:  []etherCommon.Hash{etherCommon.HexToHash("0x01")},
+		},
+	})
+
+	assert.Equal(1, len(events))
+	assert.Equal(ERC20ContractName, events[0].Contract)
+	assert.Equal("Transfer", events[0].Name)
+	assert.Equal(token.Hex(), events[0].Address)
+	assert.Equal([]pkg.DecodedArg{
+		{Name: "from", Type: "address", Value: from.Hex()},
+		{Name: "to", Type: "address", Value: to.Hex()},
+		{Name: "value", Type: "uint256", Value: "42"},
+	}, events[0].Args)
+}
+
+func (ts *TxDecoderTestSuite) TestIsNotableSFCCall() {
+	assert := ts.Assert()
+	usualMethods := map[string]bool{"delegate": true}
+
+	assert.False(IsNotableSFCCall(pkg.DecodedTx{DecodedCall: pkg.DecodedCall{Method: "delegate"}, Success: true}, usualMethods))
+	assert.True(IsNotableSFCCall(pkg.DecodedTx{DecodedCall: pkg.DecodedCall{Method: "delegate"}}, usualMethods))
+	assert.True(IsNotableSFCCall(pkg.DecodedTx{DecodedCall: pkg.DecodedCall{Method: "transferOwnership"}, Success: true}, usualMethods))
+	assert.True(IsNotableSFCCall(pkg.DecodedTx{Success: true}, usualMethods))
+}
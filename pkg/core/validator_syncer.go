@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	ValidatorSyncIntervalFlag    = "validator_sync_interval"
+	ValidatorStakeThresholdsFlag = "validator_stake_thresholds"
+	DefaultValidatorSyncInterval = 10 * time.Minute
+)
+
+type ValidatorChangeKind int
+
+const (
+	ValidatorWentOffline ValidatorChangeKind = iota
+	ValidatorBackOnline
+	ValidatorDeactivated
+	ValidatorStakeAbove
+	ValidatorStakeBelow
+)
+
+// ValidatorChange is a difference of a validator between two snapshots,
+// Threshold is the crossed stake threshold of the stake changes.
+type ValidatorChange struct {
+	Kind      ValidatorChangeKind
+	Previous  pkg.SFCValidator
+	Validator pkg.SFCValidator
+	Threshold float64
+}
+
+// DiffValidators compares two snapshots of the validators, sorted by validator ID.
+// New validators are ignored, they are notified by the CreatedValidator event.
+func DiffValidators(previous, current map[uint64]pkg.SFCValidator, stakeThresholds []float64) []ValidatorChange {
+	var changes = make([]ValidatorChange, 0)
+	for id, validator := range current {
+		prev, ok := previous[id]
+		if !ok {
+			continue
+		}
+		change := ValidatorChange{Previous: prev, Validator: validator}
+
+		if prev.DeactivatedEpoch == 0 && validator.DeactivatedEpoch != 0 {
+			change.Kind = ValidatorDeactivated
+			changes = append(changes, change)
+		}
+		if !prev.IsOffline && validator.IsOffline {
+			change.Kind = ValidatorWentOffline
+			changes = append(changes, change)
+		}
+		if prev.IsOffline && !validator.IsOffline {
+			change.Kind = ValidatorBackOnline
+			changes = append(changes, change)
+		}
+
+		// the stakes of a validator added by its CreatedValidator event are unknown, as well as
+		// the stakes a fetcher could not read
+		if prev.TotalStake == 0 || validator.TotalStake == 0 {
+			continue
+		}
+		for _, threshold := range stakeThresholds {
+			change.Threshold = threshold
+			if prev.TotalStake < threshold && validator.TotalStake >= threshold {
+				change.Kind = ValidatorStakeAbove
+				changes = append(changes, change)
+			}
+			if prev.TotalStake >= threshold && validator.TotalStake < threshold {
+				change.Kind = ValidatorStakeBelow
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Validator.ID != changes[j].Validator.ID {
+			return changes[i].Validator.ID < changes[j].Validator.ID
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+// mergeValidator updates a kept validator with its synced state, the status bits written by the
+// ChangedValidatorStatus events are kept while they agree with the synced flags.
+func mergeValidator(v *pkg.SFCValidator, synced pkg.SFCValidator) {
+	v.Address = synced.Address
+	v.CreatedTime, v.CreatedEpoch = synced.CreatedTime, synced.CreatedEpoch
+	v.DeactivatedTime, v.DeactivatedEpoch = synced.DeactivatedTime, synced.DeactivatedEpoch
+	if v.Status.IsActive() != synced.IsActive || v.Status.IsOffline() != synced.IsOffline {
+		v.Status = synced.Status
+	}
+	v.IsActive, v.IsOffline = synced.IsActive, synced.IsOffline
+	v.SelfStake, v.TotalStake = synced.SelfStake, synced.TotalStake
+}
+
+// ValidatorSyncer periodically refreshes the validators kept in memory and reports the changes.
+type ValidatorSyncer struct {
+	l               *zap.SugaredLogger
+	keeper          *keeper.ValidatorsKeeper
+	fetch           func(ctx context.Context) ([]pkg.SFCValidator, error)
+	onChange        func(ctx context.Context, change ValidatorChange)
+	interval        time.Duration
+	stakeThresholds []float64
+}
+
+func NewValidatorSyncer(validatorKeeper *keeper.ValidatorsKeeper, fetch func(ctx context.Context) ([]pkg.SFCValidator, error), onChange func(ctx context.Context, change ValidatorChange)) *ValidatorSyncer {
+	l := zap.S()
+
+	interval := DefaultValidatorSyncInterval
+	if viper.IsSet(ValidatorSyncIntervalFlag) {
+		interval = viper.GetDuration(ValidatorSyncIntervalFlag)
+	}
+
+	var stakeThresholds = make([]float64, 0)
+	if err := viper.UnmarshalKey(ValidatorStakeThresholdsFlag, &stakeThresholds); err != nil {
+		l.Errorw("error parse validator stake thresholds", "error", err)
+	}
+
+	return &ValidatorSyncer{
+		l:               l,
+		keeper:          validatorKeeper,
+		fetch:           fetch,
+		onChange:        onChange,
+		interval:        interval,
+		stakeThresholds: stakeThresholds,
+	}
+}
+
+// Run syncs the validators every interval until ctx is done.
+func (s *ValidatorSyncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				s.l.Warnw("sync validators error", "error", err)
+			}
+		}
+	}
+}
+
+// Sync fetches the validators, reports the changes and updates the keeper.
+func (s *ValidatorSyncer) Sync(ctx context.Context) error {
+	validators, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var current = make(map[uint64]pkg.SFCValidator)
+	for _, validator := range validators {
+		current[validator.ID] = validator
+	}
+	changes := DiffValidators(s.keeper.GetListValidators(), current, s.stakeThresholds)
+	for _, validator := range validators {
+		validator := validator
+		if !s.keeper.Update(validator.ID, func(v *pkg.SFCValidator) {
+			mergeValidator(v, validator)
+		}) {
+			s.keeper.Add(validator)
+		}
+	}
+
+	s.l.Debugw("sync validators", "length", len(validators), "changes", len(changes))
+	for _, change := range changes {
+		s.onChange(ctx, change)
+	}
+	return nil
+}
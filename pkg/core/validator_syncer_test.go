@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/keeper"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type DiffValidatorsTestSuite struct {
+	suite.Suite
+}
+
+func TestDiffValidatorsTestSuite(t *testing.T) {
+	suite.Run(t, new(DiffValidatorsTestSuite))
+}
+
+func (ts *DiffValidatorsTestSuite) TestStatusChanges() {
+	assert := ts.Assert()
+
+	previous := map[uint64]pkg.SFCValidator{
+		1: {ID: 1, IsActive: true, TotalStake: 100},
+		2: {ID: 2, IsOffline: true, TotalStake: 100},
+		3: {ID: 3, IsActive: true, TotalStake: 100},
+	}
+	current := map[uint64]pkg.SFCValidator{
+		1: {ID: 1, IsOffline: true, TotalStake: 100},
+		2: {ID: 2, IsActive: true, TotalStake: 100},
+		3: {ID: 3, DeactivatedEpoch: 10, TotalStake: 100},
+		4: {ID: 4, IsActive: true, TotalStake: 100},
+	}
+
+	changes := DiffValidators(previous, current, nil)
+	assert.Equal(3, len(changes))
+	assert.Equal(ValidatorWentOffline, changes[0].Kind)
+	assert.Equal(uint64(1), changes[0].Validator.ID)
+	assert.Equal(ValidatorBackOnline, changes[1].Kind)
+	assert.Equal(uint64(2), changes[1].Validator.ID)
+	assert.Equal(ValidatorDeactivated, changes[2].Kind)
+	assert.Equal(uint64(3), changes[2].Validator.ID)
+}
+
+func (ts *DiffValidatorsTestSuite) TestStakeThresholds() {
+	assert := ts.Assert()
+
+	previous := map[uint64]pkg.SFCValidator{
+		1: {ID: 1, TotalStake: 900},
+		2: {ID: 2, TotalStake: 1100},
+		3: {ID: 3},
+		4: {ID: 4, TotalStake: 1100},
+	}
+	current := map[uint64]pkg.SFCValidator{
+		1: {ID: 1, TotalStake: 1100},
+		2: {ID: 2, TotalStake: 900},
+		3: {ID: 3, TotalStake: 1100},
+		// the stake could not be read
+		4: {ID: 4},
+	}
+
+	changes := DiffValidators(previous, current, []float64{1000})
+	assert.Equal(2, len(changes))
+	assert.Equal(ValidatorStakeAbove, changes[0].Kind)
+	assert.Equal(float64(1000), changes[0].Threshold)
+	assert.Equal(ValidatorStakeBelow, changes[1].Kind)
+	assert.Equal(uint64(2), changes[1].Validator.ID)
+}
+
+func (ts *DiffValidatorsTestSuite) TestSyncKeepsEventStatus() {
+	assert := ts.Assert()
+
+	validatorKeeper := keeper.NewValidatorsKeeper()
+	validatorKeeper.Add(pkg.SFCValidator{ID: 1, Status: pkg.ValidatorStatusWithdrawn | pkg.ValidatorStatusDoubleSign, TotalStake: 100})
+	validatorKeeper.Add(pkg.SFCValidator{ID: 2, Status: pkg.ValidatorStatusOffline, IsOffline: true, TotalStake: 100})
+	syncer := &ValidatorSyncer{
+		l:      zap.S(),
+		keeper: validatorKeeper,
+		fetch: func(ctx context.Context) ([]pkg.SFCValidator, error) {
+			return []pkg.SFCValidator{
+				{ID: 1, Status: pkg.ValidatorStatusWithdrawn, TotalStake: 50},
+				{ID: 2, Status: pkg.ValidatorStatusOK, IsActive: true, TotalStake: 100},
+				{ID: 3, Status: pkg.ValidatorStatusOK, IsActive: true, TotalStake: 10},
+			}, nil
+		},
+		onChange: func(ctx context.Context, change ValidatorChange) {},
+	}
+	assert.NoError(syncer.Sync(context.Background()))
+
+	// the status of the event is kept while it agrees with the synced flags
+	validator := validatorKeeper.GetValidatorById(1)
+	assert.Equal(pkg.ValidatorStatusWithdrawn|pkg.ValidatorStatusDoubleSign, validator.Status)
+	assert.Equal(float64(50), validator.TotalStake)
+	validator = validatorKeeper.GetValidatorById(2)
+	assert.Equal(pkg.ValidatorStatusOK, validator.Status)
+	assert.True(validator.IsActive)
+	assert.Equal(3, len(validatorKeeper.GetListValidators()))
+}
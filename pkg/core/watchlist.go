@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	WatchlistAddressesFlag = "watchlist.addresses"
+	WatchlistChatIDFlag    = "watchlist.chat_id"
+	WatchlistStorageKey    = "watchlist"
+)
+
+// Watchlist holds the addresses whose activity is always notified regardless of the thresholds.
+// The addresses come from the config and from the API, only the latter are persisted.
+type Watchlist struct {
+	l       *zap.SugaredLogger
+	storage storage.KeyValueStorage
+
+	configAddresses map[string]bool
+	apiAddresses    map[string]bool
+	mu              sync.RWMutex
+}
+
+func NewWatchlist(keyValueStorage storage.KeyValueStorage) *Watchlist {
+	l := zap.S()
+
+	var configAddresses = make(map[string]bool)
+	for _, address := range viper.GetStringSlice(WatchlistAddressesFlag) {
+		configAddresses[strings.ToLower(address)] = true
+	}
+
+	var apiAddresses = make(map[string]bool)
+	if err := keyValueStorage.Get(WatchlistStorageKey, &apiAddresses); err != nil {
+		l.Debugw("no watchlist found in storage", "error", err)
+		apiAddresses = make(map[string]bool)
+	}
+
+	return &Watchlist{
+		l:               l,
+		storage:         keyValueStorage,
+		configAddresses: configAddresses,
+		apiAddresses:    apiAddresses,
+		mu:              sync.RWMutex{},
+	}
+}
+
+func (w *Watchlist) Contains(address string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	address = strings.ToLower(address)
+	return w.configAddresses[address] || w.apiAddresses[address]
+}
+
+func (w *Watchlist) ContainsAny(addresses ...string) bool {
+	for _, address := range addresses {
+		if w.Contains(address) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watchlist) Add(address string) error {
+	if !etherCommon.IsHexAddress(address) {
+		return fmt.Errorf("invalid address %s", address)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	address = strings.ToLower(address)
+	if w.apiAddresses[address] {
+		return nil
+	}
+	// the address is only watched once it is saved
+	w.apiAddresses[address] = true
+	if err := w.saveLocked(); err != nil {
+		delete(w.apiAddresses, address)
+		return err
+	}
+	return nil
+}
+
+// Remove removes an address added by the API, the addresses of the config cannot be removed.
+func (w *Watchlist) Remove(address string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	address = strings.ToLower(address)
+	if _, ok := w.apiAddresses[address]; !ok {
+		if w.configAddresses[address] {
+			return fmt.Errorf("address %s is watched by the config", address)
+		}
+		return fmt.Errorf("address %s not found", address)
+	}
+	delete(w.apiAddresses, address)
+	if err := w.saveLocked(); err != nil {
+		w.apiAddresses[address] = true
+		return err
+	}
+	return nil
+}
+
+func (w *Watchlist) saveLocked() error {
+	if err := w.storage.Set(WatchlistStorageKey, w.apiAddresses); err != nil {
+		w.l.Warnw("save watchlist error", "error", err)
+		return err
+	}
+	return nil
+}
+
+// List returns the watched addresses sorted.
+func (w *Watchlist) List() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var result = make([]string, 0, len(w.configAddresses)+len(w.apiAddresses))
+	for address := range w.configAddresses {
+		result = append(result, address)
+	}
+	for address := range w.apiAddresses {
+		if !w.configAddresses[address] {
+			result = append(result, address)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type WatchlistTestSuite struct {
+	suite.Suite
+	storage   *memoryStorage
+	watchlist *Watchlist
+}
+
+func TestWatchlistTestSuite(t *testing.T) {
+	suite.Run(t, new(WatchlistTestSuite))
+}
+
+func (ts *WatchlistTestSuite) SetupTest() {
+	ts.storage = newMemoryStorage()
+	ts.watchlist = &Watchlist{
+		l:               zap.S(),
+		storage:         ts.storage,
+		configAddresses: map[string]bool{"0x000000000000000000000000000000000000000a": true},
+		apiAddresses:    make(map[string]bool),
+	}
+}
+
+func (ts *WatchlistTestSuite) TestAddAndRemove() {
+	assert := ts.Assert()
+
+	address := "0x00000000000000000000000000000000000000Bb"
+	assert.NoError(ts.watchlist.Add(address))
+	assert.True(ts.watchlist.Contains("0x00000000000000000000000000000000000000bb"))
+	assert.True(ts.watchlist.ContainsAny("0x1", address))
+	assert.Contains(ts.storage.values, WatchlistStorageKey)
+	assert.Equal([]string{
+		"0x000000000000000000000000000000000000000a",
+		"0x00000000000000000000000000000000000000bb",
+	}, ts.watchlist.List())
+
+	assert.NoError(ts.watchlist.Remove(address))
+	assert.False(ts.watchlist.Contains(address))
+	assert.Error(ts.watchlist.Remove(address))
+}
+
+func (ts *WatchlistTestSuite) TestConfigAddresses() {
+	assert := ts.Assert()
+
+	assert.True(ts.watchlist.Contains("0x000000000000000000000000000000000000000A"))
+	assert.Error(ts.watchlist.Remove("0x000000000000000000000000000000000000000a"))
+	assert.Error(ts.watchlist.Add("not an address"))
+}
+
+// failingStorage fails to save any value.
+type failingStorage struct {
+	*memoryStorage
+}
+
+func (s failingStorage) Set(key string, value interface{}) error {
+	return errors.New("storage unavailable")
+}
+
+func (ts *WatchlistTestSuite) TestSaveError() {
+	assert := ts.Assert()
+
+	address := "0x00000000000000000000000000000000000000bb"
+	assert.NoError(ts.watchlist.Add(address))
+
+	// the watchlist is left unchanged when it cannot be saved
+	ts.watchlist.storage = failingStorage{ts.storage}
+	assert.Error(ts.watchlist.Add("0x00000000000000000000000000000000000000cc"))
+	assert.False(ts.watchlist.Contains("0x00000000000000000000000000000000000000cc"))
+	assert.Error(ts.watchlist.Remove(address))
+	assert.True(ts.watchlist.Contains(address))
+}
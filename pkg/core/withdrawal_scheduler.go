@@ -0,0 +1,222 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/quangkeu95/fantom-bot/pkg/storage"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	WithdrawalReminderIntervalFlag       = "withdrawal_reminder.check_interval"
+	WithdrawalReminderUnclaimedAfterFlag = "withdrawal_reminder.unclaimed_after"
+
+	DefaultWithdrawalReminderInterval       = 10 * time.Minute
+	DefaultWithdrawalReminderUnclaimedAfter = 7 * 24 * time.Hour
+
+	WithdrawalRequestsStorageKey = "withdrawal_requests"
+)
+
+type WithdrawalReminderKind int
+
+const (
+	WithdrawalMatured WithdrawalReminderKind = iota
+	WithdrawalUnclaimed
+)
+
+// PendingWithdrawal is a tracked withdrawal request, MaturedTime is the unix time it was found withdrawable.
+type PendingWithdrawal struct {
+	pkg.WithdrawalRequest
+	MaturedTime       uint64
+	RemindedUnclaimed bool
+}
+
+// IsWithdrawable returns whether both the withdrawal period time and epochs have passed since the request.
+func IsWithdrawable(request pkg.WithdrawalRequest, period pkg.WithdrawalPeriod, currentEpoch uint64, now time.Time) bool {
+	return uint64(now.Unix()) >= request.Time+period.Time && currentEpoch >= request.Epoch+period.Epochs
+}
+
+func withdrawalKey(delegator string, validatorID uint64, wrID uint64) string {
+	return fmt.Sprintf("%s:%d:%d", strings.ToLower(delegator), validatorID, wrID)
+}
+
+// WithdrawalScheduler records the withdrawal requests of the watched addresses and notifies when
+// they become withdrawable and when they stay unclaimed, the requests are persisted.
+type WithdrawalScheduler struct {
+	l              *zap.SugaredLogger
+	sfcClient      *SFCClient
+	storage        storage.KeyValueStorage
+	watchlist      *Watchlist
+	notify         func(ctx context.Context, withdrawal PendingWithdrawal, kind WithdrawalReminderKind)
+	interval       time.Duration
+	unclaimedAfter time.Duration
+
+	withdrawals map[string]PendingWithdrawal
+	mu          sync.Mutex
+}
+
+func NewWithdrawalScheduler(sfcClient *SFCClient, keyValueStorage storage.KeyValueStorage, watchlist *Watchlist, notify func(ctx context.Context, withdrawal PendingWithdrawal, kind WithdrawalReminderKind)) *WithdrawalScheduler {
+	l := zap.S()
+
+	interval := DefaultWithdrawalReminderInterval
+	if viper.IsSet(WithdrawalReminderIntervalFlag) {
+		interval = viper.GetDuration(WithdrawalReminderIntervalFlag)
+	}
+	unclaimedAfter := DefaultWithdrawalReminderUnclaimedAfter
+	if viper.IsSet(WithdrawalReminderUnclaimedAfterFlag) {
+		unclaimedAfter = viper.GetDuration(WithdrawalReminderUnclaimedAfterFlag)
+	}
+
+	var withdrawals = make(map[string]PendingWithdrawal)
+	if err := keyValueStorage.Get(WithdrawalRequestsStorageKey, &withdrawals); err != nil {
+		l.Debugw("no withdrawal requests found in storage", "error", err)
+		withdrawals = make(map[string]PendingWithdrawal)
+	}
+
+	return &WithdrawalScheduler{
+		l:              l,
+		sfcClient:      sfcClient,
+		storage:        keyValueStorage,
+		watchlist:      watchlist,
+		notify:         notify,
+		interval:       interval,
+		unclaimedAfter: unclaimedAfter,
+		withdrawals:    withdrawals,
+		mu:             sync.Mutex{},
+	}
+}
+
+func (s *WithdrawalScheduler) IsTracked(address string) bool {
+	return s.watchlist.Contains(address)
+}
+
+// Run checks the pending withdrawal requests until ctx is done.
+func (s *WithdrawalScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AddUndelegation records the withdrawal request created by the undelegation.
+func (s *WithdrawalScheduler) AddUndelegation(ctx context.Context, info pkg.SFCUndelegateInfo) {
+	s.Track(ctx, info.Delegator, info.ToValidatorID, info.WrID)
+}
+
+// Track records the withdrawal request read from the chain, a request already withdrawn is skipped.
+func (s *WithdrawalScheduler) Track(ctx context.Context, delegator string, validatorID uint64, wrID uint64) {
+	request, err := s.sfcClient.GetWithdrawalRequest(ctx, delegator, validatorID, wrID)
+	if err != nil {
+		return
+	}
+	if request.Amount == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := withdrawalKey(delegator, validatorID, wrID)
+	if _, ok := s.withdrawals[key]; ok {
+		return
+	}
+	s.withdrawals[key] = PendingWithdrawal{WithdrawalRequest: request}
+	s.l.Infow("track withdrawal request", "delegator", delegator, "validator_id", validatorID, "wr_id", wrID)
+	s.saveLocked()
+}
+
+// Remove forgets a withdrawn request.
+func (s *WithdrawalScheduler) Remove(delegator string, validatorID uint64, wrID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := withdrawalKey(delegator, validatorID, wrID)
+	if _, ok := s.withdrawals[key]; !ok {
+		return
+	}
+	delete(s.withdrawals, key)
+	s.saveLocked()
+}
+
+func (s *WithdrawalScheduler) check(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var withdrawals = make([]PendingWithdrawal, 0, len(s.withdrawals))
+	for _, withdrawal := range s.withdrawals {
+		withdrawals = append(withdrawals, withdrawal)
+	}
+	s.mu.Unlock()
+	if len(withdrawals) == 0 {
+		return
+	}
+
+	period, err := s.sfcClient.GetWithdrawalPeriod(ctx)
+	if err != nil {
+		return
+	}
+	currentEpoch, err := s.sfcClient.GetCurrentEpoch(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, withdrawal := range withdrawals {
+		// the request is removed when it has been withdrawn without the Withdrawn event being seen
+		request, err := s.sfcClient.GetWithdrawalRequest(ctx, withdrawal.Delegator, withdrawal.ValidatorID, withdrawal.WrID)
+		if err == nil && request.Amount == 0 {
+			s.Remove(withdrawal.Delegator, withdrawal.ValidatorID, withdrawal.WrID)
+			continue
+		}
+
+		var kind WithdrawalReminderKind
+		switch {
+		case withdrawal.MaturedTime == 0 && IsWithdrawable(withdrawal.WithdrawalRequest, period, currentEpoch, now):
+			withdrawal.MaturedTime = uint64(now.Unix())
+			kind = WithdrawalMatured
+		case withdrawal.MaturedTime != 0 && !withdrawal.RemindedUnclaimed &&
+			now.Sub(time.Unix(int64(withdrawal.MaturedTime), 0)) >= s.unclaimedAfter:
+			withdrawal.RemindedUnclaimed = true
+			kind = WithdrawalUnclaimed
+		default:
+			continue
+		}
+
+		s.mu.Lock()
+		key := withdrawalKey(withdrawal.Delegator, withdrawal.ValidatorID, withdrawal.WrID)
+		if _, ok := s.withdrawals[key]; ok {
+			s.withdrawals[key] = withdrawal
+			s.saveLocked()
+		}
+		s.mu.Unlock()
+		s.notify(ctx, withdrawal, kind)
+	}
+}
+
+func (s *WithdrawalScheduler) saveLocked() {
+	if err := s.storage.Set(WithdrawalRequestsStorageKey, s.withdrawals); err != nil {
+		s.l.Warnw("save withdrawal requests error", "error", err)
+	}
+}
+
+// GetCalendar returns the pending withdrawal requests sorted by request time.
+func (s *WithdrawalScheduler) GetCalendar() []PendingWithdrawal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result = make([]PendingWithdrawal, 0, len(s.withdrawals))
+	for _, withdrawal := range s.withdrawals {
+		result = append(result, withdrawal)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time < result[j].Time
+	})
+	return result
+}
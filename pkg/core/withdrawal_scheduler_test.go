@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type WithdrawalSchedulerTestSuite struct {
+	suite.Suite
+	backend   *fakeContractBackend
+	scheduler *WithdrawalScheduler
+	reminders []WithdrawalReminderKind
+}
+
+func TestWithdrawalSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(WithdrawalSchedulerTestSuite))
+}
+
+func (ts *WithdrawalSchedulerTestSuite) SetupTest() {
+	ts.reminders = nil
+	ts.backend = &fakeContractBackend{outputs: make(map[string][]byte)}
+	sfcClient, err := newFakeSFCClient(ts.backend)
+	ts.Assert().NoError(err)
+
+	ts.scheduler = &WithdrawalScheduler{
+		l:         zap.S(),
+		sfcClient: sfcClient,
+		storage:   newMemoryStorage(),
+		notify: func(ctx context.Context, withdrawal PendingWithdrawal, kind WithdrawalReminderKind) {
+			ts.reminders = append(ts.reminders, kind)
+		},
+		unclaimedAfter: 7 * 24 * time.Hour,
+		withdrawals:    make(map[string]PendingWithdrawal),
+	}
+}
+
+func (ts *WithdrawalSchedulerTestSuite) TestIsWithdrawable() {
+	assert := ts.Assert()
+
+	request := pkg.WithdrawalRequest{Epoch: 100, Time: 1000000}
+	period := pkg.WithdrawalPeriod{Epochs: 3, Time: 7 * 24 * 60 * 60}
+	matureTime := time.Unix(int64(request.Time+period.Time), 0)
+
+	assert.False(IsWithdrawable(request, period, 103, matureTime.Add(-time.Second)))
+	assert.False(IsWithdrawable(request, period, 102, matureTime))
+	assert.True(IsWithdrawable(request, period, 103, matureTime))
+	assert.True(IsWithdrawable(request, period, 110, matureTime.Add(time.Hour)))
+}
+
+func (ts *WithdrawalSchedulerTestSuite) TestReminders() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	// a request of 100 FTM made at epoch 100 with a withdrawal period of 3 epochs and 7 days
+	requestTime := time.Unix(1000000000, 0)
+	matureTime := requestTime.Add(7 * 24 * time.Hour)
+	ts.backend.setBigOutput("getWithdrawalRequest(address,uint256,uint256)", big.NewInt(100), big.NewInt(requestTime.Unix()), ftm(100))
+	ts.backend.setOutput("withdrawalPeriodEpochs()", 3)
+	ts.backend.setOutput("withdrawalPeriodTime()", int64(7*24*time.Hour/time.Second))
+	ts.backend.setOutput("currentEpoch()", 103)
+	ts.scheduler.Track(ctx, "0x00000000000000000000000000000000000000aa", 12, 1)
+	assert.Equal(1, len(ts.scheduler.GetCalendar()))
+
+	ts.scheduler.check(ctx, matureTime.Add(-time.Hour))
+	assert.Equal(0, len(ts.reminders))
+
+	ts.scheduler.check(ctx, matureTime)
+	assert.Equal([]WithdrawalReminderKind{WithdrawalMatured}, ts.reminders)
+	assert.Equal(uint64(matureTime.Unix()), ts.scheduler.GetCalendar()[0].MaturedTime)
+
+	ts.scheduler.check(ctx, matureTime.Add(6*24*time.Hour))
+	assert.Equal(1, len(ts.reminders))
+
+	// the unclaimed reminder is sent once, unclaimed_after the request matured
+	ts.scheduler.check(ctx, matureTime.Add(7*24*time.Hour))
+	assert.Equal([]WithdrawalReminderKind{WithdrawalMatured, WithdrawalUnclaimed}, ts.reminders)
+
+	ts.scheduler.check(ctx, matureTime.Add(30*24*time.Hour))
+	assert.Equal(2, len(ts.reminders))
+
+	// the reminders are persisted
+	var withdrawals map[string]PendingWithdrawal
+	assert.NoError(ts.scheduler.storage.Get(WithdrawalRequestsStorageKey, &withdrawals))
+	assert.Equal(1, len(withdrawals))
+	for _, withdrawal := range withdrawals {
+		assert.True(withdrawal.RemindedUnclaimed)
+	}
+}
+
+func (ts *WithdrawalSchedulerTestSuite) TestRemoveWithdrawn() {
+	assert := ts.Assert()
+	ctx := context.Background()
+
+	requestTime := time.Unix(1000000000, 0)
+	ts.backend.setBigOutput("getWithdrawalRequest(address,uint256,uint256)", big.NewInt(100), big.NewInt(requestTime.Unix()), ftm(100))
+	ts.backend.setOutput("withdrawalPeriodEpochs()", 3)
+	ts.backend.setOutput("withdrawalPeriodTime()", int64(7*24*time.Hour/time.Second))
+	ts.backend.setOutput("currentEpoch()", 103)
+	ts.scheduler.Track(ctx, "0x00000000000000000000000000000000000000aa", 12, 1)
+
+	// the request withdrawn without the Withdrawn event being seen is removed without a reminder
+	ts.backend.setOutput("getWithdrawalRequest(address,uint256,uint256)", 100, requestTime.Unix(), 0)
+	ts.scheduler.check(ctx, requestTime.Add(30*24*time.Hour))
+	assert.Equal(0, len(ts.reminders))
+	assert.Equal(0, len(ts.scheduler.GetCalendar()))
+
+	var withdrawals map[string]PendingWithdrawal
+	assert.NoError(ts.scheduler.storage.Get(WithdrawalRequestsStorageKey, &withdrawals))
+	assert.Equal(0, len(withdrawals))
+}
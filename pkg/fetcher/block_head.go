@@ -0,0 +1,41 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/quangkeu95/fantom-bot/pkg"
+)
+
+// BlockHeadResponse is the raw block header returned by the node.
+// The header is not decoded into types.Header because Opera block hashes
+// cannot be recomputed from the header fields.
+type BlockHeadResponse struct {
+	Number     hexutil.Uint64   `json:"number"`
+	Hash       etherCommon.Hash `json:"hash"`
+	ParentHash etherCommon.Hash `json:"parentHash"`
+	Time       hexutil.Uint64   `json:"timestamp"`
+}
+
+func (r *BlockHeadResponse) ToBlockHead() pkg.BlockHead {
+	return pkg.BlockHead{
+		Number:     uint64(r.Number),
+		Hash:       r.Hash.Hex(),
+		ParentHash: r.ParentHash.Hex(),
+		Time:       uint64(r.Time),
+	}
+}
+
+func getBlockHead(ctx context.Context, client *rpc.Client, method string, arg interface{}) (pkg.BlockHead, error) {
+	var resp *BlockHeadResponse
+	if err := client.CallContext(ctx, &resp, method, arg, false); err != nil {
+		return pkg.BlockHead{}, err
+	}
+	if resp == nil {
+		return pkg.BlockHead{}, fmt.Errorf("block %v not found", arg)
+	}
+	return resp.ToBlockHead(), nil
+}
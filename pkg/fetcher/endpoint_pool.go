@@ -0,0 +1,466 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	RPCEndpointFlag         = "fantom_chain.rpc_endpoint"
+	RPCEndpointsFlag        = "fantom_chain.rpc_endpoints"
+	WsEndpointFlag          = "fantom_chain.ws_endpoint"
+	WsEndpointsFlag         = "fantom_chain.ws_endpoints"
+	HealthCheckIntervalFlag = "fantom_chain.health_check_interval"
+
+	DefaultHealthCheckInterval = 15 * time.Second
+
+	EndpointDialTimeout  = 10 * time.Second
+	EndpointCheckTimeout = 5 * time.Second
+	// MaxEndpointErrors is the number of consecutive errors after which an endpoint is redialled
+	MaxEndpointErrors = 3
+
+	// score penalties of an endpoint, a connected endpoint always scores at least 1
+	maxEndpointScore    = 1000
+	lagBlockPenalty     = 50
+	endpointErrPenalty  = 200
+	latencyAverageRatio = 4
+)
+
+var (
+	ErrNoEndpoint         = errors.New("no endpoint available")
+	ErrSubscriptionClosed = errors.New("subscription closed")
+)
+
+// EndpointStats is the health of an endpoint measured by the health checks and the routed calls.
+type EndpointStats struct {
+	Connected bool
+	// Latency is the moving average of the health check latency
+	Latency time.Duration
+	// Errors is the number of consecutive errors
+	Errors int
+	Height uint64
+}
+
+// ScoreEndpoint rates an endpoint, the higher the better: a disconnected or failing endpoint scores 0,
+// every millisecond of latency, block behind maxHeight and consecutive error lowers the score.
+func ScoreEndpoint(stats EndpointStats, maxHeight uint64) float64 {
+	if !stats.Connected || stats.Errors >= MaxEndpointErrors {
+		return 0
+	}
+	score := float64(maxEndpointScore - stats.Latency.Milliseconds())
+	if stats.Height < maxHeight {
+		score -= float64((maxHeight - stats.Height) * lagBlockPenalty)
+	}
+	score -= float64(stats.Errors * endpointErrPenalty)
+	if score < 1 {
+		return 1
+	}
+	return score
+}
+
+// isEndpointError returns whether the error comes from the endpoint itself rather than from the request:
+// an error answered by the node or a missing item does not count against the endpoint.
+func isEndpointError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || err == ethereum.NotFound {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+type Endpoint struct {
+	Name string
+	URL  string
+
+	client    *ethclient.Client
+	rpcClient *rpc.Client
+	stats     EndpointStats
+	mu        sync.RWMutex
+}
+
+func (e *Endpoint) ETHClient() *ethclient.Client {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.client
+}
+
+func (e *Endpoint) RPCClient() *rpc.Client {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rpcClient
+}
+
+func (e *Endpoint) Stats() EndpointStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	stats := e.stats
+	stats.Connected = e.rpcClient != nil
+	return stats
+}
+
+// GetLatestBlock returns the latest block number of this endpoint only.
+func (e *Endpoint) GetLatestBlock(ctx context.Context) (uint64, error) {
+	client := e.ETHClient()
+	if client == nil {
+		return 0, ErrNoEndpoint
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+// dial connects the endpoint and replaces the previous connection.
+func (e *Endpoint) dial(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, EndpointDialTimeout)
+	defer cancel()
+	rpcClient, err := rpc.DialContext(ctx, e.URL)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	previous := e.rpcClient
+	e.rpcClient, e.client = rpcClient, ethclient.NewClient(rpcClient)
+	e.stats.Errors = 0
+	e.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+func (e *Endpoint) recordCall(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.stats.Errors++
+		return
+	}
+	e.stats.Errors = 0
+}
+
+func (e *Endpoint) recordCheck(latency time.Duration, height uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.Errors = 0
+	e.stats.Height = height
+	if e.stats.Latency == 0 {
+		e.stats.Latency = latency
+		return
+	}
+	e.stats.Latency = (e.stats.Latency*(latencyAverageRatio-1) + latency) / latencyAverageRatio
+}
+
+// recordDrop marks the endpoint as failing after a dropped subscription, so that it is redialled
+// by the next health check and ranked last meanwhile.
+func (e *Endpoint) recordDrop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.Errors = MaxEndpointErrors
+}
+
+// EndpointPool holds several endpoints of the same kind, health-scores them and routes the calls
+// to the best one.
+type EndpointPool struct {
+	l         *zap.SugaredLogger
+	name      string
+	endpoints []*Endpoint
+	interval  time.Duration
+}
+
+// NewEndpointPool dials every endpoint, it fails only when none of them can be dialled.
+func NewEndpointPool(name string, urls []string) (*EndpointPool, error) {
+	l := zap.S()
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no %s endpoint configured", name)
+	}
+	interval := DefaultHealthCheckInterval
+	if viper.IsSet(HealthCheckIntervalFlag) {
+		interval = viper.GetDuration(HealthCheckIntervalFlag)
+	}
+
+	pool := &EndpointPool{
+		l:        l,
+		name:     name,
+		interval: interval,
+	}
+	for _, url := range urls {
+		if err := validation.Validate(url, is.URL); err != nil {
+			return nil, err
+		}
+		pool.endpoints = append(pool.endpoints, &Endpoint{
+			Name: fmt.Sprintf("%s %s", name, url),
+			URL:  url,
+			mu:   sync.RWMutex{},
+		})
+	}
+
+	ctx := context.Background()
+	var lastErr error
+	for _, endpoint := range pool.endpoints {
+		if err := endpoint.dial(ctx); err != nil {
+			l.Warnw("dial endpoint error", "error", err, "endpoint", endpoint.Name)
+			lastErr = err
+			continue
+		}
+		pool.check(ctx, endpoint)
+	}
+	if len(pool.Ranked()) == 0 {
+		return nil, lastErr
+	}
+	return pool, nil
+}
+
+// endpointURLs returns the endpoints of the list flag followed by the one of the single endpoint flag.
+func endpointURLs(listFlag string, flag string) []string {
+	var urls = make([]string, 0)
+	if viper.IsSet(listFlag) {
+		urls = append(urls, viper.GetStringSlice(listFlag)...)
+	}
+	if url := viper.GetString(flag); url != "" {
+		for _, item := range urls {
+			if item == url {
+				return urls
+			}
+		}
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// Backend returns a contract backend which routes the calls of the bindings through the pool.
+func (p *EndpointPool) Backend() bind.ContractBackend {
+	return &poolBackend{pool: p}
+}
+
+func (p *EndpointPool) Endpoints() []*Endpoint {
+	return p.endpoints
+}
+
+// Ranked returns the connected endpoints sorted from the best score.
+func (p *EndpointPool) Ranked() []*Endpoint {
+	var (
+		list      = make([]*Endpoint, 0, len(p.endpoints))
+		stats     = make(map[*Endpoint]EndpointStats)
+		maxHeight uint64
+	)
+	for _, endpoint := range p.endpoints {
+		s := endpoint.Stats()
+		if !s.Connected {
+			continue
+		}
+		if s.Height > maxHeight {
+			maxHeight = s.Height
+		}
+		stats[endpoint] = s
+		list = append(list, endpoint)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return ScoreEndpoint(stats[list[i]], maxHeight) > ScoreEndpoint(stats[list[j]], maxHeight)
+	})
+	return list
+}
+
+// Best returns the endpoint with the best score.
+func (p *EndpointPool) Best() (*Endpoint, error) {
+	ranked := p.Ranked()
+	if len(ranked) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	return ranked[0], nil
+}
+
+// Run checks the health of every endpoint until ctx is done, the failing ones are redialled.
+func (p *EndpointPool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	var current string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, endpoint := range p.endpoints {
+			p.check(ctx, endpoint)
+		}
+		if best, err := p.Best(); err == nil && best.URL != current {
+			p.l.Infow("route calls to endpoint", "endpoint", best.Name, "previous", current)
+			current = best.URL
+		}
+	}
+}
+
+func (p *EndpointPool) check(ctx context.Context, endpoint *Endpoint) {
+	if stats := endpoint.Stats(); !stats.Connected || stats.Errors >= MaxEndpointErrors {
+		if err := endpoint.dial(ctx); err != nil {
+			p.l.Warnw("redial endpoint error", "error", err, "endpoint", endpoint.Name)
+			return
+		}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, EndpointCheckTimeout)
+	defer cancel()
+	start := time.Now()
+	height, err := endpoint.GetLatestBlock(checkCtx)
+	if err != nil {
+		p.l.Warnw("check endpoint error", "error", err, "endpoint", endpoint.Name)
+		endpoint.recordCall(err)
+		return
+	}
+	endpoint.recordCheck(time.Since(start), height)
+}
+
+// Do runs the call on the best endpoint and fails over to the next ones while the endpoint is at fault.
+func (p *EndpointPool) Do(ctx context.Context, call func(endpoint *Endpoint) error) error {
+	var err = ErrNoEndpoint
+	for _, endpoint := range p.Ranked() {
+		err = call(endpoint)
+		if !isEndpointError(ctx, err) {
+			endpoint.recordCall(nil)
+			return err
+		}
+		endpoint.recordCall(err)
+		p.l.Debugw("endpoint call error, fail over", "error", err, "endpoint", endpoint.Name)
+	}
+	return err
+}
+
+// Subscribe subscribes on the best endpoint and transparently subscribes again on the next best one
+// whenever the subscription drops, an error is only sent once every endpoint has failed.
+func (p *EndpointPool) Subscribe(ctx context.Context, subscribe func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error)) (EndpointSubscription, error) {
+	return p.newSubscription(ctx, subscribe, true)
+}
+
+// SubscribeOnce subscribes on the best endpoint, a drop is sent to the caller which can replay
+// the missed items before subscribing again, the new subscription then goes to the next best endpoint.
+func (p *EndpointPool) SubscribeOnce(ctx context.Context, subscribe func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error)) (EndpointSubscription, error) {
+	return p.newSubscription(ctx, subscribe, false)
+}
+
+func (p *EndpointPool) newSubscription(ctx context.Context, subscribe func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error), failover bool) (EndpointSubscription, error) {
+	endpoint, client, sub, err := p.subscribe(ctx, subscribe)
+	if err != nil {
+		return nil, err
+	}
+	s := &poolSubscription{
+		pool:      p,
+		subscribe: subscribe,
+		failover:  failover,
+		endpoint:  endpoint,
+		errCh:     make(chan error, 1),
+		quit:      make(chan struct{}),
+	}
+	go s.loop(ctx, endpoint, client, sub)
+	return s, nil
+}
+
+// subscribe subscribes on the best endpoint which accepts the subscription, it also returns the client
+// of the endpoint at the time of the subscription.
+func (p *EndpointPool) subscribe(ctx context.Context, subscribe func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error)) (*Endpoint, *rpc.Client, ethereum.Subscription, error) {
+	var err = ErrNoEndpoint
+	for _, endpoint := range p.Ranked() {
+		client := endpoint.RPCClient()
+		var sub ethereum.Subscription
+		sub, err = subscribe(ctx, endpoint)
+		if err == nil {
+			return endpoint, client, sub, nil
+		}
+		if isEndpointError(ctx, err) {
+			endpoint.recordCall(err)
+		}
+		p.l.Debugw("subscribe endpoint error", "error", err, "endpoint", endpoint.Name)
+	}
+	return nil, nil, nil, err
+}
+
+// EndpointSubscription is a subscription of the pool which tells the endpoint serving it.
+type EndpointSubscription interface {
+	ethereum.Subscription
+	Endpoint() *Endpoint
+}
+
+// poolSubscription follows a subscription of the pool across its endpoints.
+type poolSubscription struct {
+	pool      *EndpointPool
+	subscribe func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error)
+	failover  bool
+	endpoint  *Endpoint
+	errCh     chan error
+	quit      chan struct{}
+	once      sync.Once
+	mu        sync.RWMutex
+}
+
+func (s *poolSubscription) Err() <-chan error {
+	return s.errCh
+}
+
+// Endpoint returns the endpoint the subscription is currently on.
+func (s *poolSubscription) Endpoint() *Endpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.endpoint
+}
+
+func (s *poolSubscription) Unsubscribe() {
+	s.once.Do(func() {
+		close(s.quit)
+	})
+}
+
+func (s *poolSubscription) loop(ctx context.Context, endpoint *Endpoint, client *rpc.Client, sub ethereum.Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+			return
+		case <-s.quit:
+			sub.Unsubscribe()
+			return
+		case err := <-sub.Err():
+			// a nil error means the client of the endpoint has been closed
+			if err == nil {
+				err = ErrSubscriptionClosed
+			}
+			// the client is replaced when the endpoint is redialled, closing the previous client
+			// ends its subscriptions without the endpoint being at fault
+			if endpoint.RPCClient() == client {
+				endpoint.recordDrop()
+				s.pool.l.Warnw("subscription dropped", "error", err, "endpoint", endpoint.Name)
+			} else {
+				s.pool.l.Infow("subscription closed by redial", "endpoint", endpoint.Name)
+			}
+			if s.failover {
+				var subErr error
+				if endpoint, client, sub, subErr = s.pool.subscribe(ctx, s.subscribe); subErr == nil {
+					s.mu.Lock()
+					s.endpoint = endpoint
+					s.mu.Unlock()
+					s.pool.l.Infow("subscription moved to endpoint", "endpoint", endpoint.Name)
+					continue
+				}
+				err = subErr
+			}
+			s.errCh <- err
+			return
+		}
+	}
+}
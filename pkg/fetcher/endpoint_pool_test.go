@@ -0,0 +1,184 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type EndpointPoolTestSuite struct {
+	suite.Suite
+}
+
+func TestEndpointPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(EndpointPoolTestSuite))
+}
+
+func (ts *EndpointPoolTestSuite) newEndpoint(url string, stats EndpointStats) *Endpoint {
+	endpoint := &Endpoint{Name: url, URL: url, stats: stats, mu: sync.RWMutex{}}
+	if stats.Connected {
+		endpoint.rpcClient = rpc.DialInProc(rpc.NewServer())
+	}
+	return endpoint
+}
+
+func (ts *EndpointPoolTestSuite) TestScoreEndpoint() {
+	assert := ts.Assert()
+
+	healthy := EndpointStats{Connected: true, Latency: 100 * time.Millisecond, Height: 100}
+	assert.Equal(float64(900), ScoreEndpoint(healthy, 100))
+	assert.Equal(float64(800), ScoreEndpoint(healthy, 102))
+
+	healthy.Errors = 1
+	assert.Equal(float64(700), ScoreEndpoint(healthy, 100))
+	healthy.Errors = MaxEndpointErrors
+	assert.Equal(float64(0), ScoreEndpoint(healthy, 100))
+
+	assert.Equal(float64(0), ScoreEndpoint(EndpointStats{Height: 100}, 100))
+	// a connected endpoint always ranks above a failing one
+	assert.Equal(float64(1), ScoreEndpoint(EndpointStats{Connected: true, Latency: 2 * time.Second}, 100))
+}
+
+func (ts *EndpointPoolTestSuite) TestRanked() {
+	assert := ts.Assert()
+
+	slow := ts.newEndpoint("slow", EndpointStats{Connected: true, Latency: 300 * time.Millisecond, Height: 100})
+	lagging := ts.newEndpoint("lagging", EndpointStats{Connected: true, Latency: 50 * time.Millisecond, Height: 90})
+	fast := ts.newEndpoint("fast", EndpointStats{Connected: true, Latency: 50 * time.Millisecond, Height: 100})
+	down := ts.newEndpoint("down", EndpointStats{})
+	pool := &EndpointPool{l: zap.S(), name: "rpc", endpoints: []*Endpoint{slow, lagging, down, fast}}
+
+	assert.Equal([]*Endpoint{fast, slow, lagging}, pool.Ranked())
+
+	fast.recordDrop()
+	best, err := pool.Best()
+	assert.NoError(err)
+	assert.Equal(slow, best)
+	assert.Equal(fast, pool.Ranked()[2])
+
+	fast.recordCheck(150*time.Millisecond, 101)
+	assert.Equal(0, fast.Stats().Errors)
+	assert.Equal(75*time.Millisecond, fast.Stats().Latency)
+	best, err = pool.Best()
+	assert.NoError(err)
+	assert.Equal(fast, best)
+
+	_, err = (&EndpointPool{endpoints: []*Endpoint{down}}).Best()
+	assert.Equal(ErrNoEndpoint, err)
+}
+
+func (ts *EndpointPoolTestSuite) TestDo() {
+	assert := ts.Assert()
+
+	first := ts.newEndpoint("first", EndpointStats{Connected: true, Latency: 50 * time.Millisecond, Height: 100})
+	second := ts.newEndpoint("second", EndpointStats{Connected: true, Latency: 400 * time.Millisecond, Height: 100})
+	pool := &EndpointPool{l: zap.S(), name: "rpc", endpoints: []*Endpoint{first, second}}
+
+	var called []string
+	err := pool.Do(context.Background(), func(endpoint *Endpoint) error {
+		called = append(called, endpoint.URL)
+		if endpoint == first {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"first", "second"}, called)
+	assert.Equal(1, first.Stats().Errors)
+
+	// a missing item is answered by the endpoint, there is no failover
+	called = nil
+	err = pool.Do(context.Background(), func(endpoint *Endpoint) error {
+		called = append(called, endpoint.URL)
+		return ethereum.NotFound
+	})
+	assert.Equal(ethereum.NotFound, err)
+	assert.Equal([]string{"first"}, called)
+	assert.Equal(0, first.Stats().Errors)
+}
+
+type fakeSubscription struct {
+	errCh chan error
+}
+
+func (s *fakeSubscription) Err() <-chan error {
+	return s.errCh
+}
+
+func (s *fakeSubscription) Unsubscribe() {}
+
+func (ts *EndpointPoolTestSuite) TestSubscribeFailover() {
+	assert := ts.Assert()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := ts.newEndpoint("first", EndpointStats{Connected: true, Latency: 50 * time.Millisecond, Height: 100})
+	second := ts.newEndpoint("second", EndpointStats{Connected: true, Latency: 400 * time.Millisecond, Height: 100})
+	pool := &EndpointPool{l: zap.S(), name: "ws", endpoints: []*Endpoint{first, second}}
+
+	subs := make(map[*Endpoint]*fakeSubscription)
+	var mu sync.Mutex
+	sub, err := pool.Subscribe(ctx, func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		subs[endpoint] = &fakeSubscription{errCh: make(chan error, 1)}
+		return subs[endpoint], nil
+	})
+	assert.NoError(err)
+	defer sub.Unsubscribe()
+	assert.Equal(first, sub.Endpoint())
+
+	mu.Lock()
+	subs[first].errCh <- errors.New("connection reset")
+	mu.Unlock()
+	assert.Eventually(func() bool {
+		return sub.Endpoint() == second
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(MaxEndpointErrors, first.Stats().Errors)
+}
+
+func (ts *EndpointPoolTestSuite) TestSubscriptionClosedByRedial() {
+	assert := ts.Assert()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := ts.newEndpoint("first", EndpointStats{Connected: true, Latency: 50 * time.Millisecond, Height: 100})
+	second := ts.newEndpoint("second", EndpointStats{Connected: true, Latency: 400 * time.Millisecond, Height: 100})
+	pool := &EndpointPool{l: zap.S(), name: "ws", endpoints: []*Endpoint{first, second}}
+
+	var (
+		subs []*fakeSubscription
+		mu   sync.Mutex
+	)
+	sub, err := pool.Subscribe(ctx, func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		subs = append(subs, &fakeSubscription{errCh: make(chan error, 1)})
+		return subs[len(subs)-1], nil
+	})
+	assert.NoError(err)
+	defer sub.Unsubscribe()
+
+	// the endpoint is redialled, closing the previous client ends the subscription with a nil error
+	first.mu.Lock()
+	first.rpcClient = rpc.DialInProc(rpc.NewServer())
+	first.mu.Unlock()
+	mu.Lock()
+	subs[0].errCh <- nil
+	mu.Unlock()
+
+	assert.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(subs) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(0, first.Stats().Errors)
+	assert.Equal(first, sub.Endpoint())
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"time"
 
+	etherCommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/quangkeu95/fantom-bot/pkg"
@@ -38,6 +39,23 @@ func NewGraphqlClient() (*GraphqlClient, error) {
 	}, nil
 }
 
+// GetLatestBlock returns the number of the latest block known by the GraphQL API.
+func (c *GraphqlClient) GetLatestBlock(parentCtx context.Context) (uint64, error) {
+	var query struct {
+		Block struct {
+			Number graphql.String `graphql:"number"`
+		} `graphql:"block"`
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, GraphqlTimeout)
+	defer cancel()
+	if err := c.client.Query(ctx, &query, nil); err != nil {
+		c.l.Warnw("graphql get latest block error", "error", err)
+		return 0, err
+	}
+	return hexutil.DecodeUint64(string(query.Block.Number))
+}
+
 func (c *GraphqlClient) GetListValidators(parentCtx context.Context) ([]pkg.SFCValidator, error) {
 	var result = make([]pkg.SFCValidator, 0)
 	var query struct {
@@ -50,6 +68,8 @@ func (c *GraphqlClient) GetListValidators(parentCtx context.Context) ([]pkg.SFCV
 			CreatedEpoch     graphql.String  `graphql:"createdEpoch"`
 			DeactivatedTime  graphql.String  `graphql:"deactivatedTime"`
 			DeactivatedEpoch graphql.String  `graphql:"deactivatedEpoch"`
+			Stake            graphql.String  `graphql:"stake"`
+			TotalStake       graphql.String  `graphql:"totalStake"`
 		} `graphql:"stakers"`
 	}
 
@@ -86,15 +106,29 @@ func (c *GraphqlClient) GetListValidators(parentCtx context.Context) ([]pkg.SFCV
 			c.l.Debugw("parse deactivated epoch error", "error", err)
 			continue
 		}
+		// a validator with unknown stakes would be reported as crossing the stake thresholds
+		selfStake, err := hexutil.DecodeBig(string(item.Stake))
+		if err != nil {
+			c.l.Warnw("parse stake error, skip", "error", err, "validator_id", id)
+			continue
+		}
+		totalStake, err := hexutil.DecodeBig(string(item.TotalStake))
+		if err != nil {
+			c.l.Warnw("parse total stake error, skip", "error", err, "validator_id", id)
+			continue
+		}
 		validator := pkg.SFCValidator{
 			ID:               id.Uint64(),
 			Address:          string(item.Address),
+			Status:           pkg.ToValidatorStatus(bool(item.IsActive), bool(item.IsOffline)),
 			IsActive:         bool(item.IsActive),
 			IsOffline:        bool(item.IsOffline),
 			CreatedTime:      createdTime.Uint64(),
 			CreatedEpoch:     createdEpoch.Uint64(),
 			DeactivatedTime:  deactivatedTime.Uint64(),
 			DeactivatedEpoch: deactivatedEpoch.Uint64(),
+			SelfStake:        pkg.WeiToFloat(selfStake, 18),
+			TotalStake:       pkg.WeiToFloat(totalStake, 18),
 		}
 		result = append(result, validator)
 	}
@@ -144,11 +178,14 @@ func (c *GraphqlClient) GetListFTMTransferByBlock(parentCtx context.Context, blo
 			continue
 		}
 		result = append(result, pkg.TransferLog{
-			BlockNumber: blockNumber,
-			TxHash:      string(tx.Hash),
-			From:        string(tx.From),
-			To:          string(tx.To),
-			Amount:      pkg.WeiToFloat(amount, 18),
+			From:   string(tx.From),
+			To:     string(tx.To),
+			Amount: pkg.WeiToFloat(amount, 18),
+			EventLog: pkg.EventLog{
+				BlockNumber: blockNumber,
+				BlockHash:   etherCommon.HexToHash(string(query.Block.Hash)).Hex(),
+				TxHash:      string(tx.Hash),
+			},
 		})
 	}
 	return result, nil
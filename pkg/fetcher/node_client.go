@@ -4,74 +4,311 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	validation "github.com/go-ozzo/ozzo-validation"
-	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
 	"github.com/quangkeu95/fantom-bot/pkg"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+const (
+	// ValidatorsBatchSize is the number of validators loaded from the SFC contract at once.
+	ValidatorsBatchSize = 50
+	// ValidatorsConcurrency is the maximum number of concurrent SFC calls while loading the validators.
+	ValidatorsConcurrency = 8
+)
+
 type NodeClient struct {
-	l      *zap.SugaredLogger
-	client *ethclient.Client
-	mu     sync.RWMutex
+	l         *zap.SugaredLogger
+	pool      *EndpointPool
+	sfcCaller *contracts.SFCCaller
 
 	chainID *big.Int
 }
 
+// NewNodeClient dials the rpc endpoints, the calls are routed to the healthiest one.
 func NewNodeClient() (*NodeClient, error) {
-	endpoint := viper.GetString("fantom_chain.rpc_endpoint")
-	if err := validation.Validate(endpoint, is.URL); err != nil {
+	pool, err := NewEndpointPool("rpc", endpointURLs(RPCEndpointsFlag, RPCEndpointFlag))
+	if err != nil {
 		return nil, err
 	}
-	client, err := ethclient.Dial(endpoint)
+
+	var chainID *big.Int
+	err = pool.Do(context.Background(), func(endpoint *Endpoint) error {
+		var err error
+		chainID, err = endpoint.ETHClient().ChainID(context.Background())
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	chainID, err := client.ChainID(context.Background())
+	sfcCaller, err := contracts.NewSFCCaller(etherCommon.HexToAddress(viper.GetString("fantom_chain.sfc_contract_address")), pool.Backend())
 	if err != nil {
 		return nil, err
 	}
 
 	return &NodeClient{
-		l:       zap.S(),
-		client:  client,
-		mu:      sync.RWMutex{},
-		chainID: chainID,
+		l:         zap.S(),
+		pool:      pool,
+		sfcCaller: sfcCaller,
+		chainID:   chainID,
 	}, nil
 }
 
-func (c *NodeClient) GetETHClient() *ethclient.Client {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.client
+// Run checks the health of the rpc endpoints until ctx is done.
+func (c *NodeClient) Run(ctx context.Context) {
+	c.pool.Run(ctx)
+}
+
+func (c *NodeClient) GetChainID() *big.Int {
+	return c.chainID
+}
+
+func (c *NodeClient) GetPool() *EndpointPool {
+	return c.pool
+}
+
+// GetBackend returns a contract backend routed to the healthiest endpoint with failover.
+func (c *NodeClient) GetBackend() bind.ContractBackend {
+	return c.pool.Backend()
+}
+
+// GetTransaction returns the transaction by hash, pending or mined.
+func (c *NodeClient) GetTransaction(ctx context.Context, hash etherCommon.Hash) (*types.Transaction, error) {
+	var tx *types.Transaction
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		tx, _, err = endpoint.ETHClient().TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, err
+}
+
+// GetTxReceipt returns the receipt of a mined transaction, ethereum.NotFound when it is not mined.
+func (c *NodeClient) GetTxReceipt(ctx context.Context, hash etherCommon.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		receipt, err = endpoint.ETHClient().TransactionReceipt(ctx, hash)
+		return err
+	})
+	return receipt, err
+}
+
+func (c *NodeClient) GetBlock(ctx context.Context, blockNumber uint64) (*types.Block, error) {
+	var block *types.Block
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		block, err = endpoint.ETHClient().BlockByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+		return err
+	})
+	return block, err
+}
+
+// GetNonce returns the nonce of the address at the latest block.
+func (c *NodeClient) GetNonce(ctx context.Context, address etherCommon.Address) (uint64, error) {
+	var nonce uint64
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		nonce, err = endpoint.ETHClient().NonceAt(ctx, address, nil)
+		return err
+	})
+	return nonce, err
+}
+
+// GetBlockHead returns the head of the block by number, the latest block when number is nil.
+func (c *NodeClient) GetBlockHead(ctx context.Context, number *uint64) (pkg.BlockHead, error) {
+	blockNumber := "latest"
+	if number != nil {
+		blockNumber = hexutil.EncodeUint64(*number)
+	}
+	var head pkg.BlockHead
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		head, err = getBlockHead(ctx, endpoint.RPCClient(), "eth_getBlockByNumber", blockNumber)
+		return err
+	})
+	return head, err
+}
+
+func (c *NodeClient) GetBlockHeadByHash(ctx context.Context, hash string) (pkg.BlockHead, error) {
+	var head pkg.BlockHead
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		head, err = getBlockHead(ctx, endpoint.RPCClient(), "eth_getBlockByHash", etherCommon.HexToHash(hash))
+		return err
+	})
+	return head, err
 }
 
 func (c *NodeClient) GetLatestBlock(ctx context.Context) (uint64, error) {
-	header, err := c.client.HeaderByNumber(ctx, nil)
+	var height uint64
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		height, err = endpoint.GetLatestBlock(ctx)
+		return err
+	})
+	return height, err
+}
+
+// GetListValidators loads every validator from the SFC contract, the validators are loaded by batch
+// with a limited number of concurrent calls. A validator which cannot be read is retried once, then skipped.
+func (c *NodeClient) GetListValidators(ctx context.Context) ([]pkg.SFCValidator, error) {
+	lastValidatorID, err := c.sfcCaller.LastValidatorID(&bind.CallOpts{Context: ctx})
 	if err != nil {
-		return 0, err
+		c.l.Warnw("get last validator id error", "error", err)
+		return nil, err
+	}
+
+	var (
+		result = make([]pkg.SFCValidator, 0)
+		failed = make([]uint64, 0)
+	)
+	for startID := uint64(1); startID <= lastValidatorID.Uint64(); startID += ValidatorsBatchSize {
+		endID := startID + ValidatorsBatchSize - 1
+		if endID > lastValidatorID.Uint64() {
+			endID = lastValidatorID.Uint64()
+		}
+		var ids = make([]uint64, 0, endID-startID+1)
+		for id := startID; id <= endID; id++ {
+			ids = append(ids, id)
+		}
+
+		validators, failedIDs, err := c.getValidators(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, validators...)
+		failed = append(failed, failedIDs...)
+		c.l.Debugw("load validators batch", "from_id", startID, "to_id", endID)
 	}
-	return header.Number.Uint64(), nil
+
+	if len(failed) > 0 {
+		validators, failedIDs, err := c.getValidators(ctx, failed)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, validators...)
+		if len(failedIDs) > 0 {
+			c.l.Warnw("skip validators which cannot be read", "validator_ids", failedIDs)
+		}
+		if len(failedIDs) == int(lastValidatorID.Uint64()) {
+			return nil, fmt.Errorf("cannot read any of the %d validators", len(failedIDs))
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
 }
 
-func (c *NodeClient) GetListValidators(ctx context.Context) ([]pkg.SFCValidator, error) {
-	return nil, fmt.Errorf("not implemented")
+// getValidators reads the validators concurrently, it returns the existing validators and the IDs
+// which cannot be read. An error is only returned when ctx is done.
+func (c *NodeClient) getValidators(ctx context.Context, ids []uint64) ([]pkg.SFCValidator, []uint64, error) {
+	var (
+		validators = make([]*pkg.SFCValidator, len(ids))
+		errs       = make([]error, len(ids))
+		semaphore  = make(chan struct{}, ValidatorsConcurrency)
+		wg         sync.WaitGroup
+	)
+	for i, id := range ids {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, nil, ctx.Err()
+		}
+		wg.Add(1)
+		go func(i int, id uint64) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			validators[i], errs[i] = GetValidator(ctx, c.sfcCaller, id, nil)
+		}(i, id)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	var (
+		result = make([]pkg.SFCValidator, 0, len(ids))
+		failed = make([]uint64, 0)
+	)
+	for i, validator := range validators {
+		if errs[i] != nil {
+			c.l.Warnw("get validator error", "error", errs[i], "validator_id", ids[i])
+			failed = append(failed, ids[i])
+			continue
+		}
+		if validator != nil {
+			result = append(result, *validator)
+		}
+	}
+	return result, failed, nil
+}
+
+// GetValidator reads the validator with its stakes from the SFC contract in the state of blockNumber,
+// the latest state when blockNumber is nil. It returns nil when the validator does not exist.
+func GetValidator(ctx context.Context, sfcCaller *contracts.SFCCaller, id uint64, blockNumber *uint64) (*pkg.SFCValidator, error) {
+	opts := &bind.CallOpts{
+		Context: ctx,
+	}
+	if blockNumber != nil {
+		opts.BlockNumber = new(big.Int).SetUint64(*blockNumber)
+	}
+	validatorID := new(big.Int).SetUint64(id)
+	res, err := sfcCaller.GetValidator(opts, validatorID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Auth == (etherCommon.Address{}) {
+		return nil, nil
+	}
+	selfStake, err := sfcCaller.GetSelfStake(opts, validatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := pkg.ValidatorStatus(res.Status.Uint64())
+	return &pkg.SFCValidator{
+		ID:               id,
+		Address:          res.Auth.Hex(),
+		CreatedTime:      res.CreatedTime.Uint64(),
+		CreatedEpoch:     res.CreatedEpoch.Uint64(),
+		DeactivatedTime:  res.DeactivatedTime.Uint64(),
+		DeactivatedEpoch: res.DeactivatedEpoch.Uint64(),
+		Status:           status,
+		IsActive:         status.IsActive(),
+		IsOffline:        status.IsOffline(),
+		SelfStake:        pkg.WeiToFloat(selfStake, 18),
+		TotalStake:       pkg.WeiToFloat(res.ReceivedStake, 18),
+	}, nil
 }
 
 func (c *NodeClient) GetListFTMTransferByBlock(ctx context.Context, blockNumber uint64) ([]pkg.TransferLog, error) {
-	block, err := c.client.BlockByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	block, err := c.GetBlock(ctx, blockNumber)
 	if err != nil {
 		c.l.Warnw("get list FTM transfer by block error", "error", err, "block_number", blockNumber)
 		return nil, err
 	}
 
+	// block.Hash() is recomputed from the header fields and does not match Opera block hashes
+	head, err := c.GetBlockHead(ctx, &blockNumber)
+	if err != nil {
+		c.l.Warnw("get block head error", "error", err, "block_number", blockNumber)
+		return nil, err
+	}
+
 	var result = make([]pkg.TransferLog, 0)
 	for _, tx := range block.Transactions() {
 		data := etherCommon.Bytes2Hex(tx.Data())
@@ -88,11 +325,14 @@ func (c *NodeClient) GetListFTMTransferByBlock(ctx context.Context, blockNumber
 		from = msg.From().Hex()
 
 		result = append(result, pkg.TransferLog{
-			BlockNumber: blockNumber,
-			TxHash:      tx.Hash().Hex(),
-			From:        from,
-			To:          tx.To().Hex(),
-			Amount:      pkg.WeiToFloat(tx.Value(), 18),
+			From:   from,
+			To:     tx.To().Hex(),
+			Amount: pkg.WeiToFloat(tx.Value(), 18),
+			EventLog: pkg.EventLog{
+				BlockNumber: blockNumber,
+				BlockHash:   head.Hash,
+				TxHash:      tx.Hash().Hex(),
+			},
 		})
 	}
 	return result, nil
@@ -2,10 +2,19 @@ package fetcher
 
 import (
 	"context"
+	"errors"
+	"math/big"
+	"sync"
 	"testing"
 
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/quangkeu95/fantom-bot/config"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
 )
 
 type NodeClientTestSuite struct {
@@ -27,6 +36,19 @@ func (ts *NodeClientTestSuite) SetupSuite() {
 	ts.client = client
 }
 
+func (ts *NodeClientTestSuite) TestGetListValidators() {
+	assert := ts.Assert()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	validators, err := ts.client.GetListValidators(ctx)
+	assert.NoError(err)
+	assert.NotNil(validators)
+	assert.Greater(len(validators), 0)
+	assert.Equal(uint64(1), validators[0].ID)
+}
+
 func (ts *NodeClientTestSuite) TestGetListFTMTransferByBlock() {
 	assert := ts.Assert()
 
@@ -39,3 +61,88 @@ func (ts *NodeClientTestSuite) TestGetListFTMTransferByBlock() {
 	assert.NotNil(logs)
 	assert.Equal(2, len(logs))
 }
+
+// fakeValidatorsBackend answers the SFC calls of the validators, the auth address of a validator is its ID.
+// failures is the number of calls failing for a validator, -1 fails them all.
+type fakeValidatorsBackend struct {
+	bind.ContractBackend
+	lastValidatorID int64
+	failures        map[uint64]int
+	mu              sync.Mutex
+}
+
+func (b *fakeValidatorsBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	word := func(values ...int64) []byte {
+		var output []byte
+		for _, value := range values {
+			output = append(output, etherCommon.LeftPadBytes(big.NewInt(value).Bytes(), 32)...)
+		}
+		return output
+	}
+	method := string(call.Data[:4])
+	if method == string(crypto.Keccak256([]byte("lastValidatorID()"))[:4]) {
+		return word(b.lastValidatorID), nil
+	}
+
+	id := new(big.Int).SetBytes(call.Data[4:36]).Uint64()
+	b.mu.Lock()
+	failures := b.failures[id]
+	if failures > 0 {
+		b.failures[id]--
+	}
+	b.mu.Unlock()
+	if failures != 0 {
+		return nil, errors.New("connection reset")
+	}
+	if method == string(crypto.Keccak256([]byte("getSelfStake(uint256)"))[:4]) {
+		return word(1e18), nil
+	}
+	// status, deactivated time, deactivated epoch, received stake, created epoch, created time and auth
+	return word(0, 0, 0, 2e18, 1, 1650000000, int64(id)), nil
+}
+
+type GetListValidatorsTestSuite struct {
+	suite.Suite
+}
+
+func TestGetListValidatorsTestSuite(t *testing.T) {
+	suite.Run(t, new(GetListValidatorsTestSuite))
+}
+
+func (ts *GetListValidatorsTestSuite) newClient(backend *fakeValidatorsBackend) *NodeClient {
+	sfcCaller, err := contracts.NewSFCCaller(etherCommon.HexToAddress("0xfc00face00000000000000000000000000000000"), backend)
+	ts.Assert().NoError(err)
+	return &NodeClient{l: zap.S(), sfcCaller: sfcCaller}
+}
+
+func (ts *GetListValidatorsTestSuite) TestSkipFailedValidators() {
+	assert := ts.Assert()
+
+	backend := &fakeValidatorsBackend{lastValidatorID: 60, failures: map[uint64]int{3: 1, 55: -1}}
+	validators, err := ts.newClient(backend).GetListValidators(context.Background())
+	assert.NoError(err)
+
+	// the validator 3 is read at the retry, the validator 55 is skipped
+	assert.Equal(59, len(validators))
+	assert.Equal(uint64(3), validators[2].ID)
+	assert.Equal(float64(2), validators[2].TotalStake)
+	assert.Equal(uint64(56), validators[54].ID)
+}
+
+func (ts *GetListValidatorsTestSuite) TestNoValidatorRead() {
+	assert := ts.Assert()
+
+	backend := &fakeValidatorsBackend{lastValidatorID: 2, failures: map[uint64]int{1: -1, 2: -1}}
+	_, err := ts.newClient(backend).GetListValidators(context.Background())
+	assert.Error(err)
+}
+
+func (ts *GetListValidatorsTestSuite) TestContextDone() {
+	assert := ts.Assert()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	backend := &fakeValidatorsBackend{lastValidatorID: 60}
+	_, _, err := ts.newClient(backend).getValidators(ctx, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	assert.Equal(context.Canceled, err)
+}
@@ -0,0 +1,103 @@
+package fetcher
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// poolBackend routes the calls of the contract bindings to the best endpoint of the pool.
+type poolBackend struct {
+	pool *EndpointPool
+}
+
+var _ bind.ContractBackend = (*poolBackend)(nil)
+
+func (b *poolBackend) CodeAt(ctx context.Context, contract etherCommon.Address, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := b.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		result, err = endpoint.ETHClient().CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (b *poolBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := b.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		result, err = endpoint.ETHClient().CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (b *poolBackend) PendingCodeAt(ctx context.Context, account etherCommon.Address) ([]byte, error) {
+	var result []byte
+	err := b.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		result, err = endpoint.ETHClient().PendingCodeAt(ctx, account)
+		return err
+	})
+	return result, err
+}
+
+func (b *poolBackend) PendingNonceAt(ctx context.Context, account etherCommon.Address) (uint64, error) {
+	var result uint64
+	err := b.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		result, err = endpoint.ETHClient().PendingNonceAt(ctx, account)
+		return err
+	})
+	return result, err
+}
+
+func (b *poolBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var result *big.Int
+	err := b.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		result, err = endpoint.ETHClient().SuggestGasPrice(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (b *poolBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	var result uint64
+	err := b.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		result, err = endpoint.ETHClient().EstimateGas(ctx, call)
+		return err
+	})
+	return result, err
+}
+
+// SendTransaction sends the transaction to the best endpoint only.
+func (b *poolBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	endpoint, err := b.pool.Best()
+	if err != nil {
+		return err
+	}
+	return endpoint.ETHClient().SendTransaction(ctx, tx)
+}
+
+func (b *poolBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var result []types.Log
+	err := b.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		result, err = endpoint.ETHClient().FilterLogs(ctx, query)
+		return err
+	})
+	return result, err
+}
+
+func (b *poolBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return b.pool.SubscribeOnce(ctx, func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error) {
+		return endpoint.ETHClient().SubscribeFilterLogs(ctx, query, ch)
+	})
+}
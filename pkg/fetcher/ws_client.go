@@ -1,39 +1,88 @@
 package fetcher
 
 import (
-	"sync"
+	"context"
 
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	validation "github.com/go-ozzo/ozzo-validation"
-	"github.com/go-ozzo/ozzo-validation/is"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
 type WsClient struct {
-	l      *zap.SugaredLogger
-	client *ethclient.Client
-	mu     sync.RWMutex
+	l    *zap.SugaredLogger
+	pool *EndpointPool
 }
 
+// NewWsClient dials the ws endpoints, the calls and the subscriptions go to the healthiest one.
 func NewWsClient() (*WsClient, error) {
-	endpoint := viper.GetString("fantom_chain.ws_endpoint")
-	if err := validation.Validate(endpoint, is.URL); err != nil {
-		return nil, err
-	}
-	client, err := ethclient.Dial(endpoint)
+	pool, err := NewEndpointPool("ws", endpointURLs(WsEndpointsFlag, WsEndpointFlag))
 	if err != nil {
 		return nil, err
 	}
 	return &WsClient{
-		l:      zap.S(),
-		client: client,
-		mu:     sync.RWMutex{},
+		l:    zap.S(),
+		pool: pool,
 	}, nil
 }
 
+// Run checks the health of the ws endpoints until ctx is done.
+func (c *WsClient) Run(ctx context.Context) {
+	c.pool.Run(ctx)
+}
+
+func (c *WsClient) GetPool() *EndpointPool {
+	return c.pool
+}
+
+// GetBackend returns a contract backend routed to the healthiest endpoint with failover.
+func (c *WsClient) GetBackend() bind.ContractBackend {
+	return c.pool.Backend()
+}
+
+// GetETHClient returns the client of the healthiest endpoint, nil when no endpoint is connected.
 func (c *WsClient) GetETHClient() *ethclient.Client {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.client
+	endpoint, err := c.pool.Best()
+	if err != nil {
+		return nil
+	}
+	return endpoint.ETHClient()
+}
+
+func (c *WsClient) GetLatestBlock(ctx context.Context) (uint64, error) {
+	var height uint64
+	err := c.pool.Do(ctx, func(endpoint *Endpoint) error {
+		var err error
+		height, err = endpoint.GetLatestBlock(ctx)
+		return err
+	})
+	return height, err
+}
+
+// SubscribeNewHead subscribes to new block heads, the heads are delivered as raw responses
+// so that the block hash reported by the node is kept.
+// The subscription moves to another endpoint when it drops.
+func (c *WsClient) SubscribeNewHead(ctx context.Context, ch chan<- *BlockHeadResponse) (ethereum.Subscription, error) {
+	return c.pool.Subscribe(ctx, func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error) {
+		return endpoint.RPCClient().EthSubscribe(ctx, ch, "newHeads")
+	})
+}
+
+// SubscribePendingTransactions subscribes to the hashes of the transactions entering the mempool of the node.
+// The subscription moves to another endpoint when it drops, the mempool differs between the nodes
+// so the pending transactions are looked up on the endpoint of the subscription.
+func (c *WsClient) SubscribePendingTransactions(ctx context.Context, ch chan<- etherCommon.Hash) (EndpointSubscription, error) {
+	return c.pool.Subscribe(ctx, func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error) {
+		return endpoint.RPCClient().EthSubscribe(ctx, ch, "newPendingTransactions")
+	})
+}
+
+// SubscribeFilterLogs subscribes to the logs matching the query on the healthiest endpoint.
+// A drop ends the subscription so that the caller replays the missed logs before subscribing again.
+func (c *WsClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return c.pool.SubscribeOnce(ctx, func(ctx context.Context, endpoint *Endpoint) (ethereum.Subscription, error) {
+		return endpoint.ETHClient().SubscribeFilterLogs(ctx, query, ch)
+	})
 }
@@ -1,19 +1,31 @@
 package keeper
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/quangkeu95/fantom-bot/pkg"
 )
 
+// MaxDelegatorsPerValidator bounds the delegators kept per validator, the smallest stakes are dropped first.
+const MaxDelegatorsPerValidator = 1000
+
+// DelegatorStake is the stake delegated and not undelegated since the bot started, in FTM.
+type DelegatorStake struct {
+	Delegator string
+	Stake     float64
+}
+
 type DelegateInfoKeeper struct {
 	listInfo []pkg.SFCDelegateInfo
+	stakes   map[uint64]map[string]DelegatorStake
 	mu       sync.RWMutex
 }
 
 func NewDelegateInfoKeeper() *DelegateInfoKeeper {
 	return &DelegateInfoKeeper{
 		listInfo: make([]pkg.SFCDelegateInfo, 0),
+		stakes:   make(map[uint64]map[string]DelegatorStake),
 		mu:       sync.RWMutex{},
 	}
 }
@@ -38,3 +50,71 @@ func (k *DelegateInfoKeeper) GetLast() (pkg.SFCDelegateInfo, bool) {
 	}
 	return k.listInfo[len(k.listInfo)-1], true
 }
+
+// AddDelegation adds the delegated amount to the stake of the delegator.
+func (k *DelegateInfoKeeper) AddDelegation(info pkg.SFCDelegateInfo) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.updateStakeLocked(info.ToValidatorID, info.Delegator, info.Amount)
+}
+
+// AddUndelegation removes the undelegated amount from the stake of the delegator,
+// the delegator is forgotten once nothing is left.
+func (k *DelegateInfoKeeper) AddUndelegation(info pkg.SFCUndelegateInfo) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.updateStakeLocked(info.ToValidatorID, info.Delegator, -info.Amount)
+}
+
+// RemoveDelegation takes back the delegated amount of a delegation removed from the chain.
+func (k *DelegateInfoKeeper) RemoveDelegation(info pkg.SFCDelegateInfo) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.updateStakeLocked(info.ToValidatorID, info.Delegator, -info.Amount)
+}
+
+// RemoveUndelegation gives back the undelegated amount of an undelegation removed from the chain.
+func (k *DelegateInfoKeeper) RemoveUndelegation(info pkg.SFCUndelegateInfo) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.updateStakeLocked(info.ToValidatorID, info.Delegator, info.Amount)
+}
+
+func (k *DelegateInfoKeeper) updateStakeLocked(validatorID uint64, delegator string, amount float64) {
+	stakes, ok := k.stakes[validatorID]
+	if !ok {
+		stakes = make(map[string]DelegatorStake)
+		k.stakes[validatorID] = stakes
+	}
+	key := strings.ToLower(delegator)
+	stake := DelegatorStake{Delegator: delegator, Stake: stakes[key].Stake + amount}
+	if stake.Stake <= 0 {
+		delete(stakes, key)
+	} else {
+		stakes[key] = stake
+	}
+
+	if len(stakes) > MaxDelegatorsPerValidator {
+		var smallest string
+		for key, stake := range stakes {
+			if smallest == "" || stake.Stake < stakes[smallest].Stake {
+				smallest = key
+			}
+		}
+		delete(stakes, smallest)
+	}
+	if len(stakes) == 0 {
+		delete(k.stakes, validatorID)
+	}
+}
+
+// GetDelegators returns the delegators with a stake in the validator.
+func (k *DelegateInfoKeeper) GetDelegators(validatorID uint64) []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var result = make([]string, 0, len(k.stakes[validatorID]))
+	for _, stake := range k.stakes[validatorID] {
+		result = append(result, stake.Delegator)
+	}
+	return result
+}
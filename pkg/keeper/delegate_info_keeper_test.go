@@ -0,0 +1,76 @@
+package keeper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type DelegateInfoKeeperTestSuite struct {
+	suite.Suite
+	keeper *DelegateInfoKeeper
+}
+
+func TestDelegateInfoKeeperTestSuite(t *testing.T) {
+	suite.Run(t, new(DelegateInfoKeeperTestSuite))
+}
+
+func (ts *DelegateInfoKeeperTestSuite) SetupTest() {
+	ts.keeper = NewDelegateInfoKeeper()
+}
+
+func (ts *DelegateInfoKeeperTestSuite) TestNetStake() {
+	assert := ts.Assert()
+
+	delegator := "0x00000000000000000000000000000000000000AA"
+	ts.keeper.AddDelegation(pkg.SFCDelegateInfo{Delegator: delegator, ToValidatorID: 12, Amount: 1000})
+	ts.keeper.AddDelegation(pkg.SFCDelegateInfo{Delegator: "0x00000000000000000000000000000000000000aa", ToValidatorID: 12, Amount: 500})
+	ts.keeper.AddDelegation(pkg.SFCDelegateInfo{Delegator: delegator, ToValidatorID: 13, Amount: 100})
+	assert.Equal([]string{"0x00000000000000000000000000000000000000aa"}, ts.keeper.GetDelegators(12))
+	assert.Equal([]string{delegator}, ts.keeper.GetDelegators(13))
+
+	// the delegator is kept until the whole stake is undelegated
+	ts.keeper.AddUndelegation(pkg.SFCUndelegateInfo{Delegator: delegator, ToValidatorID: 12, Amount: 1200})
+	assert.Equal(1, len(ts.keeper.GetDelegators(12)))
+	ts.keeper.AddUndelegation(pkg.SFCUndelegateInfo{Delegator: delegator, ToValidatorID: 12, Amount: 300})
+	assert.Equal(0, len(ts.keeper.GetDelegators(12)))
+
+	// an undelegation of a stake delegated before the bot started is ignored
+	ts.keeper.AddUndelegation(pkg.SFCUndelegateInfo{Delegator: delegator, ToValidatorID: 14, Amount: 300})
+	assert.Equal(0, len(ts.keeper.GetDelegators(14)))
+	assert.Equal(1, len(ts.keeper.stakes))
+}
+
+func (ts *DelegateInfoKeeperTestSuite) TestMaxDelegatorsPerValidator() {
+	assert := ts.Assert()
+
+	for i := 0; i < MaxDelegatorsPerValidator; i++ {
+		ts.keeper.AddDelegation(pkg.SFCDelegateInfo{Delegator: fmt.Sprintf("0x%040x", i), ToValidatorID: 12, Amount: float64(100 + i)})
+	}
+	ts.keeper.AddDelegation(pkg.SFCDelegateInfo{Delegator: "0x0000000000000000000000000000000000000BbB", ToValidatorID: 12, Amount: 5000})
+
+	delegators := ts.keeper.GetDelegators(12)
+	assert.Equal(MaxDelegatorsPerValidator, len(delegators))
+	assert.Contains(delegators, "0x0000000000000000000000000000000000000BbB")
+	// the smallest stake is dropped
+	assert.NotContains(delegators, fmt.Sprintf("0x%040x", 0))
+}
+
+func (ts *DelegateInfoKeeperTestSuite) TestRemove() {
+	assert := ts.Assert()
+
+	delegator := "0x00000000000000000000000000000000000000AA"
+	delegation := pkg.SFCDelegateInfo{Delegator: delegator, ToValidatorID: 12, Amount: 1000}
+	ts.keeper.AddDelegation(delegation)
+	undelegation := pkg.SFCUndelegateInfo{Delegator: delegator, ToValidatorID: 12, Amount: 1000}
+	ts.keeper.AddUndelegation(undelegation)
+	assert.Equal(0, len(ts.keeper.GetDelegators(12)))
+
+	// the undelegation is removed by a reorg, the stake is back
+	ts.keeper.RemoveUndelegation(undelegation)
+	assert.Equal([]string{delegator}, ts.keeper.GetDelegators(12))
+	ts.keeper.RemoveDelegation(delegation)
+	assert.Equal(0, len(ts.keeper.GetDelegators(12)))
+}
@@ -1,23 +1,31 @@
 package keeper
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/quangkeu95/fantom-bot/pkg"
 )
 
 type UndelegateInfoKeeper struct {
-	listInfo []pkg.SFCUndelegateInfo
-	mu       sync.RWMutex
+	listInfo           []pkg.SFCUndelegateInfo
+	withdrawalRequests map[string]pkg.SFCUndelegateInfo
+	mu                 sync.RWMutex
 }
 
 func NewUndelegateInfoKeeper() *UndelegateInfoKeeper {
 	return &UndelegateInfoKeeper{
-		listInfo: make([]pkg.SFCUndelegateInfo, 0),
-		mu:       sync.RWMutex{},
+		listInfo:           make([]pkg.SFCUndelegateInfo, 0),
+		withdrawalRequests: make(map[string]pkg.SFCUndelegateInfo),
+		mu:                 sync.RWMutex{},
 	}
 }
 
+func withdrawalRequestKey(delegator string, validatorID uint64, wrID uint64) string {
+	return fmt.Sprintf("%s:%d:%d", strings.ToLower(delegator), validatorID, wrID)
+}
+
 func (k *UndelegateInfoKeeper) Add(info pkg.SFCUndelegateInfo) {
 	k.mu.Lock()
 	defer k.mu.Unlock()
@@ -30,6 +38,28 @@ func (k *UndelegateInfoKeeper) AddBatch(infos []pkg.SFCUndelegateInfo) {
 	k.listInfo = append(k.listInfo, infos...)
 }
 
+// AddWithdrawalRequest keeps the undelegation until its withdrawal request is withdrawn.
+func (k *UndelegateInfoKeeper) AddWithdrawalRequest(info pkg.SFCUndelegateInfo) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.withdrawalRequests[withdrawalRequestKey(info.Delegator, info.ToValidatorID, info.WrID)] = info
+}
+
+// GetByWithdrawalRequest returns the undelegation which created the withdrawal request.
+func (k *UndelegateInfoKeeper) GetByWithdrawalRequest(delegator string, validatorID uint64, wrID uint64) (pkg.SFCUndelegateInfo, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	info, ok := k.withdrawalRequests[withdrawalRequestKey(delegator, validatorID, wrID)]
+	return info, ok
+}
+
+// RemoveByWithdrawalRequest forgets the withdrawal request once it has been withdrawn.
+func (k *UndelegateInfoKeeper) RemoveByWithdrawalRequest(delegator string, validatorID uint64, wrID uint64) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.withdrawalRequests, withdrawalRequestKey(delegator, validatorID, wrID))
+}
+
 func (k *UndelegateInfoKeeper) GetLast() (pkg.SFCUndelegateInfo, bool) {
 	k.mu.RLock()
 	defer k.mu.RUnlock()
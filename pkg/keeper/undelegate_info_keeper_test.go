@@ -0,0 +1,59 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type UndelegateInfoKeeperTestSuite struct {
+	suite.Suite
+	keeper *UndelegateInfoKeeper
+}
+
+func TestUndelegateInfoKeeperTestSuite(t *testing.T) {
+	suite.Run(t, new(UndelegateInfoKeeperTestSuite))
+}
+
+func (ts *UndelegateInfoKeeperTestSuite) SetupTest() {
+	ts.keeper = NewUndelegateInfoKeeper()
+}
+
+func (ts *UndelegateInfoKeeperTestSuite) TestGetByWithdrawalRequest() {
+	assert := ts.Assert()
+
+	info := pkg.SFCUndelegateInfo{
+		Delegator:     "0x00000000000000000000000000000000000000AA",
+		ToValidatorID: 12,
+		Amount:        5000,
+		WrID:          3,
+		EventLog:      pkg.EventLog{TxHash: "0x01", BlockNumber: 100},
+	}
+	ts.keeper.AddWithdrawalRequest(info)
+	ts.keeper.AddWithdrawalRequest(pkg.SFCUndelegateInfo{Delegator: info.Delegator, ToValidatorID: 12, WrID: 4})
+
+	// the withdrawal is linked whatever the case of the delegator address
+	result, ok := ts.keeper.GetByWithdrawalRequest("0x00000000000000000000000000000000000000aa", 12, 3)
+	assert.True(ok)
+	assert.Equal(info, result)
+
+	_, ok = ts.keeper.GetByWithdrawalRequest(info.Delegator, 13, 3)
+	assert.False(ok)
+	_, ok = ts.keeper.GetByWithdrawalRequest(info.Delegator, 12, 5)
+	assert.False(ok)
+}
+
+func (ts *UndelegateInfoKeeperTestSuite) TestRemoveByWithdrawalRequest() {
+	assert := ts.Assert()
+
+	info := pkg.SFCUndelegateInfo{Delegator: "0x00000000000000000000000000000000000000AA", ToValidatorID: 12, WrID: 3}
+	ts.keeper.AddWithdrawalRequest(info)
+	ts.keeper.AddWithdrawalRequest(pkg.SFCUndelegateInfo{Delegator: info.Delegator, ToValidatorID: 12, WrID: 4})
+
+	ts.keeper.RemoveByWithdrawalRequest(info.Delegator, 12, 3)
+	_, ok := ts.keeper.GetByWithdrawalRequest(info.Delegator, 12, 3)
+	assert.False(ok)
+	_, ok = ts.keeper.GetByWithdrawalRequest(info.Delegator, 12, 4)
+	assert.True(ok)
+}
@@ -32,6 +32,25 @@ func (k *ValidatorsKeeper) AddBatch(listV []pkg.SFCValidator) {
 	}
 }
 
+// Update applies the update to a known validator, it returns false when the validator is unknown.
+func (k *ValidatorsKeeper) Update(id uint64, update func(v *pkg.SFCValidator)) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	v, ok := k.listValidators[id]
+	if !ok {
+		return false
+	}
+	update(&v)
+	k.listValidators[id] = v
+	return true
+}
+
+func (k *ValidatorsKeeper) Remove(id uint64) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.listValidators, id)
+}
+
 func (k *ValidatorsKeeper) GetListValidators() map[uint64]pkg.SFCValidator {
 	k.mu.RLock()
 	defer k.mu.RUnlock()
@@ -0,0 +1,42 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValidatorsKeeperTestSuite struct {
+	suite.Suite
+	keeper *ValidatorsKeeper
+}
+
+func TestValidatorsKeeperTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidatorsKeeperTestSuite))
+}
+
+func (ts *ValidatorsKeeperTestSuite) SetupTest() {
+	ts.keeper = NewValidatorsKeeper()
+}
+
+func (ts *ValidatorsKeeperTestSuite) TestUpdate() {
+	assert := ts.Assert()
+	ts.keeper.Add(pkg.SFCValidator{ID: 12, IsActive: true})
+
+	status := pkg.SFCChangedValidatorStatus{ValidatorID: 12, Status: pkg.ValidatorStatusDoubleSign}
+	assert.True(ts.keeper.Update(status.ValidatorID, status.Apply))
+	deactivated := pkg.SFCDeactivatedValidator{ValidatorID: 12, DeactivatedEpoch: 5000, DeactivatedTime: 1650000000}
+	assert.True(ts.keeper.Update(deactivated.ValidatorID, deactivated.Apply))
+
+	validator := ts.keeper.GetValidatorById(12)
+	assert.NotNil(validator)
+	assert.Equal(pkg.ValidatorStatusDoubleSign, validator.Status)
+	assert.False(validator.IsActive)
+	assert.Equal(uint64(5000), validator.DeactivatedEpoch)
+	assert.Equal(uint64(1650000000), validator.DeactivatedTime)
+
+	// an unknown validator is left to the caller
+	assert.False(ts.keeper.Update(13, status.Apply))
+	assert.Nil(ts.keeper.GetValidatorById(13))
+}
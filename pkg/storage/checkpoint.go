@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+const (
+	CheckpointKeyPrefix = "checkpoint_"
+)
+
+// Checkpoint keeps the last processed block of every event stream and persists it
+// through a KeyValueStorage, so that watchers can resume where they stopped.
+type Checkpoint struct {
+	l       *zap.SugaredLogger
+	storage KeyValueStorage
+	blocks  map[string]uint64
+	mu      sync.RWMutex
+}
+
+func NewCheckpoint(storage KeyValueStorage) *Checkpoint {
+	return &Checkpoint{
+		l:       zap.S(),
+		storage: storage,
+		blocks:  make(map[string]uint64),
+		mu:      sync.RWMutex{},
+	}
+}
+
+// Get returns the last processed block of the stream, false if the stream has never been processed.
+func (c *Checkpoint) Get(stream string) (uint64, bool) {
+	c.mu.RLock()
+	block, ok := c.blocks[stream]
+	c.mu.RUnlock()
+	if ok {
+		return block, true
+	}
+
+	if err := c.storage.Get(CheckpointKeyPrefix+stream, &block); err != nil {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks[stream] = block
+	return block, true
+}
+
+// Set moves the checkpoint of the stream forward, a block lower than the current checkpoint is ignored.
+func (c *Checkpoint) Set(stream string, block uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current, ok := c.blocks[stream]
+	if !ok {
+		ok = c.storage.Get(CheckpointKeyPrefix+stream, &current) == nil
+	}
+	if ok && current >= block {
+		return nil
+	}
+
+	if err := c.storage.Set(CheckpointKeyPrefix+stream, block); err != nil {
+		c.l.Warnw("save checkpoint error", "error", err, "stream", stream, "block_number", block)
+		return err
+	}
+	c.blocks[stream] = block
+	return nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CheckpointTestSuite struct {
+	suite.Suite
+	storage *memoryStorage
+}
+
+func TestCheckpointTestSuite(t *testing.T) {
+	suite.Run(t, new(CheckpointTestSuite))
+}
+
+func (ts *CheckpointTestSuite) SetupTest() {
+	ts.storage = newMemoryStorage()
+}
+
+func (ts *CheckpointTestSuite) TestSetAndGet() {
+	assert := ts.Assert()
+	checkpoint := NewCheckpoint(ts.storage)
+
+	_, ok := checkpoint.Get("delegate")
+	assert.False(ok)
+
+	assert.NoError(checkpoint.Set("delegate", 100))
+	block, ok := checkpoint.Get("delegate")
+	assert.True(ok)
+	assert.Equal(uint64(100), block)
+
+	// a checkpoint never moves backward
+	assert.NoError(checkpoint.Set("delegate", 90))
+	block, _ = checkpoint.Get("delegate")
+	assert.Equal(uint64(100), block)
+
+	_, ok = checkpoint.Get("undelegate")
+	assert.False(ok)
+}
+
+func (ts *CheckpointTestSuite) TestPersisted() {
+	assert := ts.Assert()
+
+	assert.NoError(NewCheckpoint(ts.storage).Set("delegate", 100))
+
+	block, ok := NewCheckpoint(ts.storage).Get("delegate")
+	assert.True(ok)
+	assert.Equal(uint64(100), block)
+
+	// the persisted checkpoint never moves backward either
+	assert.NoError(NewCheckpoint(ts.storage).Set("delegate", 90))
+	block, _ = NewCheckpoint(ts.storage).Get("delegate")
+	assert.Equal(uint64(100), block)
+}
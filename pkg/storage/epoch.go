@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+)
+
+const (
+	EpochKeyPrefix      = "epoch_"
+	LastEpochStorageKey = "last_epoch"
+)
+
+// EpochStore persists the snapshots of the sealed epochs.
+type EpochStore struct {
+	storage KeyValueStorage
+}
+
+func NewEpochStore(storage KeyValueStorage) *EpochStore {
+	return &EpochStore{
+		storage: storage,
+	}
+}
+
+func epochKey(epoch uint64) string {
+	return fmt.Sprintf("%s%d", EpochKeyPrefix, epoch)
+}
+
+// Save stores the snapshot and records its epoch as the last one when it is newer.
+func (s *EpochStore) Save(snapshot pkg.EpochSnapshot) error {
+	if err := s.storage.Set(epochKey(snapshot.Epoch), snapshot); err != nil {
+		return err
+	}
+	if lastEpoch, ok := s.GetLastEpoch(); ok && lastEpoch >= snapshot.Epoch {
+		return nil
+	}
+	return s.storage.Set(LastEpochStorageKey, snapshot.Epoch)
+}
+
+func (s *EpochStore) Get(epoch uint64) (pkg.EpochSnapshot, error) {
+	var snapshot pkg.EpochSnapshot
+	if err := s.storage.Get(epochKey(epoch), &snapshot); err != nil {
+		return pkg.EpochSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// GetLastEpoch returns the last saved epoch, false if no epoch has been saved.
+func (s *EpochStore) GetLastEpoch() (uint64, bool) {
+	var epoch uint64
+	if err := s.storage.Get(LastEpochStorageKey, &epoch); err != nil {
+		return 0, false
+	}
+	return epoch, true
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+	"github.com/stretchr/testify/suite"
+)
+
+type EpochStoreTestSuite struct {
+	suite.Suite
+	store *EpochStore
+}
+
+func TestEpochStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(EpochStoreTestSuite))
+}
+
+func (ts *EpochStoreTestSuite) SetupTest() {
+	ts.store = NewEpochStore(newMemoryStorage())
+}
+
+func (ts *EpochStoreTestSuite) TestSaveAndGet() {
+	assert := ts.Assert()
+
+	_, ok := ts.store.GetLastEpoch()
+	assert.False(ok)
+	_, err := ts.store.Get(100)
+	assert.Error(err)
+
+	snapshot := pkg.EpochSnapshot{
+		Epoch:      100,
+		EndTime:    1650000000,
+		TotalStake: 1000000,
+		Validators: []pkg.EpochValidator{{ID: 12, ReceivedStake: 5000, OfflineBlocks: 3}},
+	}
+	assert.NoError(ts.store.Save(snapshot))
+	result, err := ts.store.Get(100)
+	assert.NoError(err)
+	assert.Equal(snapshot, result)
+
+	lastEpoch, ok := ts.store.GetLastEpoch()
+	assert.True(ok)
+	assert.Equal(uint64(100), lastEpoch)
+}
+
+func (ts *EpochStoreTestSuite) TestLastEpochNeverMovesBack() {
+	assert := ts.Assert()
+
+	assert.NoError(ts.store.Save(pkg.EpochSnapshot{Epoch: 101}))
+	assert.NoError(ts.store.Save(pkg.EpochSnapshot{Epoch: 99}))
+
+	lastEpoch, ok := ts.store.GetLastEpoch()
+	assert.True(ok)
+	assert.Equal(uint64(101), lastEpoch)
+	_, err := ts.store.Get(99)
+	assert.NoError(err)
+}
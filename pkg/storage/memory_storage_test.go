@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// memoryStorage is an in-memory KeyValueStorage encoding the values like the badger storage does.
+type memoryStorage struct {
+	values map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		values: make(map[string][]byte),
+	}
+}
+
+func (s *memoryStorage) Set(key string, value interface{}) error {
+	data, err := Encode(value)
+	if err != nil {
+		return err
+	}
+	s.values[key] = data
+	return nil
+}
+
+func (s *memoryStorage) Get(key string, value interface{}) error {
+	data, ok := s.values[key]
+	if !ok {
+		return fmt.Errorf("key %s not found", key)
+	}
+	return Decode(data, value)
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/quangkeu95/fantom-bot/pkg"
+)
+
+const (
+	NetworkStatsStorageKey           = "network_stats"
+	NetworkStatsLastReportStorageKey = "network_stats_last_report"
+)
+
+// NetworkStatsStore persists the network stats snapshots taken during the retention period.
+type NetworkStatsStore struct {
+	storage   KeyValueStorage
+	retention time.Duration
+}
+
+func NewNetworkStatsStore(storage KeyValueStorage, retention time.Duration) *NetworkStatsStore {
+	return &NetworkStatsStore{
+		storage:   storage,
+		retention: retention,
+	}
+}
+
+// Add appends the snapshot and drops the ones older than the retention period.
+func (s *NetworkStatsStore) Add(stats pkg.NetworkStats) error {
+	list := s.List()
+	list = append(list, stats)
+
+	minTime := int64(stats.Time) - int64(s.retention.Seconds())
+	var index int
+	for index < len(list) && int64(list[index].Time) < minTime {
+		index++
+	}
+	return s.storage.Set(NetworkStatsStorageKey, list[index:])
+}
+
+// List returns the snapshots sorted by time.
+func (s *NetworkStatsStore) List() []pkg.NetworkStats {
+	var list = make([]pkg.NetworkStats, 0)
+	if err := s.storage.Get(NetworkStatsStorageKey, &list); err != nil {
+		return make([]pkg.NetworkStats, 0)
+	}
+	return list
+}
+
+// GetAt returns the latest snapshot taken at or before the unix time t.
+func (s *NetworkStatsStore) GetAt(t uint64) (pkg.NetworkStats, bool) {
+	list := s.List()
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].Time <= t {
+			return list[i], true
+		}
+	}
+	return pkg.NetworkStats{}, false
+}
+
+// GetLastReport returns the date of the last daily report, formatted as 2006-01-02.
+func (s *NetworkStatsStore) GetLastReport() string {
+	var date string
+	if err := s.storage.Get(NetworkStatsLastReportStorageKey, &date); err != nil {
+		return ""
+	}
+	return date
+}
+
+func (s *NetworkStatsStore) SetLastReport(date string) error {
+	return s.storage.Set(NetworkStatsLastReportStorageKey, date)
+}
@@ -1,9 +1,108 @@
 package pkg
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/quangkeu95/fantom-bot/lib/contracts"
 )
 
+// Event is implemented by every type decoded from a chain log or transaction.
+type Event interface {
+	GetEventLog() EventLog
+}
+
+// EventLog holds the position of an event in the chain.
+type EventLog struct {
+	BlockNumber uint64
+	BlockHash   string
+	TxHash      string
+	LogIndex    uint
+	Removed     bool
+}
+
+func ToEventLog(v types.Log) EventLog {
+	return EventLog{
+		BlockNumber: v.BlockNumber,
+		BlockHash:   v.BlockHash.Hex(),
+		TxHash:      v.TxHash.Hex(),
+		LogIndex:    v.Index,
+		Removed:     v.Removed,
+	}
+}
+
+func (e EventLog) GetEventLog() EventLog {
+	return e
+}
+
+// BlockHead is the part of a block header used to follow the canonical chain.
+type BlockHead struct {
+	Number     uint64
+	Hash       string
+	ParentHash string
+	Time       uint64
+}
+
+// ValidatorStatus is the status bit set of an SFC validator, 0 means the validator is active.
+type ValidatorStatus uint64
+
+const (
+	ValidatorStatusOK         ValidatorStatus = 0
+	ValidatorStatusWithdrawn  ValidatorStatus = 1
+	ValidatorStatusOffline    ValidatorStatus = 1 << 3
+	ValidatorStatusDoubleSign ValidatorStatus = 1 << 7
+)
+
+func (s ValidatorStatus) IsActive() bool {
+	return s == ValidatorStatusOK
+}
+
+func (s ValidatorStatus) IsWithdrawn() bool {
+	return s&ValidatorStatusWithdrawn != 0
+}
+
+func (s ValidatorStatus) IsOffline() bool {
+	return s&ValidatorStatusOffline != 0
+}
+
+func (s ValidatorStatus) IsDoubleSign() bool {
+	return s&ValidatorStatusDoubleSign != 0
+}
+
+// ToValidatorStatus derives the status from the active and offline flags of a validator,
+// an inactive validator which is not offline is considered withdrawn.
+func ToValidatorStatus(isActive bool, isOffline bool) ValidatorStatus {
+	switch {
+	case isActive:
+		return ValidatorStatusOK
+	case isOffline:
+		return ValidatorStatusOffline
+	default:
+		return ValidatorStatusWithdrawn
+	}
+}
+
+func (s ValidatorStatus) String() string {
+	if s.IsActive() {
+		return "active"
+	}
+	var flags = make([]string, 0)
+	if s.IsWithdrawn() {
+		flags = append(flags, "withdrawn")
+	}
+	if s.IsOffline() {
+		flags = append(flags, "offline")
+	}
+	if s.IsDoubleSign() {
+		flags = append(flags, "doublesign")
+	}
+	if len(flags) == 0 {
+		return fmt.Sprintf("unknown status %d", uint64(s))
+	}
+	return strings.Join(flags, ", ")
+}
+
 type SFCValidator struct {
 	ID               uint64
 	Address          string
@@ -11,8 +110,13 @@ type SFCValidator struct {
 	CreatedEpoch     uint64
 	DeactivatedTime  uint64
 	DeactivatedEpoch uint64
+	Status           ValidatorStatus
 	IsActive         bool
 	IsOffline        bool
+	// SelfStake and TotalStake are in FTM, TotalStake includes the delegations.
+	SelfStake  float64
+	TotalStake float64
+	EventLog
 }
 
 func ToSFCValidator(v *contracts.SFCCreatedValidator) SFCValidator {
@@ -21,15 +125,58 @@ func ToSFCValidator(v *contracts.SFCCreatedValidator) SFCValidator {
 		Address:      v.Auth.Hex(),
 		CreatedTime:  v.CreatedTime.Uint64(),
 		CreatedEpoch: v.CreatedEpoch.Uint64(),
+		EventLog:     ToEventLog(v.Raw),
+	}
+}
+
+type SFCDeactivatedValidator struct {
+	ValidatorID      uint64
+	DeactivatedEpoch uint64
+	DeactivatedTime  uint64
+	EventLog
+}
+
+func ToSFCDeactivatedValidator(v *contracts.SFCDeactivatedValidator) SFCDeactivatedValidator {
+	return SFCDeactivatedValidator{
+		ValidatorID:      v.ValidatorID.Uint64(),
+		DeactivatedEpoch: v.DeactivatedEpoch.Uint64(),
+		DeactivatedTime:  v.DeactivatedTime.Uint64(),
+		EventLog:         ToEventLog(v.Raw),
 	}
 }
 
+// Apply updates the kept validator with the deactivation.
+func (e SFCDeactivatedValidator) Apply(v *SFCValidator) {
+	v.DeactivatedEpoch = e.DeactivatedEpoch
+	v.DeactivatedTime = e.DeactivatedTime
+}
+
+type SFCChangedValidatorStatus struct {
+	ValidatorID uint64
+	Status      ValidatorStatus
+	EventLog
+}
+
+func ToSFCChangedValidatorStatus(v *contracts.SFCChangedValidatorStatus) SFCChangedValidatorStatus {
+	return SFCChangedValidatorStatus{
+		ValidatorID: v.ValidatorID.Uint64(),
+		Status:      ValidatorStatus(v.Status.Uint64()),
+		EventLog:    ToEventLog(v.Raw),
+	}
+}
+
+// Apply updates the kept validator with the new status.
+func (e SFCChangedValidatorStatus) Apply(v *SFCValidator) {
+	v.Status = e.Status
+	v.IsActive = e.Status.IsActive()
+	v.IsOffline = e.Status.IsOffline()
+}
+
 type SFCDelegateInfo struct {
 	Delegator     string
 	ToValidatorID uint64
 	Amount        float64
-	BlockNumber   uint64
-	TxHash        string
+	EventLog
 }
 
 func ToSFCDelegateInfo(v *contracts.SFCDelegated) SFCDelegateInfo {
@@ -37,8 +184,7 @@ func ToSFCDelegateInfo(v *contracts.SFCDelegated) SFCDelegateInfo {
 		Delegator:     v.Delegator.Hex(),
 		ToValidatorID: v.ToValidatorID.Uint64(),
 		Amount:        WeiToFloat(v.Amount, 18),
-		BlockNumber:   v.Raw.BlockNumber,
-		TxHash:        v.Raw.TxHash.Hex(),
+		EventLog:      ToEventLog(v.Raw),
 	}
 }
 
@@ -47,8 +193,7 @@ type SFCUndelegateInfo struct {
 	ToValidatorID uint64
 	Amount        float64
 	WrID          uint64
-	BlockNumber   uint64
-	TxHash        string
+	EventLog
 }
 
 func ToSFCUndelegateInfo(v *contracts.SFCUndelegated) SFCUndelegateInfo {
@@ -56,9 +201,8 @@ func ToSFCUndelegateInfo(v *contracts.SFCUndelegated) SFCUndelegateInfo {
 		Delegator:     v.Delegator.Hex(),
 		ToValidatorID: v.ToValidatorID.Uint64(),
 		Amount:        WeiToFloat(v.Amount, 18),
-		WrID:          v.ToValidatorID.Uint64(),
-		BlockNumber:   v.Raw.BlockNumber,
-		TxHash:        v.Raw.TxHash.Hex(),
+		WrID:          v.WrID.Uint64(),
+		EventLog:      ToEventLog(v.Raw),
 	}
 }
 
@@ -68,8 +212,7 @@ type SFCRewardInfo struct {
 	LockupExtraReward float64
 	LockupBaseReward  float64
 	UnlockedReward    float64
-	BlockNumber       uint64
-	TxHash            string
+	EventLog
 }
 
 func ToSFCRewardInfo(v *contracts.SFCClaimedRewards) SFCRewardInfo {
@@ -79,17 +222,120 @@ func ToSFCRewardInfo(v *contracts.SFCClaimedRewards) SFCRewardInfo {
 		LockupExtraReward: WeiToFloat(v.LockupExtraReward, 18),
 		LockupBaseReward:  WeiToFloat(v.LockupBaseReward, 18),
 		UnlockedReward:    WeiToFloat(v.UnlockedReward, 18),
-		BlockNumber:       v.Raw.BlockNumber,
-		TxHash:            v.Raw.TxHash.Hex(),
+		EventLog:          ToEventLog(v.Raw),
+	}
+}
+
+type SFCRestakedRewards struct {
+	Delegator         string
+	ToValidatorID     uint64
+	LockupExtraReward float64
+	LockupBaseReward  float64
+	UnlockedReward    float64
+	EventLog
+}
+
+func ToSFCRestakedRewards(v *contracts.SFCRestakedRewards) SFCRestakedRewards {
+	return SFCRestakedRewards{
+		Delegator:         v.Delegator.Hex(),
+		ToValidatorID:     v.ToValidatorID.Uint64(),
+		LockupExtraReward: WeiToFloat(v.LockupExtraReward, 18),
+		LockupBaseReward:  WeiToFloat(v.LockupBaseReward, 18),
+		UnlockedReward:    WeiToFloat(v.UnlockedReward, 18),
+		EventLog:          ToEventLog(v.Raw),
+	}
+}
+
+// TotalReward returns the sum of all the restaked reward components.
+func (r SFCRestakedRewards) TotalReward() float64 {
+	return r.LockupExtraReward + r.LockupBaseReward + r.UnlockedReward
+}
+
+type SFCUpdatedBaseRewardPerSec struct {
+	Value float64
+	EventLog
+}
+
+func ToSFCUpdatedBaseRewardPerSec(v *contracts.SFCUpdatedBaseRewardPerSec) SFCUpdatedBaseRewardPerSec {
+	return SFCUpdatedBaseRewardPerSec{
+		Value:    WeiToFloat(v.Value, 18),
+		EventLog: ToEventLog(v.Raw),
+	}
+}
+
+type SFCUpdatedOfflinePenaltyThreshold struct {
+	BlocksNum uint64
+	Period    uint64
+	EventLog
+}
+
+func ToSFCUpdatedOfflinePenaltyThreshold(v *contracts.SFCUpdatedOfflinePenaltyThreshold) SFCUpdatedOfflinePenaltyThreshold {
+	return SFCUpdatedOfflinePenaltyThreshold{
+		BlocksNum: v.BlocksNum.Uint64(),
+		Period:    v.Period.Uint64(),
+		EventLog:  ToEventLog(v.Raw),
+	}
+}
+
+type SFCUpdatedSlashingRefundRatio struct {
+	ValidatorID uint64
+	// RefundRatio is the part of the slashed stake refunded to the delegators, 1 means 100%.
+	RefundRatio float64
+	EventLog
+}
+
+func ToSFCUpdatedSlashingRefundRatio(v *contracts.SFCUpdatedSlashingRefundRatio) SFCUpdatedSlashingRefundRatio {
+	return SFCUpdatedSlashingRefundRatio{
+		ValidatorID: v.ValidatorID.Uint64(),
+		RefundRatio: WeiToFloat(v.RefundRatio, 18),
+		EventLog:    ToEventLog(v.Raw),
+	}
+}
+
+type SFCOwnershipTransferred struct {
+	PreviousOwner string
+	NewOwner      string
+	EventLog
+}
+
+func ToSFCOwnershipTransferred(v *contracts.SFCOwnershipTransferred) SFCOwnershipTransferred {
+	return SFCOwnershipTransferred{
+		PreviousOwner: v.PreviousOwner.Hex(),
+		NewOwner:      v.NewOwner.Hex(),
+		EventLog:      ToEventLog(v.Raw),
 	}
 }
 
 type TransferLog struct {
-	BlockNumber uint64
-	TxHash      string
-	From        string
-	To          string
-	Amount      float64
+	From   string
+	To     string
+	Amount float64
+	EventLog
+}
+
+// TokenInfo is an ERC20 token watched for big transfers.
+type TokenInfo struct {
+	Address  string
+	Symbol   string
+	Decimals uint8
+}
+
+type TokenTransferLog struct {
+	Token  TokenInfo
+	From   string
+	To     string
+	Amount float64
+	EventLog
+}
+
+func ToTokenTransferLog(v *contracts.ERC20Transfer, token TokenInfo) TokenTransferLog {
+	return TokenTransferLog{
+		Token:    token,
+		From:     v.From.Hex(),
+		To:       v.To.Hex(),
+		Amount:   WeiToFloat(v.Value, int(token.Decimals)),
+		EventLog: ToEventLog(v.Raw),
+	}
 }
 
 type SFCLockedUpStake struct {
@@ -97,8 +343,7 @@ type SFCLockedUpStake struct {
 	ValidatorID uint64
 	Duration    uint64
 	Amount      float64
-	BlockNumber uint64
-	TxHash      string
+	EventLog
 }
 
 func ToSFCLockedUpStake(v *contracts.SFCLockedUpStake) SFCLockedUpStake {
@@ -107,8 +352,7 @@ func ToSFCLockedUpStake(v *contracts.SFCLockedUpStake) SFCLockedUpStake {
 		ValidatorID: v.ValidatorID.Uint64(),
 		Duration:    v.Duration.Uint64(),
 		Amount:      WeiToFloat(v.Amount, 18),
-		BlockNumber: v.Raw.BlockNumber,
-		TxHash:      v.Raw.TxHash.Hex(),
+		EventLog:    ToEventLog(v.Raw),
 	}
 }
 
@@ -117,8 +361,7 @@ type SFCUnlockedStake struct {
 	ValidatorID uint64
 	Amount      float64
 	Penalty     float64
-	BlockNumber uint64
-	TxHash      string
+	EventLog
 }
 
 func ToSFCUnlockedStake(v *contracts.SFCUnlockedStake) SFCUnlockedStake {
@@ -127,7 +370,203 @@ func ToSFCUnlockedStake(v *contracts.SFCUnlockedStake) SFCUnlockedStake {
 		ValidatorID: v.ValidatorID.Uint64(),
 		Amount:      WeiToFloat(v.Amount, 18),
 		Penalty:     WeiToFloat(v.Penalty, 18),
-		BlockNumber: v.Raw.BlockNumber,
-		TxHash:      v.Raw.TxHash.Hex(),
+		EventLog:    ToEventLog(v.Raw),
+	}
+}
+
+type SFCWithdrawn struct {
+	Delegator     string
+	ToValidatorID uint64
+	WrID          uint64
+	Amount        float64
+	EventLog
+}
+
+func ToSFCWithdrawn(v *contracts.SFCWithdrawn) SFCWithdrawn {
+	return SFCWithdrawn{
+		Delegator:     v.Delegator.Hex(),
+		ToValidatorID: v.ToValidatorID.Uint64(),
+		WrID:          v.WrID.Uint64(),
+		Amount:        WeiToFloat(v.Amount, 18),
+		EventLog:      ToEventLog(v.Raw),
+	}
+}
+
+// EpochSnapshot is the state of the network at the end of a sealed epoch, amounts are in FTM.
+type EpochSnapshot struct {
+	Epoch               uint64
+	EndTime             uint64
+	EpochFee            float64
+	TotalStake          float64
+	TotalSupply         float64
+	BaseRewardPerSecond float64
+	Validators          []EpochValidator
+}
+
+type EpochValidator struct {
+	ID               uint64
+	ReceivedStake    float64
+	OriginatedTxsFee float64
+	// OfflineTime in seconds and OfflineBlocks are the downtime of the validator when the epoch was sealed.
+	OfflineTime   uint64
+	OfflineBlocks uint64
+	// AccumulatedUptime is the uptime in seconds accumulated since the validator was created.
+	AccumulatedUptime uint64
+}
+
+// RewardParams are the SFC parameters used to compute the rewards, ratios are between 0 and 1 and durations in seconds.
+// The validator commission is not part of them, the accumulated reward per token is already net of it.
+type RewardParams struct {
+	BaseRewardPerSecond float64
+	UnlockedRewardRatio float64
+	MinLockupDuration   uint64
+	MaxLockupDuration   uint64
+}
+
+// ValidatorAPR is the trailing APR of the delegations to a validator between two epochs,
+// FullAPR is the APR of the full reward received with the maximum lockup duration, 0.05 means 5%.
+type ValidatorAPR struct {
+	ValidatorID uint64
+	FromEpoch   uint64
+	ToEpoch     uint64
+	FullAPR     float64
+}
+
+// LockupInfo is the lockup of a delegation, EndTime is a unix time and Duration is in seconds.
+type LockupInfo struct {
+	Delegator   string
+	ValidatorID uint64
+	LockedStake float64
+	FromEpoch   uint64
+	EndTime     uint64
+	Duration    uint64
+}
+
+// WithdrawalRequest is a pending withdrawal created by an undelegation, Time is a unix time.
+type WithdrawalRequest struct {
+	Delegator   string
+	ValidatorID uint64
+	WrID        uint64
+	Epoch       uint64
+	Time        uint64
+	Amount      float64
+}
+
+// WithdrawalPeriod is the delay before a withdrawal request can be withdrawn, Time is in seconds.
+type WithdrawalPeriod struct {
+	Epochs uint64
+	Time   uint64
+}
+
+// PendingRewards are the unclaimed rewards of a delegation at an epoch, Stashed is the part already stashed by the contract.
+type PendingRewards struct {
+	Epoch   uint64
+	Pending float64
+	Stashed float64
+}
+
+// DecodedArg is an argument of a contract call or event decoded with its ABI.
+type DecodedArg struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// DecodedEvent is an event emitted by a transaction, Contract is the name of the ABI it was decoded with.
+type DecodedEvent struct {
+	Contract string
+	Address  string
+	Name     string
+	Args     []DecodedArg
+}
+
+// DecodedCall is the call data of a transaction decoded with the ABI of the Contract,
+// Contract and Method are empty when the call data is unknown.
+type DecodedCall struct {
+	Contract string
+	Method   string
+	Args     []DecodedArg
+}
+
+// Call returns the decoded call as method(name=value, ...).
+func (c DecodedCall) Call() string {
+	if c.Method == "" {
+		return "unknown method"
+	}
+	return fmt.Sprintf("%s(%s)", c.Method, formatDecodedArgs(c.Args))
+}
+
+// DecodedTx is a transaction with its call data and emitted events decoded with the known ABIs.
+// Error is the revert reason of a failed transaction.
+type DecodedTx struct {
+	From    string
+	To      string
+	Value   float64
+	Success bool
+	GasUsed uint64
+	DecodedCall
+	Events []DecodedEvent
+	Error  string
+	EventLog
+}
+
+func (tx DecodedTx) String() string {
+	status := "success"
+	if !tx.Success {
+		status = "failed"
+		if tx.Error != "" {
+			status += ": " + tx.Error
+		}
+	}
+	var lines = []string{
+		fmt.Sprintf("tx:       %s", tx.TxHash),
+		fmt.Sprintf("block:    %d", tx.BlockNumber),
+		fmt.Sprintf("status:   %s", status),
+		fmt.Sprintf("from:     %s", tx.From),
+		fmt.Sprintf("to:       %s", tx.To),
+		fmt.Sprintf("value:    %f FTM", tx.Value),
+		fmt.Sprintf("gas used: %d", tx.GasUsed),
+		fmt.Sprintf("call:     %s %s", tx.Contract, tx.Call()),
+	}
+	for _, event := range tx.Events {
+		lines = append(lines, fmt.Sprintf("event:    %s %s(%s) at %s", event.Contract, event.Name, formatDecodedArgs(event.Args), event.Address))
 	}
+	return strings.Join(lines, "\n")
+}
+
+func formatDecodedArgs(args []DecodedArg) string {
+	var result = make([]string, 0, len(args))
+	for _, arg := range args {
+		result = append(result, fmt.Sprintf("%s=%s", arg.Name, arg.Value))
+	}
+	return strings.Join(result, ", ")
+}
+
+// NetworkStats is a snapshot of the network wide staking values, Time is the unix time of the snapshot.
+type NetworkStats struct {
+	Time                uint64
+	Epoch               uint64
+	TotalStake          float64
+	TotalActiveStake    float64
+	TotalSupply         float64
+	BaseRewardPerSecond float64
+}
+
+// StakingRatio returns the part of the supply which is staked.
+func (s NetworkStats) StakingRatio() float64 {
+	if s.TotalSupply == 0 {
+		return 0
+	}
+	return s.TotalStake / s.TotalSupply
+}
+
+// PendingTx is a transaction seen in the mempool, SeenTime is the unix time it was first seen.
+type PendingTx struct {
+	Hash     string
+	From     string
+	To       string
+	Value    float64
+	Nonce    uint64
+	SeenTime uint64
+	DecodedCall
 }
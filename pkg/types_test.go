@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"math/big"
+	"testing"
+
+	etherCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/quangkeu95/fantom-bot/lib/contracts"
+	"github.com/stretchr/testify/suite"
+)
+
+type TypesTestSuite struct {
+	suite.Suite
+	sfc *contracts.SFC
+}
+
+func TestTypesTestSuite(t *testing.T) {
+	suite.Run(t, new(TypesTestSuite))
+}
+
+func (ts *TypesTestSuite) SetupSuite() {
+	sfc, err := contracts.NewSFC(etherCommon.HexToAddress("0xfc00face00000000000000000000000000000000"), nil)
+	ts.Assert().NoError(err)
+	ts.sfc = sfc
+}
+
+func uint256Data(values ...uint64) []byte {
+	var data []byte
+	for _, value := range values {
+		data = append(data, etherCommon.LeftPadBytes(new(big.Int).SetUint64(value).Bytes(), 32)...)
+	}
+	return data
+}
+
+func (ts *TypesTestSuite) TestValidatorStatus() {
+	assert := ts.Assert()
+
+	assert.True(ValidatorStatusOK.IsActive())
+	assert.Equal("active", ValidatorStatusOK.String())
+
+	status := ValidatorStatusWithdrawn | ValidatorStatusDoubleSign
+	assert.False(status.IsActive())
+	assert.True(status.IsWithdrawn())
+	assert.False(status.IsOffline())
+	assert.True(status.IsDoubleSign())
+	assert.Equal("withdrawn, doublesign", status.String())
+
+	assert.True(ValidatorStatusOffline.IsOffline())
+	assert.Equal("offline", ValidatorStatusOffline.String())
+	assert.Equal("unknown status 4", ValidatorStatus(1<<2).String())
+
+	assert.Equal(ValidatorStatusOK, ToValidatorStatus(true, false))
+	assert.Equal(ValidatorStatusOffline, ToValidatorStatus(false, true))
+	assert.Equal(ValidatorStatusWithdrawn, ToValidatorStatus(false, false))
+}
+
+func (ts *TypesTestSuite) TestChangedValidatorStatus() {
+	assert := ts.Assert()
+
+	event, err := ts.sfc.ParseChangedValidatorStatus(types.Log{
+		Topics: []etherCommon.Hash{
+			contracts.ChangedValidatorStatusTopics,
+			etherCommon.BigToHash(big.NewInt(12)),
+		},
+		Data:        uint256Data(uint64(ValidatorStatusOffline)),
+		BlockNumber: 100,
+	})
+	assert.NoError(err)
+	item := ToSFCChangedValidatorStatus(event)
+	assert.Equal(uint64(12), item.ValidatorID)
+	assert.Equal(ValidatorStatusOffline, item.Status)
+
+	validator := SFCValidator{ID: 12, IsActive: true}
+	item.Apply(&validator)
+	assert.Equal(ValidatorStatusOffline, validator.Status)
+	assert.False(validator.IsActive)
+	assert.True(validator.IsOffline)
+
+	// the validator is back online
+	SFCChangedValidatorStatus{ValidatorID: 12, Status: ValidatorStatusOK}.Apply(&validator)
+	assert.True(validator.IsActive)
+	assert.False(validator.IsOffline)
+}
+
+func (ts *TypesTestSuite) TestDeactivatedValidator() {
+	assert := ts.Assert()
+
+	event, err := ts.sfc.ParseDeactivatedValidator(types.Log{
+		Topics: []etherCommon.Hash{
+			contracts.DeactivatedValidatorTopics,
+			etherCommon.BigToHash(big.NewInt(12)),
+		},
+		Data: uint256Data(5000, 1650000000),
+	})
+	assert.NoError(err)
+	item := ToSFCDeactivatedValidator(event)
+	assert.Equal(uint64(12), item.ValidatorID)
+
+	validator := SFCValidator{ID: 12, Status: ValidatorStatusWithdrawn}
+	item.Apply(&validator)
+	assert.Equal(uint64(5000), validator.DeactivatedEpoch)
+	assert.Equal(uint64(1650000000), validator.DeactivatedTime)
+	assert.Equal(ValidatorStatusWithdrawn, validator.Status)
+}
+
+func (ts *TypesTestSuite) TestRestakedRewards() {
+	assert := ts.Assert()
+
+	delegator := etherCommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	event, err := ts.sfc.ParseRestakedRewards(types.Log{
+		Topics: []etherCommon.Hash{
+			contracts.RestakedRewardsTopics,
+			delegator.Hash(),
+			etherCommon.BigToHash(big.NewInt(12)),
+		},
+		Data: append(uint256Data(0, 0), etherCommon.LeftPadBytes(new(big.Int).Mul(big.NewInt(1500), big.NewInt(1e18)).Bytes(), 32)...),
+	})
+	assert.NoError(err)
+	item := ToSFCRestakedRewards(event)
+	assert.Equal(delegator.Hex(), item.Delegator)
+	assert.Equal(uint64(12), item.ToValidatorID)
+	assert.Equal(float64(1500), item.UnlockedReward)
+	assert.Equal(float64(1500), item.TotalReward())
+
+	item.LockupExtraReward, item.LockupBaseReward = 20, 30
+	assert.Equal(float64(1550), item.TotalReward())
+}
@@ -1,6 +1,10 @@
 package pkg
 
-import "math/big"
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
 
 func WeiToFloat(amountWei *big.Int, decimal int) float64 {
 	amountWeiFloat := new(big.Float).SetInt(amountWei)
@@ -9,3 +13,20 @@ func WeiToFloat(amountWei *big.Int, decimal int) float64 {
 	rawResult, _ := amount.Float64()
 	return rawResult
 }
+
+// FormatDuration formats a duration with days, hours and minutes, e.g. "7d 2h 5m".
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := d / (24 * time.Hour)
+	hours := (d % (24 * time.Hour)) / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}